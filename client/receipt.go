@@ -0,0 +1,157 @@
+// Package client helps consumers of the bidding API check what the server returns.
+// It depends only on the standard library so it can be vendored into any Go client.
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is returned when a receipt's signature does not match its fields
+	ErrInvalidSignature = errors.New("invalid receipt signature")
+	// ErrUnknownKey is returned when a receipt names a key that is not in the key set
+	ErrUnknownKey = errors.New("unknown receipt key")
+	// ErrMalformedKey is returned for a published key that is not an Ed25519 public key
+	ErrMalformedKey = errors.New("malformed receipt key")
+)
+
+// ReceiptKeysPath is where the server publishes its receipt signing keys
+const ReceiptKeysPath = "/.well-known/bid-receipt-keys"
+
+// receiptVersion is mixed into every signed payload so the encoding can evolve
+const receiptVersion = "bidreceipt/v1"
+
+// Receipt is the server's signed statement that it accepted a bid
+type Receipt struct {
+	BidID     string    `json:"bid_id"`
+	ItemID    string    `json:"item_id"`
+	UserID    string    `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	Sequence  uint64    `json:"sequence"`  // position in the item's bid history, starting at 1
+	KeyID     string    `json:"key_id"`    // kid of the signing key in the published key set
+	Signature string    `json:"signature"` // unpadded base64url Ed25519 signature over SigningPayload
+}
+
+// SigningPayload returns the bytes the server signs: a JSON array of the receipt's fields,
+// with the amount as text and the time as integer nanoseconds so the payload is identical
+// however the receipt was transported.
+func (r Receipt) SigningPayload() []byte {
+	payload, _ := json.Marshal([]string{ // marshalling strings cannot fail
+		receiptVersion,
+		r.BidID,
+		r.ItemID,
+		r.UserID,
+		strconv.FormatFloat(r.Amount, 'g', -1, 64),
+		strconv.FormatInt(r.CreatedAt.UnixNano(), 10),
+		strconv.FormatUint(r.Sequence, 10),
+	})
+	return payload
+}
+
+// VerifyReceipt checks that pub signed the receipt
+func VerifyReceipt(pub ed25519.PublicKey, r Receipt) error {
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: public key is %d bytes", ErrMalformedKey, len(pub))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(r.Signature)
+	if err != nil || !ed25519.Verify(pub, r.SigningPayload(), sig) {
+		return fmt.Errorf("%w: bid %s", ErrInvalidSignature, r.BidID)
+	}
+	return nil
+}
+
+// KeyID derives the identifier the server publishes for a signing key
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// PublicKey is a receipt signing key in JWK form (RFC 8037)
+type PublicKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"` // "OKP"
+	Curve     string `json:"crv"` // "Ed25519"
+	Algorithm string `json:"alg"` // "EdDSA"
+	X         string `json:"x"`   // unpadded base64url public key
+}
+
+// NewPublicKey encodes an Ed25519 public key for publication
+func NewPublicKey(pub ed25519.PublicKey) PublicKey {
+	return PublicKey{
+		KeyID:     KeyID(pub),
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		Algorithm: "EdDSA",
+		X:         base64.RawURLEncoding.EncodeToString(pub),
+	}
+}
+
+// Ed25519 decodes the key, checking its type
+func (k PublicKey) Ed25519() (ed25519.PublicKey, error) {
+	if k.KeyType != "OKP" || k.Curve != "Ed25519" {
+		return nil, fmt.Errorf("%w: %s is %s/%s", ErrMalformedKey, k.KeyID, k.KeyType, k.Curve)
+	}
+	pub, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: %s has an invalid x", ErrMalformedKey, k.KeyID)
+	}
+	return pub, nil
+}
+
+// KeySet is the document served at ReceiptKeysPath
+type KeySet struct {
+	Keys []PublicKey `json:"keys"`
+}
+
+// Verify checks a receipt against the key it names
+func (s KeySet) Verify(r Receipt) error {
+	for _, k := range s.Keys {
+		if k.KeyID != r.KeyID {
+			continue
+		}
+		pub, err := k.Ed25519()
+		if err != nil {
+			return err
+		}
+		return VerifyReceipt(pub, r)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownKey, r.KeyID)
+}
+
+// FetchKeySet downloads the receipt keys published by the server at baseURL.
+// A nil httpClient uses http.DefaultClient.
+func FetchKeySet(ctx context.Context, httpClient *http.Client, baseURL string) (KeySet, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+ReceiptKeysPath, nil)
+	if err != nil {
+		return KeySet{}, fmt.Errorf("fetch receipt keys: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return KeySet{}, fmt.Errorf("fetch receipt keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return KeySet{}, fmt.Errorf("fetch receipt keys: unexpected status %s", resp.Status)
+	}
+
+	var set KeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return KeySet{}, fmt.Errorf("fetch receipt keys: decode: %w", err)
+	}
+	return set, nil
+}
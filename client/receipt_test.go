@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sign produces a receipt the way the server does
+func sign(t *testing.T, key ed25519.PrivateKey, r Receipt) Receipt {
+	t.Helper()
+	r.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	r.Signature = base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, r.SigningPayload()))
+	return r
+}
+
+// Test VerifyReceipt against tampered receipts and wrong keys
+func TestVerifyReceipt(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signed := sign(t, key, Receipt{
+		BidID:     "bid1",
+		ItemID:    "item1",
+		UserID:    "user1",
+		Amount:    150.25,
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 987654321, time.UTC),
+		Sequence:  7,
+	})
+
+	tests := []struct {
+		name    string
+		pub     ed25519.PublicKey
+		tamper  func(r *Receipt)
+		wantErr error
+	}{
+		{name: "valid", pub: pub, tamper: func(*Receipt) {}},
+		{name: "amount_changed", pub: pub, tamper: func(r *Receipt) { r.Amount = 1502.5 }, wantErr: ErrInvalidSignature},
+		{name: "user_changed", pub: pub, tamper: func(r *Receipt) { r.UserID = "user2" }, wantErr: ErrInvalidSignature},
+		{name: "sequence_changed", pub: pub, tamper: func(r *Receipt) { r.Sequence = 1 }, wantErr: ErrInvalidSignature},
+		{name: "time_changed", pub: pub, tamper: func(r *Receipt) { r.CreatedAt = r.CreatedAt.Add(time.Nanosecond) }, wantErr: ErrInvalidSignature},
+		{name: "signature_not_base64", pub: pub, tamper: func(r *Receipt) { r.Signature = "!!" }, wantErr: ErrInvalidSignature},
+		{name: "other_key", pub: otherPub, tamper: func(*Receipt) {}, wantErr: ErrInvalidSignature},
+		{name: "short_key", pub: pub[:16], tamper: func(*Receipt) {}, wantErr: ErrMalformedKey},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			r := signed
+			tc.tamper(&r)
+			err := VerifyReceipt(tc.pub, r)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// Test that a receipt still verifies after a JSON round trip in another time zone
+func TestReceipt_JSONRoundTrip(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signed := sign(t, key, Receipt{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 0.1 + 0.2, CreatedAt: time.Now().UTC(), Sequence: 1})
+
+	data, err := json.Marshal(signed)
+	require.NoError(t, err)
+	var decoded Receipt
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	decoded.CreatedAt = decoded.CreatedAt.In(time.FixedZone("UTC+2", 2*60*60))
+	require.NoError(t, VerifyReceipt(pub, decoded))
+}
+
+// Test KeySet.Verify key selection and FetchKeySet
+func TestKeySet(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	set := KeySet{Keys: []PublicKey{NewPublicKey(pub)}}
+	signed := sign(t, key, Receipt{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 100, CreatedAt: time.Now().UTC(), Sequence: 1})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != ReceiptKeysPath {
+			http.NotFound(w, r)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(set))
+	}))
+	defer srv.Close()
+
+	fetched, err := FetchKeySet(context.Background(), srv.Client(), srv.URL+"/")
+	require.NoError(t, err)
+	require.Equal(t, set, fetched)
+	require.NoError(t, fetched.Verify(signed))
+
+	unknown := signed
+	unknown.KeyID = "0000000000000000"
+	require.ErrorIs(t, fetched.Verify(unknown), ErrUnknownKey)
+
+	badKey := KeySet{Keys: []PublicKey{{KeyID: signed.KeyID, KeyType: "RSA"}}}
+	require.ErrorIs(t, badKey.Verify(signed), ErrMalformedKey)
+
+	_, err = FetchKeySet(context.Background(), srv.Client(), srv.URL+"/missing")
+	require.Error(t, err)
+}
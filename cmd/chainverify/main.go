@@ -0,0 +1,111 @@
+// Command chainverify checks an exported bid history offline.
+//
+// It reads the bids of one item in recording order, either as a JSON array or as the
+// response of GET /items/:item_id/bids, from a file or stdin:
+//
+//	curl -s localhost:8080/items/item1/bids | chainverify -head <chain_head> -
+//
+// With -head, the chain must end at that hash (the chain_head from
+// GET /items/:item_id/winning), which also detects bids removed from the end.
+// It exits 0 when the chain is intact, 1 when it is broken and 2 on usage errors.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"bidding-tracker/internal/bidchain"
+	model "bidding-tracker/internal/models"
+)
+
+const (
+	exitOK     = 0
+	exitBroken = 1
+	exitUsage  = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run verifies the bid list named by args and reports to stdout; it returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("chainverify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	head := fs.String("head", "", "expected chain head, e.g. chain_head from GET /items/:item_id/winning")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: chainverify [-head hash] <bids.json | ->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	bids, err := readBids(fs.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "chainverify: %v\n", err)
+		return exitUsage
+	}
+
+	res, err := verify(bids, *head)
+	if err != nil {
+		fmt.Fprintf(stdout, "BROKEN: %v\n", err)
+		return exitBroken
+	}
+	fmt.Fprintf(stdout, "OK: %d bids, %d unchained, head %s\n", res.Bids, res.Unchained, res.Head)
+	return exitOK
+}
+
+// verify checks the chain, that every bid is for the same item and, when set, the expected head
+func verify(bids []model.Bid, head string) (bidchain.Result, error) {
+	for i, bid := range bids {
+		if bid.ItemID != bids[0].ItemID {
+			return bidchain.Result{}, fmt.Errorf("bid %d (%s) is for item %q, not %q", i, bid.BidID, bid.ItemID, bids[0].ItemID)
+		}
+	}
+	res, err := bidchain.Verify(bids)
+	if err != nil {
+		return bidchain.Result{}, err
+	}
+	if head != "" && res.Head != head {
+		return bidchain.Result{}, fmt.Errorf("chain ends at %q, expected head %q", res.Head, head)
+	}
+	return res, nil
+}
+
+// readBids loads a bid list from path ("-" for stdin), unwrapping an API response envelope
+func readBids(path string, stdin io.Reader) ([]model.Bid, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var bids []model.Bid
+	if err := json.Unmarshal(data, &bids); err == nil {
+		return bids, nil
+	}
+	var envelope struct {
+		Data *[]model.Bid `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Data == nil {
+		return nil, errors.New("input must be a JSON array of bids or a GET /items/:item_id/bids response")
+	}
+	return *envelope.Data, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/bidchain"
+	model "bidding-tracker/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// chainOf links bids for item1 with increasing amounts
+func chainOf(n int) []model.Bid {
+	var bids []model.Bid
+	head := ""
+	for i := 0; i < n; i++ {
+		bid := bidchain.Link(head, model.Bid{
+			BidID:     "bid" + string(rune('a'+i)),
+			ItemID:    "item1",
+			UserID:    "user1",
+			Amount:    float64(100 + i),
+			CreatedAt: time.Date(2024, 1, 1, 12, i, 0, 0, time.UTC),
+		})
+		head = bid.Hash
+		bids = append(bids, bid)
+	}
+	return bids
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	bids := chainOf(3)
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(data)
+	}
+	tampered := chainOf(3)
+	tampered[1].Amount = 500
+	otherItem := chainOf(2)
+	otherItem[1] = bidchain.Link(otherItem[0].Hash, model.Bid{BidID: "x", ItemID: "item2", Amount: 1})
+
+	tests := []struct {
+		name     string
+		args     []string
+		input    string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "array", args: []string{"-"}, input: encode(bids), wantCode: exitOK, wantOut: "OK: 3 bids"},
+		{name: "api_envelope", args: []string{"-"}, input: encode(map[string]any{"data": bids}), wantCode: exitOK, wantOut: "OK: 3 bids"},
+		{name: "expected_head", args: []string{"-head", bids[2].Hash, "-"}, input: encode(bids), wantCode: exitOK},
+		{name: "truncated_tail", args: []string{"-head", bids[2].Hash, "-"}, input: encode(bids[:2]), wantCode: exitBroken, wantOut: "expected head"},
+		{name: "altered_amount", args: []string{"-"}, input: encode(tampered), wantCode: exitBroken, wantOut: "bid 1"},
+		{name: "deleted_bid", args: []string{"-"}, input: encode([]model.Bid{bids[0], bids[2]}), wantCode: exitBroken, wantOut: "prev_hash"},
+		{name: "mixed_items", args: []string{"-"}, input: encode(otherItem), wantCode: exitBroken, wantOut: "item2"},
+		{name: "not_bids", args: []string{"-"}, input: `{"message":"nope"}`, wantCode: exitUsage},
+		{name: "missing_argument", args: nil, wantCode: exitUsage},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.input), &stdout, &stderr)
+			require.Equal(t, tc.wantCode, code, stdout.String()+stderr.String())
+			require.Contains(t, stdout.String(), tc.wantOut)
+		})
+	}
+}
@@ -18,7 +18,7 @@ func SetupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	repo := repository.NewMemoryRepo()
 	service := bidding.NewBiddingService(repo)
-	router := server.SetupRouter(service)
+	router := server.SetupRouter(server.Dependencies{Bidding: service})
 	return router
 }
 
@@ -76,6 +76,6 @@ func SetupTestRouterWithItems(items ...model.Item) *gin.Engine {
 	}
 
 	service := bidding.NewBiddingService(repo)
-	router := server.SetupRouter(service)
+	router := server.SetupRouter(server.Dependencies{Bidding: service})
 	return router
 }
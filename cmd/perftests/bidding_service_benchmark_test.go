@@ -1,8 +1,10 @@
 package perftests
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -16,6 +18,7 @@ import (
 func Benchmark_PlaceBid_Isolated(b *testing.B) {
 	repo := repository.NewMemoryRepo()
 	svc := bidding.NewBiddingService(repo)
+	ctx := context.Background()
 
 	for i := 0; i < b.N; i++ {
 		item := model.Item{
@@ -34,7 +37,7 @@ func Benchmark_PlaceBid_Isolated(b *testing.B) {
 		userID := fmt.Sprintf("user_%d", i)
 		itemID := fmt.Sprintf("item_%d", i)
 		bidAmount := float64(50 + rand.Intn(100))
-		if _, err := svc.PlaceBid(itemID, userID, bidAmount); err != nil {
+		if _, err := svc.PlaceBid(ctx, itemID, userID, bidAmount); err != nil {
 			b.Fatalf("failed to place bid: %v", err)
 		}
 	}
@@ -45,6 +48,7 @@ func Benchmark_PlaceBid_Isolated(b *testing.B) {
 func Benchmark_PlaceBid_ConcurrentSharedItem(b *testing.B) {
 	repo := repository.NewMemoryRepo()
 	svc := bidding.NewBiddingService(repo)
+	ctx := context.Background()
 
 	item := model.Item{
 		ItemID:        "shared_item_1",
@@ -65,7 +69,7 @@ func Benchmark_PlaceBid_ConcurrentSharedItem(b *testing.B) {
 			userID := fmt.Sprintf("user_parallel_%d", rnd.Int())
 
 			nextBid := atomic.AddInt64(&lastBid, int64(rnd.Intn(5)+1))
-			_, _ = svc.PlaceBid(item.ItemID, userID, float64(nextBid))
+			_, _ = svc.PlaceBid(ctx, item.ItemID, userID, float64(nextBid))
 		}
 	})
 }
@@ -74,6 +78,7 @@ func Benchmark_PlaceBid_ConcurrentSharedItem(b *testing.B) {
 func Benchmark_GetWinningBid_SingleThreaded(b *testing.B) {
 	repo := repository.NewMemoryRepo()
 	svc := bidding.NewBiddingService(repo)
+	ctx := context.Background()
 
 	for i := 0; i < b.N; i++ {
 		item := model.Item{
@@ -87,7 +92,7 @@ func Benchmark_GetWinningBid_SingleThreaded(b *testing.B) {
 		for j := 0; j < 10; j++ {
 			userID := fmt.Sprintf("user_%d_%d", i, j)
 			bidAmount := float64(50 + j*10)
-			_, _ = svc.PlaceBid(item.ItemID, userID, bidAmount)
+			_, _ = svc.PlaceBid(ctx, item.ItemID, userID, bidAmount)
 		}
 	}
 
@@ -96,7 +101,7 @@ func Benchmark_GetWinningBid_SingleThreaded(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		itemID := fmt.Sprintf("item_%d", i)
-		if _, err := svc.GetWinningBid(itemID); err != nil {
+		if _, err := svc.GetWinningBid(ctx, itemID); err != nil {
 			b.Fatalf("failed to get winning bid: %v", err)
 		}
 	}
@@ -106,6 +111,7 @@ func Benchmark_GetWinningBid_SingleThreaded(b *testing.B) {
 func Benchmark_GetWinningBid_ConcurrentSharedItem(b *testing.B) {
 	repo := repository.NewMemoryRepo()
 	svc := bidding.NewBiddingService(repo)
+	ctx := context.Background()
 
 	item := model.Item{
 		ItemID:        "shared_item_1",
@@ -118,7 +124,7 @@ func Benchmark_GetWinningBid_ConcurrentSharedItem(b *testing.B) {
 	for j := 0; j < 100; j++ {
 		userID := fmt.Sprintf("user_%d", j)
 		bidAmount := float64(50 + j)
-		_, _ = svc.PlaceBid(item.ItemID, userID, bidAmount)
+		_, _ = svc.PlaceBid(ctx, item.ItemID, userID, bidAmount)
 	}
 
 	b.ReportAllocs()
@@ -128,7 +134,7 @@ func Benchmark_GetWinningBid_ConcurrentSharedItem(b *testing.B) {
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			if _, err := svc.GetWinningBid(item.ItemID); err != nil {
+			if _, err := svc.GetWinningBid(ctx, item.ItemID); err != nil {
 				b.Fatalf("failed to get winning bid: %v", err)
 			}
 			atomic.AddInt64(&counter, 1)
@@ -140,6 +146,7 @@ func Benchmark_GetWinningBid_ConcurrentSharedItem(b *testing.B) {
 func Benchmark_MixedWorkload_SharedItem(b *testing.B) {
 	repo := repository.NewMemoryRepo()
 	svc := bidding.NewBiddingService(repo)
+	ctx := context.Background()
 
 	item := model.Item{
 		ItemID:        "shared_item_1",
@@ -152,7 +159,7 @@ func Benchmark_MixedWorkload_SharedItem(b *testing.B) {
 	for j := 0; j < 50; j++ {
 		userID := fmt.Sprintf("user_seed_%d", j)
 		bidAmount := float64(50 + j*2)
-		_, _ = svc.PlaceBid(item.ItemID, userID, bidAmount)
+		_, _ = svc.PlaceBid(ctx, item.ItemID, userID, bidAmount)
 	}
 
 	b.ReportAllocs()
@@ -171,10 +178,10 @@ func Benchmark_MixedWorkload_SharedItem(b *testing.B) {
 				// Writer: Place a new bid
 				userID := fmt.Sprintf("user_writer_%d", rnd.Int())
 				nextBid := atomic.AddInt64(&lastBid, int64(rnd.Intn(5)+1))
-				_, _ = svc.PlaceBid(item.ItemID, userID, float64(nextBid))
+				_, _ = svc.PlaceBid(ctx, item.ItemID, userID, float64(nextBid))
 			default:
 				// Reader: Get winning bid
-				if _, _ = svc.GetWinningBid(item.ItemID); false {
+				if _, _ = svc.GetWinningBid(ctx, item.ItemID); false {
 					b.Fatalf("read error") // never happens
 				}
 			}
@@ -182,3 +189,231 @@ func Benchmark_MixedWorkload_SharedItem(b *testing.B) {
 		}
 	})
 }
+
+// globalLockRepo serializes every call through one mutex, reproducing the
+// single-lock repository design as a baseline for the multi-item benchmark
+type globalLockRepo struct {
+	mu   sync.RWMutex
+	repo repository.AuctionDB
+}
+
+func (g *globalLockRepo) RecordBidForItem(ctx context.Context, bid model.Bid) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.repo.RecordBidForItem(ctx, bid)
+}
+
+func (g *globalLockRepo) GetBidsByItem(ctx context.Context, itemID string) ([]model.Bid, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.repo.GetBidsByItem(ctx, itemID)
+}
+
+func (g *globalLockRepo) GetWinningBid(ctx context.Context, itemID string) (model.Bid, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.repo.GetWinningBid(ctx, itemID)
+}
+
+func (g *globalLockRepo) GetItem(ctx context.Context, itemID string) (model.Item, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.repo.GetItem(ctx, itemID)
+}
+
+func (g *globalLockRepo) CloseItem(ctx context.Context, itemID string, closedAt time.Time) (model.Item, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.repo.CloseItem(ctx, itemID, closedAt)
+}
+
+func (g *globalLockRepo) GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.repo.GetItemsByUser(ctx, userID)
+}
+
+// Benchmark 6: PlaceBid - Concurrent writers spread over many items.
+// Compares per-item locking against a single global lock: with per-item locks,
+// writes to different items proceed in parallel.
+func Benchmark_PlaceBid_ConcurrentMultiItem(b *testing.B) {
+	const numItems = 64
+
+	locking := []struct {
+		name string
+		wrap func(repository.AuctionDB) repository.AuctionDB
+	}{
+		{"PerItemLock", func(r repository.AuctionDB) repository.AuctionDB { return r }},
+		{"GlobalLock", func(r repository.AuctionDB) repository.AuctionDB { return &globalLockRepo{repo: r} }},
+	}
+
+	for _, l := range locking {
+		b.Run(l.name, func(b *testing.B) {
+			repo := repository.NewMemoryRepo()
+			svc := bidding.NewBiddingService(l.wrap(repo))
+			ctx := context.Background()
+
+			for i := 0; i < numItems; i++ {
+				repo.AddItem(model.Item{
+					ItemID:        fmt.Sprintf("item_%d", i),
+					Title:         fmt.Sprintf("Multi-Item %d", i),
+					Description:   "Used to measure parallel writes to different items",
+					StartingPrice: 50,
+				})
+			}
+
+			// one monotonically increasing bid counter per item keeps every bid valid
+			lastBids := make([]int64, numItems)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+				for pb.Next() {
+					idx := rnd.Intn(numItems)
+					userID := fmt.Sprintf("user_multi_%d", rnd.Int())
+					nextBid := atomic.AddInt64(&lastBids[idx], int64(rnd.Intn(5)+1))
+					_, _ = svc.PlaceBid(ctx, fmt.Sprintf("item_%d", idx), userID, float64(50+nextBid))
+				}
+			})
+		})
+	}
+}
+
+// scanWinnerRepo answers GetWinningBid by scanning every bid for the item,
+// reproducing the pre-index lookup as a baseline for the large-item benchmarks
+type scanWinnerRepo struct {
+	repository.AuctionDB
+}
+
+func (s scanWinnerRepo) GetWinningBid(ctx context.Context, itemID string) (model.Bid, error) {
+	bids, err := s.GetBidsByItem(ctx, itemID)
+	if err != nil {
+		return model.Bid{}, err
+	}
+	winning := bids[0]
+	for _, b := range bids[1:] {
+		if b.Amount > winning.Amount || (b.Amount == winning.Amount && b.CreatedAt.Before(winning.CreatedAt)) {
+			winning = b
+		}
+	}
+	return winning, nil
+}
+
+// largeItemBids is the number of bids seeded on the item in the large-item benchmarks
+const largeItemBids = 100_000
+
+// setupLargeItem creates a repository holding one item with largeItemBids bids
+func setupLargeItem(b *testing.B) *repository.MemoryRepo {
+	b.Helper()
+	repo := repository.NewMemoryRepo()
+	ctx := context.Background()
+
+	repo.AddItem(model.Item{
+		ItemID:        "large_item",
+		Title:         "Large Item",
+		Description:   "Item with a long bid history",
+		StartingPrice: 50,
+	})
+	start := time.Now()
+	for i := 0; i < largeItemBids; i++ {
+		bid := model.Bid{
+			BidID:     fmt.Sprintf("bid_%d", i),
+			ItemID:    "large_item",
+			UserID:    fmt.Sprintf("user_%d", i%1000),
+			Amount:    float64(51 + i),
+			CreatedAt: start.Add(time.Duration(i)),
+		}
+		if err := repo.RecordBidForItem(ctx, bid); err != nil {
+			b.Fatalf("failed to seed bid: %v", err)
+		}
+	}
+	return repo
+}
+
+// Benchmark 7: GetWinningBid - Item with 100k bids.
+// Indexed reads the maintained leader; FullScan walks every bid.
+func Benchmark_GetWinningBid_LargeItem(b *testing.B) {
+	lookups := []struct {
+		name string
+		wrap func(repository.AuctionDB) repository.AuctionDB
+	}{
+		{"Indexed", func(r repository.AuctionDB) repository.AuctionDB { return r }},
+		{"FullScan", func(r repository.AuctionDB) repository.AuctionDB { return scanWinnerRepo{r} }},
+	}
+
+	repo := setupLargeItem(b)
+	for _, l := range lookups {
+		b.Run(l.name, func(b *testing.B) {
+			svc := bidding.NewBiddingService(l.wrap(repo))
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := svc.GetWinningBid(ctx, "large_item"); err != nil {
+					b.Fatalf("failed to get winning bid: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Benchmark 8: PlaceBid - Item with 100k bids.
+// PlaceBid validates against the current winner, so the lookup cost is paid on every write.
+func Benchmark_PlaceBid_LargeItem(b *testing.B) {
+	lookups := []struct {
+		name string
+		wrap func(repository.AuctionDB) repository.AuctionDB
+	}{
+		{"Indexed", func(r repository.AuctionDB) repository.AuctionDB { return r }},
+		{"FullScan", func(r repository.AuctionDB) repository.AuctionDB { return scanWinnerRepo{r} }},
+	}
+
+	for _, l := range lookups {
+		b.Run(l.name, func(b *testing.B) {
+			repo := setupLargeItem(b)
+			svc := bidding.NewBiddingService(l.wrap(repo))
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				amount := float64(51 + largeItemBids + i)
+				if _, err := svc.PlaceBid(ctx, "large_item", fmt.Sprintf("user_%d", i%1000), amount); err != nil {
+					b.Fatalf("failed to place bid: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Benchmark 9: PlaceBid - One user bidding across 100k items.
+// The user index dedupes by set membership, so the cost stays flat as the user's item list grows.
+func Benchmark_PlaceBid_UserWithManyItems(b *testing.B) {
+	repo := repository.NewMemoryRepo()
+	svc := bidding.NewBiddingService(repo)
+	ctx := context.Background()
+
+	const numItems = 100_000
+	for i := 0; i < numItems; i++ {
+		itemID := fmt.Sprintf("item_%d", i)
+		repo.AddItem(model.Item{ItemID: itemID, Title: itemID, Description: "User index benchmark item", StartingPrice: 50})
+		if _, err := svc.PlaceBid(ctx, itemID, "heavy_user", 51); err != nil {
+			b.Fatalf("failed to seed bid: %v", err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		amount := float64(52 + i/numItems)
+		if _, err := svc.PlaceBid(ctx, fmt.Sprintf("item_%d", i%numItems), "heavy_user", amount); err != nil {
+			b.Fatalf("failed to place bid: %v", err)
+		}
+	}
+}
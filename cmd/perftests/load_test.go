@@ -1,6 +1,7 @@
 package perftests
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"runtime"
@@ -98,6 +99,7 @@ func runParallelScenario(b *testing.B, s LoadScenario) {
 	b.ReportAllocs()
 
 	_, svc := setupRepo(s.NumItems)
+	ctx := context.Background()
 
 	var totalOps, successfulBids, failedBids, totalReads int64
 	itemSuccess := make([]int64, s.NumItems)
@@ -115,7 +117,7 @@ func runParallelScenario(b *testing.B, s LoadScenario) {
 
 			opStart := time.Now()
 			if opType < s.ReadRatio {
-				_, err := svc.GetWinningBid(itemID)
+				_, err := svc.GetWinningBid(ctx, itemID)
 				if err != nil {
 					b.Logf("ignored read error: %v", err)
 				}
@@ -123,7 +125,7 @@ func runParallelScenario(b *testing.B, s LoadScenario) {
 			} else {
 				bidAmount := float64(100 + rnd.Intn(s.MaxBidIncrement))
 				userID := fmt.Sprintf("user_%d", rnd.Int())
-				if _, err := svc.PlaceBid(itemID, userID, bidAmount); err != nil {
+				if _, err := svc.PlaceBid(ctx, itemID, userID, bidAmount); err != nil {
 					b.Logf("ignored bid error: %v", err)
 					atomic.AddInt64(&failedBids, 1)
 				} else {
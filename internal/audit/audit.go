@@ -0,0 +1,266 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionBidPlaced          = "bid.placed"
+	ActionAuctionClosed      = "auction.closed"
+	ActionPreferencesUpdated = "preferences.updated"
+	ActionWebhookCreated     = "webhook.created"
+	ActionWebhookDeleted     = "webhook.deleted"
+	ActionAPIKeyIssued       = "api_key.issued"
+	ActionAPIKeyRevoked      = "api_key.revoked"
+)
+
+// Entry is one recorded mutation. Entries are never updated or deleted.
+type Entry struct {
+	Seq        uint64          `json:"seq"` // position in the log, starting at 1
+	At         time.Time       `json:"at"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`                  // authenticated user, or "anonymous"
+	ActorKeyID string          `json:"actor_key_id,omitempty"` // API key used, if any
+	SourceIP   string          `json:"source_ip"`
+	RequestID  string          `json:"request_id"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	ItemID     string          `json:"item_id,omitempty"`
+	UserID     string          `json:"user_id,omitempty"` // user the mutation is about
+	Resource   string          `json:"resource,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// Filter selects entries in Query. Zero fields match everything.
+type Filter struct {
+	ItemID string
+	UserID string // matches the entry's user or its actor
+	Action string
+	From   time.Time // inclusive
+	To     time.Time // exclusive
+	After  uint64    // only entries with a greater Seq
+	Limit  int       // 0 means no limit
+}
+
+// Log is an append-only audit log, kept in memory and optionally mirrored to a
+// JSON lines file. Appends are durable when they return: concurrent appends share
+// one fsync instead of each holding the log for their own.
+type Log struct {
+	mu      sync.RWMutex
+	entries []Entry // the retained entries, in sequence order
+	last    uint64  // sequence number of the last entry appended
+	retain  int     // entries kept in memory; 0 keeps all
+	f       *os.File
+	failed  error // set when a write or fsync fails; the file may be torn or lost entries, so appends stop
+
+	syncMu sync.Mutex
+	synced uint64 // sequence number of the last entry known to be on disk
+}
+
+// Option configures a Log
+type Option func(*Log)
+
+// WithRetention keeps only the n most recent entries in memory for Query; 0 keeps
+// all. The file, if any, keeps every entry.
+func WithRetention(n int) Option {
+	return func(l *Log) {
+		l.retain = n
+	}
+}
+
+// NewMemoryLog creates a log that is not persisted
+func NewMemoryLog(opts ...Option) *Log {
+	l := &Log{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// OpenLog opens (or creates) the audit file at path and loads its entries.
+// A torn final line from a crash during an append is truncated away.
+func OpenLog(path string, opts ...Option) (*Log, error) {
+	l := NewMemoryLog(opts...)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: open %s: %w", path, err)
+	}
+
+	valid, err := l.load(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit: truncate %s: %w", path, err)
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit: seek %s: %w", path, err)
+	}
+	l.f = f
+	l.synced = l.last
+	return l, nil
+}
+
+// Append assigns the entry its sequence number and stores it
+func (l *Log) Append(e Entry) (Entry, error) {
+	l.mu.Lock()
+	if l.failed != nil {
+		l.mu.Unlock()
+		return Entry{}, l.failed
+	}
+	e.Seq = l.last + 1
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+
+	if l.f != nil {
+		line, err := json.Marshal(e)
+		if err != nil {
+			l.mu.Unlock()
+			return Entry{}, fmt.Errorf("audit: encode entry: %w", err)
+		}
+		if _, err := l.f.Write(append(line, '\n')); err != nil {
+			l.failed = fmt.Errorf("audit: write entry: %w", err) // a partial line may be left behind
+			l.mu.Unlock()
+			return Entry{}, l.failed
+		}
+	}
+	l.last = e.Seq
+	l.keep(e)
+	l.mu.Unlock()
+
+	if l.f != nil {
+		if err := l.sync(e.Seq); err != nil {
+			return Entry{}, err
+		}
+	}
+	return e, nil
+}
+
+// Err returns the write or fsync failure that stopped the log, or nil while it accepts appends
+func (l *Log) Err() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.failed
+}
+
+// sync makes the file durable up to entry seq. Entries written while another append
+// was syncing are covered by a single fsync.
+func (l *Log) sync(seq uint64) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	if l.synced >= seq {
+		return nil // a concurrent append's fsync covered this entry
+	}
+
+	l.mu.RLock()
+	written, failed := l.last, l.failed
+	l.mu.RUnlock()
+	if failed != nil {
+		return failed
+	}
+	if err := l.f.Sync(); err != nil {
+		err = fmt.Errorf("audit: fsync entry: %w", err)
+		l.mu.Lock()
+		l.failed = err
+		l.mu.Unlock()
+		return err
+	}
+	l.synced = written
+	return nil
+}
+
+// keep adds an entry to the in-memory history, dropping the oldest entries once the
+// history is twice the retention, so trimming is amortized over appends.
+// The caller must hold l.mu.
+func (l *Log) keep(e Entry) {
+	l.entries = append(l.entries, e)
+	if l.retain > 0 && len(l.entries) >= 2*l.retain {
+		l.entries = append([]Entry(nil), l.entries[len(l.entries)-l.retain:]...)
+	}
+}
+
+// Query returns the retained entries matching f in sequence order
+func (l *Log) Query(f Filter) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	retained := l.entries
+	if l.retain > 0 && len(retained) > l.retain {
+		retained = retained[len(retained)-l.retain:]
+	}
+	start := 0
+	if len(retained) > 0 && f.After >= retained[0].Seq {
+		start = int(min(f.After-retained[0].Seq+1, uint64(len(retained))))
+	}
+
+	matched := []Entry{}
+	for _, e := range retained[start:] {
+		if f.matches(e) {
+			matched = append(matched, e)
+			if f.Limit > 0 && len(matched) == f.Limit {
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Close closes the audit file, if any
+func (l *Log) Close() error {
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+func (f Filter) matches(e Entry) bool {
+	switch {
+	case f.ItemID != "" && e.ItemID != f.ItemID:
+		return false
+	case f.UserID != "" && e.UserID != f.UserID && e.Actor != f.UserID:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case !f.From.IsZero() && e.At.Before(f.From):
+		return false
+	case !f.To.IsZero() && !e.At.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// load reads the entries of an audit file into the log and returns the length of
+// its complete lines
+func (l *Log) load(r io.Reader) (valid int64, err error) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return valid, nil // anything left is a torn final line
+		}
+		if err != nil {
+			return 0, fmt.Errorf("audit: read log: %w", err)
+		}
+
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return 0, fmt.Errorf("audit: corrupt log at byte %d: %w", valid, err)
+		}
+		l.last = e.Seq
+		l.keep(e)
+		valid += int64(len(line))
+	}
+}
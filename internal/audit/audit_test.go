@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/internal/biddingerrors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_Query(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	log := NewMemoryLog()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []Entry{
+		{Action: ActionBidPlaced, Actor: "user1", ItemID: "item1", UserID: "user1"},
+		{Action: ActionBidPlaced, Actor: "user2", ItemID: "item1", UserID: "user2"},
+		{Action: ActionBidPlaced, Actor: "user1", ItemID: "item2", UserID: "user1"},
+		{Action: ActionPreferencesUpdated, Actor: "admin", UserID: "user2"},
+	} {
+		e.At = base.Add(time.Duration(i) * time.Minute)
+		got, err := log.Append(e)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i+1), got.Seq)
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		wantSeqs []uint64
+	}{
+		{name: "all", filter: Filter{}, wantSeqs: []uint64{1, 2, 3, 4}},
+		{name: "by_item", filter: Filter{ItemID: "item1"}, wantSeqs: []uint64{1, 2}},
+		{name: "by_user_as_subject_or_actor", filter: Filter{UserID: "user2"}, wantSeqs: []uint64{2, 4}},
+		{name: "by_actor", filter: Filter{UserID: "admin"}, wantSeqs: []uint64{4}},
+		{name: "by_action", filter: Filter{Action: ActionPreferencesUpdated}, wantSeqs: []uint64{4}},
+		{name: "time_range_half_open", filter: Filter{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}, wantSeqs: []uint64{2, 3}},
+		{name: "after_and_limit", filter: Filter{After: 1, Limit: 2}, wantSeqs: []uint64{2, 3}},
+		{name: "after_end", filter: Filter{After: 10}, wantSeqs: []uint64{}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			seqs := []uint64{}
+			for _, e := range log.Query(tc.filter) {
+				seqs = append(seqs, e.Seq)
+			}
+			require.Equal(t, tc.wantSeqs, seqs)
+		})
+	}
+}
+
+// Test that entries survive a reopen and a torn final line is dropped
+func TestOpenLog_Recovery(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := OpenLog(path)
+	require.NoError(t, err)
+	_, err = log.Append(Entry{Action: ActionBidPlaced, ItemID: "item1", After: []byte(`{"amount":100}`)})
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"seq":2,"action":"bid.pl`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	log, err = OpenLog(path)
+	require.NoError(t, err)
+	defer log.Close()
+	entries := log.Query(Filter{})
+	require.Len(t, entries, 1)
+	require.JSONEq(t, `{"amount":100}`, string(entries[0].After))
+
+	e, err := log.Append(Entry{Action: ActionBidPlaced, ItemID: "item1"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), e.Seq)
+}
+
+// Test that only the most recent entries are kept in memory, before and after a reopen
+func TestLog_Retention(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := OpenLog(path, WithRetention(2))
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := log.Append(Entry{Action: ActionBidPlaced, ItemID: "item1"})
+		require.NoError(t, err)
+	}
+
+	seqs := func(entries []Entry) []uint64 {
+		got := []uint64{}
+		for _, e := range entries {
+			got = append(got, e.Seq)
+		}
+		return got
+	}
+	require.Equal(t, []uint64{4, 5}, seqs(log.Query(Filter{})))
+	require.Equal(t, []uint64{5}, seqs(log.Query(Filter{After: 4})))
+	require.NoError(t, log.Close())
+
+	log, err = OpenLog(path, WithRetention(3))
+	require.NoError(t, err)
+	defer log.Close()
+	require.Equal(t, []uint64{3, 4, 5}, seqs(log.Query(Filter{})))
+	e, err := log.Append(Entry{Action: ActionBidPlaced, ItemID: "item1"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), e.Seq, "sequence numbers continue past trimmed entries")
+}
+
+// Test that concurrent appends get distinct sequence numbers and all reach the file
+func TestLog_ConcurrentAppends(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := OpenLog(path)
+	require.NoError(t, err)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := log.Append(Entry{Action: ActionBidPlaced, ItemID: "item1"})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, log.Close())
+
+	log, err = OpenLog(path)
+	require.NoError(t, err)
+	defer log.Close()
+	entries := log.Query(Filter{})
+	require.Len(t, entries, n)
+	for i, e := range entries {
+		require.Equal(t, uint64(i+1), e.Seq)
+	}
+}
+
+// Test that a log whose file cannot be written refuses further appends
+func TestLog_AppendFailure(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	log, err := OpenLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	_, err = log.Append(Entry{Action: ActionBidPlaced})
+	require.Error(t, err)
+	_, err = log.Append(Entry{Action: ActionBidPlaced})
+	require.Error(t, err)
+	require.Empty(t, log.Query(Filter{}), "failed appends are not queryable")
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	type value struct {
+		Amount float64 `json:"amount"`
+	}
+
+	// without a recorder, Record is a no-op
+	require.NoError(t, Ready(context.Background()))
+	Record(context.Background(), Change{Action: ActionBidPlaced})
+
+	log := NewMemoryLog()
+	recorder := NewRecorder(log, Entry{SourceIP: "203.0.113.7", RequestID: "req-1", Method: "POST", Path: "/bids", Action: "ignored"})
+	ctx := WithRecorder(context.Background(), recorder)
+	ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: "user1", KeyID: "key1"})
+	var previous *value
+	after := &value{Amount: 100}
+	require.NoError(t, Ready(ctx))
+	Record(ctx, Change{Action: ActionBidPlaced, ItemID: "item1", UserID: "user1", Resource: "bid1", Before: previous, After: after})
+	after.Amount = 999 // later edits do not change the record
+
+	entries := log.Query(Filter{})
+	require.Len(t, entries, 1)
+	require.Equal(t, ActionBidPlaced, entries[0].Action)
+	require.Equal(t, "bid1", entries[0].Resource)
+	require.Equal(t, "user1", entries[0].Actor)
+	require.Equal(t, "key1", entries[0].ActorKeyID)
+	require.Equal(t, "203.0.113.7", entries[0].SourceIP)
+	require.Equal(t, "req-1", entries[0].RequestID)
+	require.Nil(t, entries[0].Before, "a typed nil is recorded as absent")
+	require.JSONEq(t, `{"amount":100}`, string(entries[0].After))
+
+	// an anonymous change is attributed as such
+	Record(WithRecorder(context.Background(), recorder), Change{Action: ActionBidPlaced})
+	require.Equal(t, "anonymous", log.Query(Filter{After: 1})[0].Actor)
+
+	// once an append failed, the log is reported as not ready for further changes
+	closed, err := OpenLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+	ctx = WithRecorder(context.Background(), NewRecorder(closed, Entry{}))
+	require.NoError(t, Ready(ctx))
+	Record(ctx, Change{Action: ActionBidPlaced})
+	require.ErrorIs(t, Ready(ctx), biddingerrors.ErrAuditUnavailable)
+}
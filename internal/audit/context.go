@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/internal/biddingerrors"
+	"bidding-tracker/utils"
+)
+
+// Change describes a mutation made while serving a request
+type Change struct {
+	Action   string
+	ItemID   string
+	UserID   string
+	Resource string // ID of the affected resource, e.g. a bid or webhook
+	Before   any    // nil when the resource did not exist
+	After    any    // nil when the resource was removed
+}
+
+// Recorder appends the changes of one request to a log as they are made, completed
+// with the request's source IP, request ID, method and path and the actor found in the
+// context the change is recorded with
+type Recorder struct {
+	log     *Log
+	request Entry
+}
+
+// NewRecorder returns a recorder appending to log. The request fields of request
+// (SourceIP, RequestID, Method and Path) are copied into every entry.
+func NewRecorder(log *Log, request Entry) *Recorder {
+	return &Recorder{log: log, request: Entry{
+		SourceIP:  request.SourceIP,
+		RequestID: request.RequestID,
+		Method:    request.Method,
+		Path:      request.Path,
+	}}
+}
+
+// recorderKey is the context key for the request's Recorder
+type recorderKey struct{}
+
+// WithRecorder returns a context carrying r
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// Ready reports whether changes made for the request can be audited. Callers check it
+// before making a change, so a request fails before its change is committed rather than
+// after: a log that failed once refuses every later append. It is nil when auditing is
+// disabled.
+func Ready(ctx context.Context) error {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return nil
+	}
+	if err := r.log.Err(); err != nil {
+		return fmt.Errorf("%w: %w", biddingerrors.ErrAuditUnavailable, err)
+	}
+	return nil
+}
+
+// Record appends a committed change to the request's audit log and waits until it is
+// durable. Before and after are captured as JSON immediately, so later edits cannot alter
+// the record. The change stands when the append fails, so the failure is logged rather
+// than returned; Ready then fails for every later change. It is a no-op when auditing is
+// disabled.
+func Record(ctx context.Context, c Change) {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return
+	}
+
+	e := r.request
+	e.Action, e.ItemID, e.UserID, e.Resource = c.Action, c.ItemID, c.UserID, c.Resource
+	e.Actor = "anonymous"
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		e.Actor, e.ActorKeyID = principal.UserID, principal.KeyID
+	}
+	e.Before = snapshot(c.Action, c.Before)
+	e.After = snapshot(c.Action, c.After)
+
+	if _, err := r.log.Append(e); err != nil {
+		utils.ErrorContext(ctx, "audit: failed to record committed change", map[string]any{
+			"action":   c.Action,
+			"resource": c.Resource,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// snapshot encodes a before/after value; a value that cannot be encoded is dropped
+// rather than losing the whole entry
+func snapshot(action string, v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		utils.Warn("audit: failed to encode value", map[string]any{"action": action, "error": err.Error()})
+		return nil
+	}
+	if string(data) == "null" { // a typed nil pointer
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,401 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bidding-tracker/utils"
+)
+
+var (
+	// ErrInvalidAPIKey is returned for an unknown or malformed API key
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyExpired is returned for a key past its expiry
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrAPIKeyRevoked is returned for a revoked key
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+	// ErrAPIKeyNotFound is returned for an unknown key ID
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidKeyRequest is returned when an issuance request fails validation
+	ErrInvalidKeyRequest = errors.New("invalid api key request")
+	// ErrAPIKeyExists is returned when registering a secret that is already stored
+	ErrAPIKeyExists = errors.New("api key already registered")
+)
+
+// APIKeyPrefix starts every issued key so keys are recognisable in logs and secret scanners
+const APIKeyPrefix = "btk_"
+
+// APIKeyScopes are the permissions a key can be granted. PermAdmin implies all others.
+var APIKeyScopes = []Permission{PermBidsWrite, PermItemsRead, PermAdmin}
+
+// APIKey is an issued key. Only the SHA-256 hash of the secret is kept.
+type APIKey struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	OwnerID      string       `json:"owner_id"` // user the key acts as
+	Scopes       []Permission `json:"scopes"`
+	Prefix       string       `json:"prefix"` // first characters of the secret, to identify it
+	Hash         string       `json:"-"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ExpiresAt    *time.Time   `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time   `json:"revoked_at,omitempty"`
+	LastUsedAt   *time.Time   `json:"last_used_at,omitempty"`
+	RequestCount uint64       `json:"request_count"`
+}
+
+// storedKey is the on-disk form of a key; the hash is never part of API responses
+type storedKey struct {
+	APIKey
+	Hash string `json:"hash"`
+}
+
+// KeyRequest describes a key to issue
+type KeyRequest struct {
+	Name    string
+	OwnerID string
+	Scopes  []Permission
+	TTL     time.Duration // 0 means the key never expires
+}
+
+// APIKeyStore issues, authenticates and revokes API keys. When backed by a file,
+// issuance and revocation are persisted immediately, and usage on Flush and from the
+// background flusher started by FlushEvery.
+//
+// Authenticate only takes the read lock and counts usage with atomics, so requests
+// authenticated with API keys do not serialise on the store.
+type APIKeyStore struct {
+	path string
+	now  func() time.Time
+
+	saveMu sync.Mutex // serialises writes of the key file; taken before mu
+	mu     sync.RWMutex
+	keys   map[string]*keyEntry // by ID
+	byHash map[string]string    // hash -> ID
+	dirty  atomic.Bool          // usage changed since the last flush
+
+	stopOnce sync.Once
+	stop     chan struct{} // closed by Close to end the flusher
+	done     chan struct{} // closed when the flusher has returned; nil without one
+}
+
+// keyEntry is a stored key with its usage counters. The usage fields of key are
+// unused: usage lives in the atomics so Authenticate can update it under the read lock.
+type keyEntry struct {
+	key      APIKey
+	requests atomic.Uint64
+	lastUsed atomic.Int64 // UnixNano of the last use, 0 if never used
+}
+
+func newKeyEntry(key APIKey) *keyEntry {
+	e := &keyEntry{key: key}
+	e.requests.Store(key.RequestCount)
+	if key.LastUsedAt != nil {
+		e.lastUsed.Store(key.LastUsedAt.UnixNano())
+	}
+	e.key.RequestCount, e.key.LastUsedAt = 0, nil
+	return e
+}
+
+// snapshot returns the key with its current usage
+func (e *keyEntry) snapshot() APIKey {
+	key := e.key
+	key.RequestCount = e.requests.Load()
+	if n := e.lastUsed.Load(); n != 0 {
+		last := time.Unix(0, n).UTC()
+		key.LastUsedAt = &last
+	}
+	return key
+}
+
+// NewAPIKeyStore creates a store, loading existing keys from path when it is not empty
+func NewAPIKeyStore(path string) (*APIKeyStore, error) {
+	s := &APIKeyStore{
+		path:   path,
+		now:    time.Now,
+		keys:   make(map[string]*keyEntry),
+		byHash: make(map[string]string),
+		stop:   make(chan struct{}),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("api keys: read %s: %w", path, err)
+	}
+	var stored []storedKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("api keys: decode %s: %w", path, err)
+	}
+	for _, sk := range stored {
+		k := sk.APIKey
+		k.Hash = sk.Hash
+		s.keys[k.ID] = newKeyEntry(k)
+		s.byHash[k.Hash] = k.ID
+	}
+	return s, nil
+}
+
+// Issue creates a key with a random secret. The secret is returned only here.
+func (s *APIKeyStore) Issue(req KeyRequest) (APIKey, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return APIKey{}, "", fmt.Errorf("api keys: generate secret: %w", err)
+	}
+	secret := APIKeyPrefix + hex.EncodeToString(b)
+	key, err := s.Register(secret, req)
+	return key, secret, err
+}
+
+// Register stores a key for a caller-chosen secret, e.g. a bootstrap admin key from config
+func (s *APIKeyStore) Register(secret string, req KeyRequest) (APIKey, error) {
+	if len(secret) < 32 {
+		return APIKey{}, fmt.Errorf("%w: secret must be at least 32 characters", ErrInvalidKeyRequest)
+	}
+	if strings.TrimSpace(req.OwnerID) == "" {
+		return APIKey{}, fmt.Errorf("%w: owner_id is required", ErrInvalidKeyRequest)
+	}
+	if len(req.Scopes) == 0 {
+		return APIKey{}, fmt.Errorf("%w: at least one scope is required", ErrInvalidKeyRequest)
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return APIKey{}, fmt.Errorf("%w: unknown scope %q", ErrInvalidKeyRequest, scope)
+		}
+	}
+	if req.TTL < 0 {
+		return APIKey{}, fmt.Errorf("%w: ttl must not be negative", ErrInvalidKeyRequest)
+	}
+
+	now := s.now().UTC()
+	key := &APIKey{
+		ID:        utils.GenerateID(),
+		Name:      req.Name,
+		OwnerID:   req.OwnerID,
+		Scopes:    slices.Clone(req.Scopes),
+		Prefix:    secret[:min(len(secret), len(APIKeyPrefix)+8)],
+		Hash:      hashSecret(secret),
+		CreatedAt: now,
+	}
+	if req.TTL > 0 {
+		expires := now.Add(req.TTL)
+		key.ExpiresAt = &expires
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.byHash[key.Hash]; exists {
+		return APIKey{}, ErrAPIKeyExists
+	}
+	s.keys[key.ID] = newKeyEntry(*key)
+	s.byHash[key.Hash] = key.ID
+	if err := s.save(s.storedLocked()); err != nil {
+		delete(s.keys, key.ID)
+		delete(s.byHash, key.Hash)
+		return APIKey{}, err
+	}
+	return *key, nil
+}
+
+// Authenticate resolves a secret to its principal and records the use
+func (s *APIKeyStore) Authenticate(secret string) (Principal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byHash[hashSecret(secret)]
+	if !ok {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	entry := s.keys[id]
+	key := &entry.key
+	now := s.now().UTC()
+	if key.RevokedAt != nil {
+		return Principal{}, ErrAPIKeyRevoked
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return Principal{}, ErrAPIKeyExpired
+	}
+
+	entry.requests.Add(1)
+	entry.lastUsed.Store(now.UnixNano())
+	s.dirty.Store(true)
+	return Principal{UserID: key.OwnerID, KeyID: key.ID, Scopes: slices.Clone(key.Scopes)}, nil
+}
+
+// Revoke disables a key immediately. Revoking twice keeps the first revocation time.
+func (s *APIKeyStore) Revoke(id string) (APIKey, error) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.keys[id]
+	if !ok {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	if entry.key.RevokedAt == nil {
+		now := s.now().UTC()
+		entry.key.RevokedAt = &now
+		if err := s.save(s.storedLocked()); err != nil {
+			entry.key.RevokedAt = nil
+			return APIKey{}, err
+		}
+	}
+	return entry.snapshot(), nil
+}
+
+// Get returns a key with its usage record
+func (s *APIKeyStore) Get(id string) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.keys[id]
+	if !ok {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	return entry.snapshot(), nil
+}
+
+// List returns every key, oldest first
+func (s *APIKeyStore) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedLocked()
+}
+
+// Flush persists usage records. Authentication continues while the file is written.
+func (s *APIKeyStore) Flush() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.dirty.Store(false)
+	s.mu.RLock()
+	stored := s.storedLocked()
+	s.mu.RUnlock()
+	if err := s.save(stored); err != nil {
+		s.dirty.Store(true)
+		return err
+	}
+	return nil
+}
+
+// FlushEvery starts a goroutine that persists usage records every interval while they
+// have changed, so a crash loses at most one interval of usage. Call it at most once;
+// Close stops it.
+func (s *APIKeyStore) FlushEvery(interval time.Duration) {
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-t.C:
+				if !s.dirty.Load() {
+					continue
+				}
+				if err := s.Flush(); err != nil {
+					utils.Error("APIKeyStore: periodic flush failed", map[string]any{"path": s.path, "error": err.Error()})
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the background flusher and persists usage records; call it on shutdown
+func (s *APIKeyStore) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	if s.done != nil {
+		<-s.done
+	}
+	return s.Flush()
+}
+
+func (s *APIKeyStore) sortedLocked() []APIKey {
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, e := range s.keys {
+		keys = append(keys, e.snapshot())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// storedLocked returns the on-disk form of every key. The caller must hold mu.
+func (s *APIKeyStore) storedLocked() []storedKey {
+	keys := s.sortedLocked()
+	stored := make([]storedKey, len(keys))
+	for i, k := range keys {
+		stored[i] = storedKey{APIKey: k, Hash: k.Hash}
+	}
+	return stored
+}
+
+// save atomically rewrites the key file via write-to-temp and rename. The caller must
+// hold saveMu, and must have taken stored under it so an older state never wins.
+func (s *APIKeyStore) save(stored []storedKey) error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("api keys: encode: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("api keys: create: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("api keys: chmod: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("api keys: write: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("api keys: fsync: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("api keys: close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("api keys: install: %w", err)
+	}
+	return nil
+}
+
+// hashSecret is the at-rest form of a key. Secrets carry 256 random bits,
+// so a fast unsalted hash is sufficient and keeps lookups O(1).
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
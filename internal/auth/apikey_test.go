@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyStore_Authenticate(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	store, err := NewAPIKeyStore("")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	key, secret, err := store.Issue(KeyRequest{Name: "partner", OwnerID: "partner1", Scopes: []Permission{PermBidsWrite}, TTL: time.Hour})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, APIKeyPrefix))
+	require.True(t, strings.HasPrefix(secret, key.Prefix))
+	require.NotContains(t, key.Hash, secret)
+
+	principal, err := store.Authenticate(secret)
+	require.NoError(t, err)
+	require.Equal(t, Principal{UserID: "partner1", KeyID: key.ID, Scopes: []Permission{PermBidsWrite}}, principal)
+	require.True(t, principal.Can(PermBidsWrite))
+	require.False(t, principal.Can(PermAdmin))
+
+	_, err = store.Authenticate(secret)
+	require.NoError(t, err)
+	got, err := store.Get(key.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), got.RequestCount)
+	require.Equal(t, now, *got.LastUsedAt)
+
+	_, err = store.Authenticate(secret + "x")
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	now = now.Add(time.Hour)
+	_, err = store.Authenticate(secret)
+	require.ErrorIs(t, err, ErrAPIKeyExpired)
+
+	_, other, err := store.Issue(KeyRequest{OwnerID: "partner2", Scopes: []Permission{PermAdmin}})
+	require.NoError(t, err)
+	adminKey, err := store.Authenticate(other)
+	require.NoError(t, err)
+	require.True(t, adminKey.Can(PermUsersRead), "admin scope implies every permission")
+
+	_, err = store.Revoke(adminKey.KeyID)
+	require.NoError(t, err)
+	_, err = store.Authenticate(other)
+	require.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	_, err = store.Revoke("missing")
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyStore_Issue_Validation(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		req  KeyRequest
+	}{
+		{name: "missing_owner", req: KeyRequest{Scopes: []Permission{PermItemsRead}}},
+		{name: "missing_scopes", req: KeyRequest{OwnerID: "partner1"}},
+		{name: "unknown_scope", req: KeyRequest{OwnerID: "partner1", Scopes: []Permission{"items:delete"}}},
+		{name: "role_only_permission", req: KeyRequest{OwnerID: "partner1", Scopes: []Permission{PermUsersRead}}},
+		{name: "negative_ttl", req: KeyRequest{OwnerID: "partner1", Scopes: []Permission{PermItemsRead}, TTL: -time.Hour}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			store, err := NewAPIKeyStore("")
+			require.NoError(t, err)
+			_, _, err = store.Issue(tc.req)
+			require.ErrorIs(t, err, ErrInvalidKeyRequest)
+			require.Empty(t, store.List())
+		})
+	}
+}
+
+// Test that keys, revocations and usage survive a reopen without storing secrets
+func TestAPIKeyStore_Persistence(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := NewAPIKeyStore(path)
+	require.NoError(t, err)
+
+	kept, secret, err := store.Issue(KeyRequest{OwnerID: "partner1", Scopes: []Permission{PermItemsRead}})
+	require.NoError(t, err)
+	revoked, revokedSecret, err := store.Issue(KeyRequest{OwnerID: "partner2", Scopes: []Permission{PermItemsRead}})
+	require.NoError(t, err)
+	_, err = store.Revoke(revoked.ID)
+	require.NoError(t, err)
+	_, err = store.Authenticate(secret)
+	require.NoError(t, err)
+	require.NoError(t, store.Flush())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), secret)
+
+	reopened, err := NewAPIKeyStore(path)
+	require.NoError(t, err)
+	principal, err := reopened.Authenticate(secret)
+	require.NoError(t, err)
+	require.Equal(t, kept.ID, principal.KeyID)
+	_, err = reopened.Authenticate(revokedSecret)
+	require.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	got, err := reopened.Get(kept.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), got.RequestCount)
+
+	_, err = reopened.Register(secret, KeyRequest{OwnerID: "partner1", Scopes: []Permission{PermItemsRead}})
+	require.ErrorIs(t, err, ErrAPIKeyExists)
+}
+
+// Test that usage reaches the file from the background flusher, without Flush or Close
+func TestAPIKeyStore_FlushEvery(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := NewAPIKeyStore(path)
+	require.NoError(t, err)
+	key, secret, err := store.Issue(KeyRequest{OwnerID: "partner1", Scopes: []Permission{PermItemsRead}})
+	require.NoError(t, err)
+	store.FlushEvery(10 * time.Millisecond)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				_, err := store.Authenticate(secret)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		reopened, err := NewAPIKeyStore(path)
+		if err != nil {
+			return false
+		}
+		got, err := reopened.Get(key.ID)
+		return err == nil && got.RequestCount == 200 && got.LastUsedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
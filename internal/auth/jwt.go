@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidToken is returned for a token that is malformed, badly signed or fails claim checks
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for a correctly signed token past its expiry
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// JWTConfig configures token verification. At least one key must be set; a token is
+// accepted when signed with HS256 under HMACSecret or with EdDSA under Ed25519PublicKey.
+type JWTConfig struct {
+	HMACSecret       []byte
+	Ed25519PublicKey ed25519.PublicKey
+	Issuer           string        // required iss claim when set
+	Audience         string        // required aud entry when set
+	Leeway           time.Duration // clock skew allowed on exp, nbf and iat
+	MaxTTL           time.Duration // when set, reject tokens whose exp is further than this after iat
+}
+
+// Claims are the JWT claims understood by the service. sub is the user ID.
+type Claims struct {
+	Roles []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JWTVerifier validates bearer tokens and extracts the principal
+type JWTVerifier struct {
+	cfg    JWTConfig
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier creates a verifier, failing if no key is configured
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	if len(cfg.HMACSecret) == 0 && len(cfg.Ed25519PublicKey) == 0 {
+		return nil, errors.New("auth: a JWT HMAC secret or Ed25519 public key is required")
+	}
+	if len(cfg.Ed25519PublicKey) != 0 && len(cfg.Ed25519PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("auth: Ed25519 public key must be %d bytes", ed25519.PublicKeySize)
+	}
+
+	var methods []string
+	if len(cfg.HMACSecret) != 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(cfg.Ed25519PublicKey) != 0 {
+		methods = append(methods, jwt.SigningMethodEdDSA.Alg())
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods), // never let the token pick an algorithm we did not configure
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	return &JWTVerifier{cfg: cfg, parser: jwt.NewParser(opts...)}, nil
+}
+
+// Verify checks a token's signature and claims and returns its principal
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	var claims Claims
+	_, err := v.parser.ParseWithClaims(token, &claims, v.key)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return Principal{}, ErrTokenExpired
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+	if v.cfg.MaxTTL > 0 {
+		if claims.IssuedAt == nil {
+			return Principal{}, fmt.Errorf("%w: missing iat claim", ErrInvalidToken)
+		}
+		if claims.ExpiresAt.Sub(claims.IssuedAt.Time) > v.cfg.MaxTTL {
+			return Principal{}, fmt.Errorf("%w: lifetime exceeds %s", ErrInvalidToken, v.cfg.MaxTTL)
+		}
+	}
+	return Principal{UserID: claims.Subject, Roles: claims.Roles}, nil
+}
+
+// key selects the verification key for the token's algorithm
+func (v *JWTVerifier) key(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.cfg.HMACSecret, nil
+	case jwt.SigningMethodEdDSA.Alg():
+		return v.cfg.Ed25519PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}
+}
+
+// ParseEd25519PublicKey decodes a PEM-encoded PKIX Ed25519 public key
+func ParseEd25519PublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("auth: no PEM block found in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("auth: parse public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("auth: public key is %T, not Ed25519", key)
+	}
+	return pub, nil
+}
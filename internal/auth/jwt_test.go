@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	secret := []byte("test-secret")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier, err := NewJWTVerifier(JWTConfig{
+		HMACSecret:       secret,
+		Ed25519PublicKey: pub,
+		Issuer:           "auth.example.com",
+		Audience:         "bidding-tracker",
+		MaxTTL:           time.Hour,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	valid := func() Claims {
+		return Claims{
+			Roles: []string{"bidder"},
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user1",
+				Issuer:    "auth.example.com",
+				Audience:  jwt.ClaimStrings{"bidding-tracker"},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(15 * time.Minute)),
+			},
+		}
+	}
+	hs256 := func(c Claims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(secret)
+		require.NoError(t, err)
+		return s
+	}
+	eddsa := func(key ed25519.PrivateKey, c Claims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, c).SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+	with := func(f func(*Claims)) Claims {
+		c := valid()
+		f(&c)
+		return c
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		token     string
+		wantError error
+	}{
+		{name: "hmac_valid", token: hs256(valid())},
+		{name: "ed25519_valid", token: eddsa(priv, valid())},
+		{name: "ed25519_wrong_key", token: eddsa(otherPriv, valid()), wantError: ErrInvalidToken},
+		{name: "alg_none_rejected", token: unsigned, wantError: ErrInvalidToken},
+		{name: "expired", token: hs256(with(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) })), wantError: ErrTokenExpired},
+		{name: "missing_expiry", token: hs256(with(func(c *Claims) { c.ExpiresAt = nil })), wantError: ErrInvalidToken},
+		{name: "lifetime_too_long", token: hs256(with(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(2 * time.Hour)) })), wantError: ErrInvalidToken},
+		{name: "wrong_issuer", token: hs256(with(func(c *Claims) { c.Issuer = "evil.example.com" })), wantError: ErrInvalidToken},
+		{name: "wrong_audience", token: hs256(with(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other"} })), wantError: ErrInvalidToken},
+		{name: "missing_subject", token: hs256(with(func(c *Claims) { c.Subject = "" })), wantError: ErrInvalidToken},
+		{name: "garbage", token: "not.a.token", wantError: ErrInvalidToken},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			principal, err := verifier.Verify(tc.token)
+			if tc.wantError != nil {
+				require.ErrorIs(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, Principal{UserID: "user1", Roles: []string{"bidder"}}, principal)
+		})
+	}
+}
+
+func TestParseEd25519PublicKey(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	got, err := ParseEd25519PublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	require.Equal(t, pub, got)
+
+	_, err = ParseEd25519PublicKey([]byte("not pem"))
+	require.Error(t, err)
+
+	_, err = NewJWTVerifier(JWTConfig{})
+	require.Error(t, err, "a key is required")
+}
@@ -0,0 +1,25 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID string       `json:"user_id"`
+	Roles  []string     `json:"roles,omitempty"`
+	KeyID  string       `json:"key_id,omitempty"` // set when authenticated with an API key
+	Scopes []Permission `json:"scopes,omitempty"` // an API key's scopes; they replace roles
+}
+
+// principalKey is the context key for the request's Principal
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx, if the request was authenticated
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,60 @@
+package auth
+
+import "slices"
+
+// Roles carried in a token's roles claim
+const (
+	RoleAdmin  = "admin"
+	RoleSeller = "seller"
+	RoleBidder = "bidder"
+)
+
+// Permission is an operation a route can require
+type Permission string
+
+const (
+	PermBidsWrite  Permission = "bids:write"  // place bids as oneself
+	PermItemsRead  Permission = "items:read"  // read items and bids
+	PermItemsWrite Permission = "items:write" // close auctions
+	PermUsersRead  Permission = "users:read"  // read any user's bid history and preferences
+	PermAdmin      Permission = "admin"       // webhooks, change feed and other operator endpoints
+)
+
+// rolePermissions grants permissions to roles. Admins hold every permission.
+var rolePermissions = map[string][]Permission{
+	RoleBidder: {PermBidsWrite, PermItemsRead},
+	RoleSeller: {PermItemsRead, PermItemsWrite},
+	RoleAdmin:  {PermBidsWrite, PermItemsRead, PermItemsWrite, PermUsersRead, PermAdmin},
+}
+
+// Authorization error codes returned to clients; they are part of the API and must not change
+const (
+	CodeUnauthenticated  = "unauthenticated"   // no valid credentials were presented
+	CodePermissionDenied = "permission_denied" // the principal's roles lack the route's permission
+	CodeNotOwner         = "not_owner"         // the resource belongs to someone else
+)
+
+// Can reports whether the principal holds a permission. API key principals are
+// limited to their scopes, where admin implies every permission. A principal without
+// roles is a bidder, so tokens issued before roles existed keep working.
+func (p Principal) Can(perm Permission) bool {
+	if p.KeyID != "" {
+		return slices.Contains(p.Scopes, perm) || slices.Contains(p.Scopes, PermAdmin)
+	}
+	roles := p.Roles
+	if len(roles) == 0 {
+		roles = []string{RoleBidder}
+	}
+	for _, role := range roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessOwned reports whether the principal may act on a resource owned by ownerID:
+// owners always may, others need the override permission (e.g. PermUsersRead for bid history)
+func (p Principal) CanAccessOwned(ownerID string, override Permission) bool {
+	return p.UserID == ownerID || p.Can(override)
+}
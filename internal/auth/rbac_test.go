@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipal_Can(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name  string
+		roles []string
+		perm  Permission
+		want  bool
+	}{
+		{name: "bidder_places_bids", roles: []string{RoleBidder}, perm: PermBidsWrite, want: true},
+		{name: "bidder_not_admin", roles: []string{RoleBidder}, perm: PermAdmin, want: false},
+		{name: "no_roles_defaults_to_bidder", roles: nil, perm: PermBidsWrite, want: true},
+		{name: "seller_manages_items", roles: []string{RoleSeller}, perm: PermItemsWrite, want: true},
+		{name: "seller_cannot_bid", roles: []string{RoleSeller}, perm: PermBidsWrite, want: false},
+		{name: "roles_combine", roles: []string{RoleSeller, RoleBidder}, perm: PermBidsWrite, want: true},
+		{name: "admin_reads_users", roles: []string{RoleAdmin}, perm: PermUsersRead, want: true},
+		{name: "unknown_role_grants_nothing", roles: []string{"auditor"}, perm: PermItemsRead, want: false},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			p := Principal{UserID: "user1", Roles: tc.roles}
+			require.Equal(t, tc.want, p.Can(tc.perm))
+		})
+	}
+}
+
+func TestPrincipal_CanAccessOwned(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	seller := Principal{UserID: "seller1", Roles: []string{RoleSeller}}
+	require.True(t, seller.CanAccessOwned("seller1", PermAdmin), "owners can always access")
+	require.False(t, seller.CanAccessOwned("seller2", PermAdmin), "sellers can only edit their own items")
+
+	admin := Principal{UserID: "root", Roles: []string{RoleAdmin}}
+	require.True(t, admin.CanAccessOwned("seller2", PermAdmin))
+}
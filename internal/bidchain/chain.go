@@ -0,0 +1,90 @@
+// Package bidchain links each item's bids into a hash chain, so a bid history can be
+// checked for inserted, altered, reordered or deleted bids by anyone holding it.
+package bidchain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	model "bidding-tracker/internal/models"
+)
+
+// ErrBroken is returned when a bid history does not form a valid chain
+var ErrBroken = errors.New("bid chain broken")
+
+// version is mixed into every hash so the encoding can evolve without ambiguity
+const version = "bidchain/v1"
+
+// Hash returns the chain hash of bid given the hash of the bid recorded before it
+// ("" for an item's first bid). It covers every field of the bid except the hashes.
+func Hash(prevHash string, bid model.Bid) string {
+	// a JSON array is an unambiguous encoding even when IDs contain separators;
+	// the amount is formatted as text and the time as integer nanoseconds so the
+	// hash survives every storage backend's round trip
+	fields, _ := json.Marshal([]string{ // marshalling strings cannot fail
+		version,
+		prevHash,
+		bid.BidID,
+		bid.ItemID,
+		bid.UserID,
+		strconv.FormatFloat(bid.Amount, 'g', -1, 64),
+		strconv.FormatInt(bid.CreatedAt.UnixNano(), 10),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// Link sets bid's PrevHash and Hash so it extends a chain whose head is prevHash
+func Link(prevHash string, bid model.Bid) model.Bid {
+	bid.PrevHash = prevHash
+	bid.Hash = Hash(prevHash, bid)
+	return bid
+}
+
+// Result summarises a verified bid history
+type Result struct {
+	Bids      int    `json:"bids"`      // bids checked, including unchained ones
+	Unchained int    `json:"unchained"` // leading bids recorded before chaining existed
+	Head      string `json:"head"`      // hash of the last bid; "" when nothing is chained
+}
+
+// BrokenError locates the first bid that does not extend the chain
+type BrokenError struct {
+	Index  int    // position in the history
+	BidID  string // bid at that position
+	Reason string
+}
+
+func (e *BrokenError) Error() string {
+	return fmt.Sprintf("%s at bid %d (%s): %s", ErrBroken, e.Index, e.BidID, e.Reason)
+}
+
+func (e *BrokenError) Unwrap() error {
+	return ErrBroken
+}
+
+// Verify checks that bids, in recording order, form a chain and returns its head.
+// Bids recorded before chaining existed carry no hashes; they are only accepted as
+// a prefix of the history and are counted in Result.Unchained.
+func Verify(bids []model.Bid) (Result, error) {
+	res := Result{Bids: len(bids)}
+	for res.Unchained < len(bids) && bids[res.Unchained].Hash == "" && bids[res.Unchained].PrevHash == "" {
+		res.Unchained++
+	}
+
+	for i := res.Unchained; i < len(bids); i++ {
+		bid := bids[i]
+		switch {
+		case bid.PrevHash != res.Head:
+			return Result{}, &BrokenError{Index: i, BidID: bid.BidID, Reason: fmt.Sprintf("prev_hash %q does not match the previous bid's hash %q", bid.PrevHash, res.Head)}
+		case bid.Hash != Hash(bid.PrevHash, bid):
+			return Result{}, &BrokenError{Index: i, BidID: bid.BidID, Reason: "hash does not match the bid's contents"}
+		}
+		res.Head = bid.Hash
+	}
+	return res, nil
+}
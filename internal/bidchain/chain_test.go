@@ -0,0 +1,72 @@
+package bidchain
+
+import (
+	"testing"
+	"time"
+
+	model "bidding-tracker/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	legacy := model.Bid{BidID: "old", ItemID: "item1", UserID: "user0", Amount: 50, CreatedAt: base}
+	b1 := Link("", model.Bid{BidID: "b1", ItemID: "item1", UserID: "user1", Amount: 100, CreatedAt: base.Add(time.Second)})
+	b2 := Link(b1.Hash, model.Bid{BidID: "b2", ItemID: "item1", UserID: "user2", Amount: 150.25, CreatedAt: base.Add(2 * time.Second)})
+	b3 := Link(b2.Hash, model.Bid{BidID: "b3", ItemID: "item1", UserID: "user1", Amount: 200, CreatedAt: base.Add(3 * time.Second)})
+
+	with := func(b model.Bid, f func(*model.Bid)) model.Bid {
+		f(&b)
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		bids      []model.Bid
+		want      Result
+		wantBreak string // BidID of the first bad bid
+	}{
+		{name: "empty", bids: nil, want: Result{}},
+		{name: "intact", bids: []model.Bid{b1, b2, b3}, want: Result{Bids: 3, Head: b3.Hash}},
+		{name: "legacy_prefix", bids: []model.Bid{legacy, b1, b2}, want: Result{Bids: 3, Unchained: 1, Head: b2.Hash}},
+		{name: "altered_amount", bids: []model.Bid{b1, with(b2, func(b *model.Bid) { b.Amount = 151 }), b3}, wantBreak: "b2"},
+		{name: "altered_time", bids: []model.Bid{b1, with(b2, func(b *model.Bid) { b.CreatedAt = b.CreatedAt.Add(time.Nanosecond) }), b3}, wantBreak: "b2"},
+		{name: "deleted_bid", bids: []model.Bid{b1, b3}, wantBreak: "b3"},
+		{name: "reordered", bids: []model.Bid{b2, b1, b3}, wantBreak: "b2"},
+		{name: "inserted_unchained", bids: []model.Bid{b1, legacy, b2}, wantBreak: "old"},
+		{name: "rehashed_forgery", bids: []model.Bid{b1, Link(b1.Hash, with(b2, func(b *model.Bid) { b.Amount = 151 })), b3}, wantBreak: "b3"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			res, err := Verify(tc.bids)
+			if tc.wantBreak != "" {
+				require.ErrorIs(t, err, ErrBroken)
+				var broken *BrokenError
+				require.ErrorAs(t, err, &broken)
+				require.Equal(t, tc.wantBreak, broken.BidID)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, res)
+		})
+	}
+}
+
+// Test that the hash is stable across time zones and monotonic clock readings
+func TestHash_Canonical(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	now := time.Now()
+	bid := model.Bid{BidID: "b1", ItemID: "item1", UserID: "user1", Amount: 100, CreatedAt: now}
+	utc := bid
+	utc.CreatedAt = now.UTC().Round(0)
+	require.Equal(t, Hash("", bid), Hash("", utc))
+	require.NotEqual(t, Hash("", bid), Hash("x", bid), "the previous hash is covered")
+}
@@ -1,76 +1,281 @@
 package bidding
 
 import (
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/bidchain"
 	"bidding-tracker/internal/biddingerrors"
+	"bidding-tracker/internal/events"
 	"bidding-tracker/internal/models"
 	"bidding-tracker/internal/repository"
+	"bidding-tracker/internal/tracing"
 	"bidding-tracker/utils"
+	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"sync"
 	"time"
 )
 
+// itemLockStripes is the number of mutexes bids are serialized on, keyed by item
+const itemLockStripes = 64
+
+// placeBidAttempts bounds how often a bid is placed again after losing a race to a bid
+// recorded by another instance
+const placeBidAttempts = 3
+
 // BiddingService defines the business logic for auction bidding
 type BiddingService struct {
-	repo repository.AuctionDB
+	repo      repository.AuctionDB
+	publisher events.Publisher // optional; receives domain events for accepted bids
+
+	minIncrement float64 // a bid must beat the winning bid by at least this much; 0 means any amount above it
+
+	// itemLocks serialize validate -> record -> publish per item, so a bid is checked
+	// against the latest winner and events for an item are published in order
+	itemLocks [itemLockStripes]sync.Mutex
+
+	waiters leaderWaiters // long-poll callers of WaitForWinningBid
+}
+
+// Option configures optional BiddingService dependencies
+type Option func(*BiddingService)
+
+// WithPublisher makes the service publish domain events to p
+func WithPublisher(p events.Publisher) Option {
+	return func(s *BiddingService) {
+		s.publisher = p
+	}
+}
+
+// WithMinIncrement requires each bid to exceed the winning bid by at least inc
+func WithMinIncrement(inc float64) Option {
+	return func(s *BiddingService) {
+		s.minIncrement = inc
+	}
 }
 
 // NewBiddingService creates a new BiddingService instance
-func NewBiddingService(repo repository.AuctionDB) *BiddingService {
-	return &BiddingService{
-		repo: repo,
+func NewBiddingService(repo repository.AuctionDB, opts ...Option) *BiddingService {
+	s := &BiddingService{
+		repo: repository.Traced(repo), // repository calls are spans of the request's trace
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // PlaceBid validates and records a user's bid for an item
-func (s *BiddingService) PlaceBid(itemID, userID string, amount float64) (models.Bid, error) {
-	if err := s.validateBid(itemID, userID, amount); err != nil {
+func (s *BiddingService) PlaceBid(ctx context.Context, itemID, userID string, amount float64) (models.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.PlaceBid")
+	span.SetAttribute("user_id", userID)
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
+	// a bid that could not be audited is refused before it is recorded, never after
+	if err := audit.Ready(ctx); err != nil {
+		err = fmt.Errorf("service: bid on item %s refused: %w", itemID, err)
+		observeBid(err)
+		span.SetError(err)
 		return models.Bid{}, err
 	}
 
-	bid := models.Bid{
+	// another instance sharing the database may record a bid between validation and
+	// recording; placing again validates against the new leader
+	var (
+		bid      models.Bid
+		previous *models.Bid
+		err      error
+	)
+	for attempt := 1; ; attempt++ {
+		bid, previous, err = s.placeBid(ctx, itemID, userID, amount)
+		if !errors.Is(err, biddingerrors.ErrBidConflict) || attempt == placeBidAttempts {
+			break
+		}
+	}
+	if err == nil {
+		// outside the item's lock, so bids on the item do not queue behind the audit fsync
+		audit.Record(ctx, audit.Change{Action: audit.ActionBidPlaced, ItemID: itemID, UserID: userID, Resource: bid.BidID, Before: previous, After: bid})
+	}
+	observeBid(err)
+	span.SetError(err)
+	return bid, err
+}
+
+// placeBid records one attempt at a bid under the item's lock and returns it with the
+// leader it outbid
+func (s *BiddingService) placeBid(ctx context.Context, itemID, userID string, amount float64) (models.Bid, *models.Bid, error) {
+	mu := s.itemLock(itemID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	previous, err := s.validateBid(ctx, itemID, userID, amount)
+	if err != nil {
+		return models.Bid{}, nil, err
+	}
+
+	// an accepted bid always outbids the winner, so the winner is the item's
+	// last recorded bid and its hash is the head the new bid extends
+	var head string
+	if previous != nil {
+		head = previous.Hash
+	}
+	seq, err := s.nextSeq(ctx, itemID, previous)
+	if err != nil {
+		return models.Bid{}, nil, err
+	}
+	bid := bidchain.Link(head, models.Bid{
 		BidID:     utils.GenerateID(),
 		ItemID:    itemID,
 		UserID:    userID,
 		Amount:    amount,
 		CreatedAt: time.Now().UTC(),
+		Seq:       seq,
+	})
+
+	if err := s.repo.RecordBidForItem(ctx, bid); err != nil {
+		return models.Bid{}, nil, fmt.Errorf("service: failed to record bid for item %s by user %s: %w", itemID, userID, err)
 	}
 
-	if err := s.repo.RecordBidForItem(bid); err != nil {
-		return models.Bid{}, fmt.Errorf("service: failed to record bid for item %s by user %s: %w", itemID, userID, err)
+	s.publishBid(ctx, bid, previous)
+	s.waiters.notify(itemID) // every accepted bid is the new leader
+
+	return bid, previous, nil
+}
+
+// nextSeq returns the sequence number of an item's next bid, following the last recorded
+// bid. Bids stored before sequences existed have none, so then the history is counted.
+// The caller must hold the item's lock.
+func (s *BiddingService) nextSeq(ctx context.Context, itemID string, last *models.Bid) (uint64, error) {
+	if last == nil {
+		return 1, nil
+	}
+	if last.Seq > 0 {
+		return last.Seq + 1, nil
 	}
+	bids, err := s.repo.GetBidsByItem(ctx, itemID)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to count bids for item %s: %w", itemID, err)
+	}
+	return uint64(len(bids)) + 1, nil
+}
 
-	return bid, nil
+// itemLock returns the mutex that serializes bids on an item
+func (s *BiddingService) itemLock(itemID string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(itemID))
+	return &s.itemLocks[h.Sum32()%itemLockStripes]
 }
 
-// validateBid checks input validity and business rules for bidding
-func (s *BiddingService) validateBid(itemID, userID string, amount float64) error {
+// publishBid emits the events for an accepted bid. An accepted bid always outbids the
+// previous winner, so the leader changes whenever the winning user is different.
+// The caller must hold the item's lock.
+func (s *BiddingService) publishBid(ctx context.Context, bid models.Bid, previous *models.Bid) {
+	if s.publisher == nil {
+		return
+	}
+
+	evs := []events.Event{events.BidPlaced{Bid: bid}}
+	if previous == nil || previous.UserID != bid.UserID {
+		evs = append(evs, events.LeaderChanged{Leader: bid, Previous: previous})
+	}
+	s.publisher.Publish(ctx, evs...)
+}
+
+// validateBid checks input validity and business rules for bidding.
+// It returns the current winning bid, or nil if the item has no bids yet.
+func (s *BiddingService) validateBid(ctx context.Context, itemID, userID string, amount float64) (*models.Bid, error) {
 	if itemID == "" || userID == "" {
-		return fmt.Errorf("service: %w - missing itemID or userID", biddingerrors.ErrInvalidBid)
+		return nil, fmt.Errorf("service: %w - missing itemID or userID", biddingerrors.ErrInvalidBid)
 	}
 	if amount <= 0 {
-		return fmt.Errorf("service: %w - non-positive bid amount", biddingerrors.ErrInvalidBid)
+		return nil, fmt.Errorf("service: %w - non-positive bid amount", biddingerrors.ErrInvalidBid)
 	}
 
-	winningBid, err := s.repo.GetWinningBid(itemID)
+	winningBid, err := s.repo.GetWinningBid(ctx, itemID)
 	if err == nil {
 		if amount <= winningBid.Amount {
-			return fmt.Errorf("service: %w - current highest bid is %.2f", biddingerrors.ErrBidTooLow, winningBid.Amount)
+			return nil, fmt.Errorf("service: %w - current highest bid is %.2f", biddingerrors.ErrBidTooLow, winningBid.Amount)
+		}
+		if s.minIncrement > 0 && amount < winningBid.Amount+s.minIncrement {
+			return nil, fmt.Errorf("service: %w - minimum next bid is %.2f", biddingerrors.ErrBidTooLow, winningBid.Amount+s.minIncrement)
 		}
+		return &winningBid, nil
 	} else if !errors.Is(err, biddingerrors.ErrNoBids) {
-		return fmt.Errorf("service: failed to check winning bid: %w", err)
+		return nil, fmt.Errorf("service: failed to check winning bid: %w", err)
 	}
 
-	return nil
+	return nil, nil
+}
+
+// CloseAuction ends bidding on an item and returns the closed item and its winning bid,
+// nil when nobody bid. Bids placed after the close fail with ErrAuctionClosed.
+func (s *BiddingService) CloseAuction(ctx context.Context, itemID string) (models.Item, *models.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.CloseAuction")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
+	if err := audit.Ready(ctx); err != nil {
+		err = fmt.Errorf("service: close of item %s refused: %w", itemID, err)
+		span.SetError(err)
+		return models.Item{}, nil, err
+	}
+
+	item, winner, err := s.closeAuction(ctx, itemID)
+	if err == nil {
+		before := item
+		before.ClosedAt = nil
+		audit.Record(ctx, audit.Change{Action: audit.ActionAuctionClosed, ItemID: itemID, Resource: itemID, Before: before, After: item})
+	}
+	span.SetError(err)
+	return item, winner, err
+}
+
+// closeAuction is CloseAuction without the span
+func (s *BiddingService) closeAuction(ctx context.Context, itemID string) (models.Item, *models.Bid, error) {
+	if itemID == "" {
+		return models.Item{}, nil, fmt.Errorf("service: %w - empty item ID", biddingerrors.ErrInvalidBid)
+	}
+
+	// holding the item's lock orders the close after bids in flight on this instance, so
+	// the winner read below is final and AuctionClosed follows their events
+	mu := s.itemLock(itemID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	item, err := s.repo.CloseItem(ctx, itemID, time.Now().UTC())
+	if err != nil {
+		return models.Item{}, nil, fmt.Errorf("service: failed to close auction for item %s: %w", itemID, err)
+	}
+
+	var winner *models.Bid
+	bid, err := s.repo.GetWinningBid(ctx, itemID)
+	switch {
+	case err == nil:
+		winner = &bid
+	case !errors.Is(err, biddingerrors.ErrNoBids):
+		return models.Item{}, nil, fmt.Errorf("service: failed to get winning bid for closed item %s: %w", itemID, err)
+	}
+
+	if s.publisher != nil {
+		s.publisher.Publish(ctx, events.AuctionClosed{Item: itemID, Winner: winner, ClosedAt: *item.ClosedAt})
+	}
+
+	return item, winner, nil
 }
 
 // GetBidsForItem returns all bids for a specific item
-func (s *BiddingService) GetBidsForItem(itemID string) ([]models.Bid, error) {
+func (s *BiddingService) GetBidsForItem(ctx context.Context, itemID string) ([]models.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.GetBidsForItem")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
 	if itemID == "" {
 		return nil, fmt.Errorf("service: %w - empty item ID", biddingerrors.ErrInvalidBid)
 	}
 
-	bids, err := s.repo.GetBidsByItem(itemID)
+	bids, err := s.repo.GetBidsByItem(ctx, itemID)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to get bids for item %s: %w", itemID, err)
 	}
@@ -79,12 +284,16 @@ func (s *BiddingService) GetBidsForItem(itemID string) ([]models.Bid, error) {
 }
 
 // GetWinningBid returns the highest bid for a specific item
-func (s *BiddingService) GetWinningBid(itemID string) (models.Bid, error) {
+func (s *BiddingService) GetWinningBid(ctx context.Context, itemID string) (models.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.GetWinningBid")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
 	if itemID == "" {
 		return models.Bid{}, fmt.Errorf("service: %w - empty item ID", biddingerrors.ErrInvalidBid)
 	}
 
-	winningBid, err := s.repo.GetWinningBid(itemID)
+	winningBid, err := s.repo.GetWinningBid(ctx, itemID)
 	if err != nil {
 		return models.Bid{}, fmt.Errorf("service: failed to get winning bid for item %s: %w", itemID, err)
 	}
@@ -92,13 +301,103 @@ func (s *BiddingService) GetWinningBid(itemID string) (models.Bid, error) {
 	return winningBid, nil
 }
 
+// WaitForWinningBid returns the winning bid for an item once it is no longer afterBidID,
+// waiting up to timeout for a new leader. On timeout it returns the current state,
+// which is the unchanged leader or ErrNoBids. An empty afterBidID waits for the first bid.
+func (s *BiddingService) WaitForWinningBid(ctx context.Context, itemID, afterBidID string, timeout time.Duration) (models.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.WaitForWinningBid")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	released := s.waiters.releasedCh()
+
+	for {
+		// watch before reading, so a bid recorded in between still wakes us
+		changed, release := s.waiters.watch(itemID)
+		bid, err := s.GetWinningBid(ctx, itemID)
+		if err != nil && !errors.Is(err, biddingerrors.ErrNoBids) {
+			release()
+			return models.Bid{}, err
+		}
+		if err == nil && bid.BidID != afterBidID {
+			release()
+			return bid, nil
+		}
+
+		select {
+		case <-changed:
+			release()
+		case <-timer.C:
+			release()
+			return bid, err
+		case <-released:
+			release()
+			return bid, err
+		case <-ctx.Done():
+			release()
+			return models.Bid{}, fmt.Errorf("service: wait for winning bid on item %s: %w", itemID, ctx.Err())
+		}
+	}
+}
+
+// ReleaseWaiters ends every WaitForWinningBid call as if its timeout had passed, and makes
+// later calls return without waiting. It is called on shutdown so long polls do not
+// hold up draining.
+func (s *BiddingService) ReleaseWaiters() {
+	s.waiters.releaseAll()
+}
+
+// VerifyChain checks that an item's bid history forms an intact hash chain.
+// A broken chain is reported as an error wrapping bidchain.ErrBroken.
+func (s *BiddingService) VerifyChain(ctx context.Context, itemID string) (bidchain.Result, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.VerifyChain")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
+	bids, err := s.GetBidsForItem(ctx, itemID)
+	if err != nil && !errors.Is(err, biddingerrors.ErrNoBids) {
+		return bidchain.Result{}, err
+	}
+
+	res, err := bidchain.Verify(bids)
+	if err != nil {
+		return bidchain.Result{}, fmt.Errorf("service: verify chain for item %s: %w", itemID, err)
+	}
+	return res, nil
+}
+
+// GetItem returns an item
+func (s *BiddingService) GetItem(ctx context.Context, itemID string) (models.Item, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.GetItem")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+
+	if itemID == "" {
+		return models.Item{}, fmt.Errorf("service: %w - empty item ID", biddingerrors.ErrInvalidBid)
+	}
+
+	item, err := s.repo.GetItem(ctx, itemID)
+	if err != nil {
+		span.SetError(err)
+		return models.Item{}, fmt.Errorf("service: failed to get item %s: %w", itemID, err)
+	}
+
+	return item, nil
+}
+
 // GetItemsByUser returns all items a user has placed bids on
-func (s *BiddingService) GetItemsByUser(userID string) ([]models.Item, error) {
+func (s *BiddingService) GetItemsByUser(ctx context.Context, userID string) ([]models.Item, error) {
+	ctx, span := tracing.StartSpan(ctx, "BiddingService.GetItemsByUser")
+	span.SetAttribute("user_id", userID)
+	defer span.End()
+
 	if userID == "" {
 		return nil, fmt.Errorf("service: %w - empty user ID", biddingerrors.ErrInvalidBid)
 	}
 
-	items, err := s.repo.GetItemsByUser(userID)
+	items, err := s.repo.GetItemsByUser(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to get items for user %s: %w", userID, err)
 	}
@@ -1,16 +1,23 @@
 package bidding
 
 import (
+	"bidding-tracker/internal/bidchain"
 	"bidding-tracker/internal/biddingerrors"
+	"bidding-tracker/internal/events"
 	model "bidding-tracker/internal/models"
 	"bidding-tracker/internal/repository"
+	"context"
 	"errors"
+	"fmt"
 	"math"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver for the sql backend
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,8 +47,8 @@ func TestBiddingService_PlaceBid(t *testing.T) {
 			userID: "user1",
 			amount: 100,
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item1").Return(model.Bid{}, biddingerrors.ErrNoBids)
-				mockRepo.EXPECT().RecordBidForItem(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(model.Bid{}, biddingerrors.ErrNoBids)
+				mockRepo.EXPECT().RecordBidForItem(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			expectError:   false,
 			expectedError: nil,
@@ -88,7 +95,7 @@ func TestBiddingService_PlaceBid(t *testing.T) {
 			userID: "user2",
 			amount: 80,
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item1").Return(model.Bid{Amount: 100}, nil)
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(model.Bid{Amount: 100}, nil)
 			},
 			expectError:   true,
 			expectedError: biddingerrors.ErrBidTooLow,
@@ -99,8 +106,8 @@ func TestBiddingService_PlaceBid(t *testing.T) {
 			userID: "user3",
 			amount: 120,
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item1").Return(model.Bid{Amount: 100}, nil)
-				mockRepo.EXPECT().RecordBidForItem(gomock.Any()).Return(errors.New("repo write failed"))
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(model.Bid{Amount: 100, Seq: 1}, nil)
+				mockRepo.EXPECT().RecordBidForItem(gomock.Any(), gomock.Any()).Return(errors.New("repo write failed"))
 			},
 			expectError:   true,
 			expectedError: nil, // Service wraps repo error, we don’t match specific error here
@@ -111,8 +118,24 @@ func TestBiddingService_PlaceBid(t *testing.T) {
 			userID: "user4",
 			amount: math.MaxFloat64,
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item1").Return(model.Bid{Amount: 100}, nil)
-				mockRepo.EXPECT().RecordBidForItem(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(model.Bid{Amount: 100, Seq: 1}, nil)
+				mockRepo.EXPECT().RecordBidForItem(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectError:   false,
+			expectedError: nil,
+		},
+		{
+			name:   "legacy_history_without_seq",
+			itemID: "item1",
+			userID: "user5",
+			amount: 300,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(model.Bid{Amount: 200}, nil)
+				mockRepo.EXPECT().GetBidsByItem(gomock.Any(), "item1").Return([]model.Bid{{Amount: 100}, {Amount: 200}}, nil)
+				mockRepo.EXPECT().RecordBidForItem(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, bid model.Bid) error {
+					require.Equal(t, uint64(3), bid.Seq, "sequence follows the counted history")
+					return nil
+				})
 			},
 			expectError:   false,
 			expectedError: nil,
@@ -127,7 +150,7 @@ func TestBiddingService_PlaceBid(t *testing.T) {
 
 			tc.mockSetup()
 
-			bid, err := service.PlaceBid(tc.itemID, tc.userID, tc.amount)
+			bid, err := service.PlaceBid(context.Background(), tc.itemID, tc.userID, tc.amount)
 
 			if tc.expectError {
 				require.Error(t, err)
@@ -152,6 +175,327 @@ func TestBiddingService_PlaceBid(t *testing.T) {
 	}
 }
 
+// Tests that a minimum increment is enforced over the winning bid
+func TestBiddingService_PlaceBidMinIncrement(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	service := NewBiddingService(repo, WithMinIncrement(5))
+	ctx := context.Background()
+
+	_, err := service.PlaceBid(ctx, "item1", "user1", 1)
+	require.NoError(t, err, "the first bid has nothing to beat")
+	_, err = service.PlaceBid(ctx, "item1", "user2", 5.99)
+	require.ErrorIs(t, err, biddingerrors.ErrBidTooLow)
+	require.ErrorContains(t, err, "minimum next bid is 6.00")
+	_, err = service.PlaceBid(ctx, "item1", "user2", 6)
+	require.NoError(t, err)
+}
+
+// Tests the events PlaceBid publishes
+func TestBiddingService_PlaceBidPublishesEvents(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	leader := model.Bid{BidID: "bid0", ItemID: "item1", UserID: "user1", Amount: 100, Seq: 1}
+
+	tests := []struct {
+		name          string
+		userID        string
+		amount        float64
+		winning       model.Bid
+		winningErr    error
+		wantTypes     []string
+		wantPrevious  *model.Bid
+		expectedError error
+	}{
+		{
+			name:         "first_bid_takes_lead",
+			userID:       "user1",
+			amount:       100,
+			winningErr:   biddingerrors.ErrNoBids,
+			wantTypes:    []string{events.NameBidPlaced, events.NameLeaderChanged},
+			wantPrevious: nil,
+		},
+		{
+			name:         "outbid_changes_leader",
+			userID:       "user2",
+			amount:       150,
+			winning:      leader,
+			wantTypes:    []string{events.NameBidPlaced, events.NameLeaderChanged},
+			wantPrevious: &leader,
+		},
+		{
+			name:      "leader_raises_own_bid",
+			userID:    "user1",
+			amount:    150,
+			winning:   leader,
+			wantTypes: []string{events.NameBidPlaced},
+		},
+		{
+			name:          "rejected_bid_publishes_nothing",
+			userID:        "user2",
+			amount:        50,
+			winning:       leader,
+			wantTypes:     nil,
+			expectedError: biddingerrors.ErrBidTooLow,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			ctrl := gomock.NewController(t)
+			mockRepo := repository.NewMockAuctionDB(ctrl)
+			mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(tc.winning, tc.winningErr)
+			if tc.expectedError == nil {
+				mockRepo.EXPECT().RecordBidForItem(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			var published []events.Event
+			bus := events.NewBus(events.BusConfig{})
+			bus.Subscribe("recorder", events.SubscriberFunc(func(_ context.Context, ev events.Event) error {
+				published = append(published, ev)
+				return nil
+			}), events.Sync)
+			service := NewBiddingService(mockRepo, WithPublisher(bus))
+
+			bid, err := service.PlaceBid(context.Background(), "item1", tc.userID, tc.amount)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var gotTypes []string
+			for _, ev := range published {
+				gotTypes = append(gotTypes, ev.EventName())
+				switch e := ev.(type) {
+				case events.BidPlaced:
+					require.Equal(t, bid, e.Bid)
+				case events.LeaderChanged:
+					require.Equal(t, bid, e.Leader)
+					require.Equal(t, tc.wantPrevious, e.Previous)
+				}
+			}
+			require.Equal(t, tc.wantTypes, gotTypes)
+		})
+	}
+}
+
+// Tests that concurrent bids on one item are validated against the latest winner
+// and their events are published in the order the bids were accepted
+func TestBiddingService_PlaceBidEventOrdering(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 1}))
+
+	var mu sync.Mutex
+	var placed []float64
+	bus := events.NewBus(events.BusConfig{})
+	defer bus.Close()
+	bus.Subscribe("recorder", events.SubscriberFunc(func(_ context.Context, ev events.Event) error {
+		if e, ok := ev.(events.BidPlaced); ok {
+			mu.Lock()
+			placed = append(placed, e.Bid.Amount)
+			mu.Unlock()
+		}
+		return nil
+	}), events.Async)
+	service := NewBiddingService(repo, WithPublisher(bus))
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 200; i++ {
+		wg.Add(1)
+		go func(amount float64) {
+			defer wg.Done()
+			_, _ = service.PlaceBid(context.Background(), "item1", fmt.Sprintf("user%d", int(amount)%7), amount)
+		}(float64(i))
+	}
+	wg.Wait()
+	bus.Close() // drain the async queue
+
+	bids, err := repo.GetBidsByItem(context.Background(), "item1")
+	require.NoError(t, err)
+	require.Len(t, placed, len(bids))
+	for i, b := range bids {
+		require.Equal(t, b.Amount, placed[i], "events must follow recording order")
+		if i > 0 {
+			require.Greater(t, b.Amount, bids[i-1].Amount, "every accepted bid must beat the previous winner")
+		}
+	}
+}
+
+// Tests CloseAuction: the winner is reported and published, and later bids are rejected
+func TestBiddingService_CloseAuction(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name          string
+		itemID        string
+		bids          []float64
+		wantWinner    float64 // 0 when nobody bid
+		expectedError error
+	}{
+		{name: "closes_with_winner", itemID: "item1", bids: []float64{100, 150}, wantWinner: 150},
+		{name: "closes_without_bids", itemID: "item1"},
+		{name: "missing_item", itemID: "missing", expectedError: biddingerrors.ErrItemNotFound},
+		{name: "empty_item_id", itemID: "", expectedError: biddingerrors.ErrInvalidBid},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			repo := repository.NewMemoryRepo()
+			require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 1}))
+
+			var closed []events.AuctionClosed
+			bus := events.NewBus(events.BusConfig{})
+			bus.Subscribe("recorder", events.SubscriberFunc(func(_ context.Context, ev events.Event) error {
+				if e, ok := ev.(events.AuctionClosed); ok {
+					closed = append(closed, e)
+				}
+				return nil
+			}), events.Sync)
+			service := NewBiddingService(repo, WithPublisher(bus))
+
+			for i, amount := range tc.bids {
+				_, err := service.PlaceBid(context.Background(), "item1", fmt.Sprintf("user%d", i), amount)
+				require.NoError(t, err)
+			}
+
+			item, winner, err := service.CloseAuction(context.Background(), tc.itemID)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				require.Empty(t, closed)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, item.ClosedAt)
+			if tc.wantWinner == 0 {
+				require.Nil(t, winner)
+			} else {
+				require.NotNil(t, winner)
+				require.Equal(t, tc.wantWinner, winner.Amount)
+			}
+			require.Equal(t, []events.AuctionClosed{{Item: "item1", Winner: winner, ClosedAt: *item.ClosedAt}}, closed)
+
+			_, err = service.PlaceBid(context.Background(), "item1", "late", 1000)
+			require.ErrorIs(t, err, biddingerrors.ErrAuctionClosed)
+			_, _, err = service.CloseAuction(context.Background(), "item1")
+			require.ErrorIs(t, err, biddingerrors.ErrAuctionClosed)
+			require.Len(t, closed, 1, "closing twice publishes once")
+		})
+	}
+}
+
+// Tests WaitForWinningBid long-polling
+func TestBiddingService_WaitForWinningBid(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name       string
+		seed       []float64 // bids placed before waiting
+		afterFirst bool      // wait after the current leader's bid ID
+		bidAfter   float64   // placed while waiting; 0 places nothing
+		wantAmount float64
+		wantError  error
+		wantWait   bool // whether the call must block until the timeout
+	}{
+		{name: "leader_differs_returns_immediately", seed: []float64{100}, wantAmount: 100},
+		{name: "woken_by_new_leader", seed: []float64{100}, afterFirst: true, bidAfter: 150, wantAmount: 150},
+		{name: "woken_by_first_bid", bidAfter: 120, wantAmount: 120},
+		{name: "timeout_unchanged_leader", seed: []float64{100}, afterFirst: true, wantAmount: 100, wantWait: true},
+		{name: "timeout_no_bids", wantError: biddingerrors.ErrNoBids, wantWait: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			repo := repository.NewMemoryRepo()
+			require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 1}))
+			service := NewBiddingService(repo)
+
+			var after string
+			for _, amount := range tc.seed {
+				bid, err := service.PlaceBid(context.Background(), "item1", "user1", amount)
+				require.NoError(t, err)
+				if tc.afterFirst {
+					after = bid.BidID
+				}
+			}
+
+			if tc.bidAfter > 0 {
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					_, _ = service.PlaceBid(context.Background(), "item1", "user2", tc.bidAfter)
+				}()
+			}
+
+			timeout := 300 * time.Millisecond
+			if tc.bidAfter > 0 {
+				timeout = 5 * time.Second // woken long before the timeout
+			}
+			start := time.Now()
+			bid, err := service.WaitForWinningBid(context.Background(), "item1", after, timeout)
+			elapsed := time.Since(start)
+
+			if tc.wantError != nil {
+				require.ErrorIs(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantAmount, bid.Amount)
+			}
+			if tc.wantWait {
+				require.GreaterOrEqual(t, elapsed, timeout)
+			} else {
+				require.Less(t, elapsed, time.Second)
+			}
+			require.Empty(t, service.waiters.items, "waiters must be released")
+		})
+	}
+}
+
+// Tests that ReleaseWaiters ends current and later long polls with the current leader
+func TestBiddingService_ReleaseWaiters(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 1}))
+	service := NewBiddingService(repo)
+	leader, err := service.PlaceBid(context.Background(), "item1", "user1", 100)
+	require.NoError(t, err)
+
+	done := make(chan model.Bid)
+	go func() {
+		bid, err := service.WaitForWinningBid(context.Background(), "item1", leader.BidID, time.Minute)
+		require.NoError(t, err)
+		done <- bid
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the wait start
+	service.ReleaseWaiters()
+	select {
+	case bid := <-done:
+		require.Equal(t, leader.BidID, bid.BidID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait was not released")
+	}
+
+	start := time.Now()
+	bid, err := service.WaitForWinningBid(context.Background(), "item1", leader.BidID, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, leader.BidID, bid.BidID)
+	require.Less(t, time.Since(start), time.Second, "later waits return at once")
+}
+
 // Tests GetBidsForItem
 func TestBiddingService_GetBidsForItem(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -180,7 +524,7 @@ func TestBiddingService_GetBidsForItem(t *testing.T) {
 			name:   "valid_item_with_bids",
 			itemID: "item1",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetBidsByItem("item1").Return(bidsExample, nil)
+				mockRepo.EXPECT().GetBidsByItem(gomock.Any(), "item1").Return(bidsExample, nil)
 			},
 			expectError:   false,
 			expectedError: nil,
@@ -190,7 +534,7 @@ func TestBiddingService_GetBidsForItem(t *testing.T) {
 			name:   "valid_item_no_bids",
 			itemID: "item2",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetBidsByItem("item2").Return([]model.Bid{}, nil)
+				mockRepo.EXPECT().GetBidsByItem(gomock.Any(), "item2").Return([]model.Bid{}, nil)
 			},
 			expectError:   false,
 			expectedError: nil,
@@ -207,7 +551,7 @@ func TestBiddingService_GetBidsForItem(t *testing.T) {
 			name:   "repo_error",
 			itemID: "item3",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetBidsByItem("item3").Return(nil, errors.New("db failure"))
+				mockRepo.EXPECT().GetBidsByItem(gomock.Any(), "item3").Return(nil, errors.New("db failure"))
 			},
 			expectError:   true,
 			expectedError: nil, // Service wraps repo error
@@ -221,7 +565,7 @@ func TestBiddingService_GetBidsForItem(t *testing.T) {
 
 			tc.mockSetup()
 
-			bids, err := service.GetBidsForItem(tc.itemID)
+			bids, err := service.GetBidsForItem(context.Background(), tc.itemID)
 
 			if tc.expectError {
 				require.Error(t, err)
@@ -257,7 +601,7 @@ func TestBiddingService_GetWinningBid(t *testing.T) {
 			name:   "valid_item_with_winning_bid",
 			itemID: "item1",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item1").Return(model.Bid{
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item1").Return(model.Bid{
 					BidID:     uuid.NewString(),
 					ItemID:    "item1",
 					UserID:    "user1",
@@ -277,7 +621,7 @@ func TestBiddingService_GetWinningBid(t *testing.T) {
 			name:   "repo_returns_no_bids",
 			itemID: "item2",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item2").Return(model.Bid{}, biddingerrors.ErrNoBids)
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item2").Return(model.Bid{}, biddingerrors.ErrNoBids)
 			},
 			expectError: true,
 		},
@@ -285,7 +629,7 @@ func TestBiddingService_GetWinningBid(t *testing.T) {
 			name:   "repo_returns_error",
 			itemID: "item3",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetWinningBid("item3").Return(model.Bid{}, errors.New("repo error"))
+				mockRepo.EXPECT().GetWinningBid(gomock.Any(), "item3").Return(model.Bid{}, errors.New("repo error"))
 			},
 			expectError: true,
 		},
@@ -298,7 +642,7 @@ func TestBiddingService_GetWinningBid(t *testing.T) {
 
 			tc.mockSetup()
 
-			bid, err := service.GetWinningBid(tc.itemID)
+			bid, err := service.GetWinningBid(context.Background(), tc.itemID)
 
 			if tc.expectError {
 				require.Error(t, err)
@@ -355,7 +699,7 @@ func TestBiddingService_GetItemsByUser(t *testing.T) {
 			name:   "valid_user_with_items",
 			userID: "user1",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetItemsByUser("user1").Return(itemsExample, nil)
+				mockRepo.EXPECT().GetItemsByUser(gomock.Any(), "user1").Return(itemsExample, nil)
 			},
 			expectError:   false,
 			expectedError: nil,
@@ -365,7 +709,7 @@ func TestBiddingService_GetItemsByUser(t *testing.T) {
 			name:   "valid_user_no_items",
 			userID: "user2",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetItemsByUser("user2").Return([]model.Item{}, nil)
+				mockRepo.EXPECT().GetItemsByUser(gomock.Any(), "user2").Return([]model.Item{}, nil)
 			},
 			expectError:   false,
 			expectedError: nil,
@@ -383,7 +727,7 @@ func TestBiddingService_GetItemsByUser(t *testing.T) {
 			name:   "repo_error",
 			userID: "user3",
 			mockSetup: func() {
-				mockRepo.EXPECT().GetItemsByUser("user3").Return(nil, errors.New("db failure"))
+				mockRepo.EXPECT().GetItemsByUser(gomock.Any(), "user3").Return(nil, errors.New("db failure"))
 			},
 			expectError:   true,
 			expectedError: nil, // Service wraps repo error
@@ -398,7 +742,7 @@ func TestBiddingService_GetItemsByUser(t *testing.T) {
 
 			tc.mockSetup()
 
-			items, err := service.GetItemsByUser(tc.userID)
+			items, err := service.GetItemsByUser(context.Background(), tc.userID)
 
 			if tc.expectError {
 				require.Error(t, err)
@@ -413,3 +757,127 @@ func TestBiddingService_GetItemsByUser(t *testing.T) {
 		})
 	}
 }
+
+// Tests that placed bids form a hash chain that survives each storage backend
+func TestBiddingService_VerifyChain(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		cfg  func(t *testing.T) repository.StoreConfig
+	}{
+		{name: "memory", cfg: func(t *testing.T) repository.StoreConfig { return repository.StoreConfig{} }},
+		{name: "file", cfg: func(t *testing.T) repository.StoreConfig {
+			return repository.StoreConfig{Backend: repository.BackendFile, File: repository.FileRepoConfig{Dir: t.TempDir()}}
+		}},
+		{name: "sql", cfg: func(t *testing.T) repository.StoreConfig {
+			return repository.StoreConfig{Backend: repository.BackendSQL, SQLDriver: "sqlite3", SQLDSN: "file:" + filepath.Join(t.TempDir(), "auction.db") + "?_txlock=immediate&_foreign_keys=on"}
+		}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			store, err := repository.OpenStore(context.Background(), tc.cfg(t))
+			require.NoError(t, err)
+			defer store.Close()
+			require.NoError(t, store.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 1}))
+			service := NewBiddingService(store)
+
+			res, err := service.VerifyChain(context.Background(), "item1")
+			require.NoError(t, err)
+			require.Equal(t, bidchain.Result{}, res, "an item without bids has an empty chain")
+
+			var last model.Bid
+			for i, amount := range []float64{100, 150.5, 200} {
+				bid, err := service.PlaceBid(context.Background(), "item1", fmt.Sprintf("user%d", i), amount)
+				require.NoError(t, err)
+				require.Equal(t, last.Hash, bid.PrevHash)
+				last = bid
+			}
+
+			res, err = service.VerifyChain(context.Background(), "item1")
+			require.NoError(t, err)
+			require.Equal(t, bidchain.Result{Bids: 3, Head: last.Hash}, res)
+
+			winning, err := service.GetWinningBid(context.Background(), "item1")
+			require.NoError(t, err)
+			require.Equal(t, res.Head, winning.Hash, "the winning bid is the chain head")
+		})
+	}
+}
+
+// Tests that two instances sharing a SQL database never return an internal error for a
+// lost race and keep the item's chain intact
+func TestBiddingService_PlaceBidSharedDatabase(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg := repository.StoreConfig{Backend: repository.BackendSQL, SQLDriver: "sqlite3", SQLDSN: "file:" + filepath.Join(t.TempDir(), "auction.db") + "?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on"}
+	var services []*BiddingService
+	for i := 0; i < 2; i++ {
+		store, err := repository.OpenStore(context.Background(), cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = store.Close() })
+		if i == 0 {
+			require.NoError(t, store.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 1}))
+		}
+		services = append(services, NewBiddingService(store))
+	}
+
+	// every bidder keeps raising, so the instances race for the same leader on every round
+	const bidders, rounds = 10, 20
+	errs := make(chan error, bidders*rounds)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for b := 0; b < bidders; b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			<-start
+			for r := 0; r < rounds; r++ {
+				_, err := services[b%2].PlaceBid(context.Background(), "item1", fmt.Sprintf("user%d", b), float64(100+r*bidders+b))
+				errs <- err
+			}
+		}(b)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	accepted := 0
+	for err := range errs {
+		if err == nil {
+			accepted++
+			continue
+		}
+		require.ErrorIs(t, err, biddingerrors.ErrBidTooLow)
+	}
+
+	res, err := services[0].VerifyChain(context.Background(), "item1")
+	require.NoError(t, err)
+	require.Equal(t, accepted, res.Bids)
+	require.Zero(t, res.Unchained)
+}
+
+// Tests that a tampered history is reported as a broken chain
+func TestBiddingService_VerifyChainTampered(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := repository.NewMockAuctionDB(ctrl)
+	service := NewBiddingService(mockRepo)
+
+	first := bidchain.Link("", model.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 100, CreatedAt: time.Now().UTC()})
+	second := bidchain.Link(first.Hash, model.Bid{BidID: "bid2", ItemID: "item1", UserID: "user2", Amount: 150, CreatedAt: time.Now().UTC()})
+	first.UserID = "user3" // rewritten after the fact
+	mockRepo.EXPECT().GetBidsByItem(gomock.Any(), "item1").Return([]model.Bid{first, second}, nil)
+
+	_, err := service.VerifyChain(context.Background(), "item1")
+	require.ErrorIs(t, err, bidchain.ErrBroken)
+	var broken *bidchain.BrokenError
+	require.ErrorAs(t, err, &broken)
+	require.Equal(t, "bid1", broken.BidID)
+}
@@ -0,0 +1,42 @@
+package bidding
+
+import (
+	"context"
+	"errors"
+
+	"bidding-tracker/internal/biddingerrors"
+	"bidding-tracker/internal/metrics"
+)
+
+// bidsTotal counts PlaceBid outcomes; reason is empty for accepted bids
+var bidsTotal = metrics.Default.NewCounterVec("bids_total",
+	"Bids placed, by result (accepted or rejected) and rejection reason.", "result", "reason")
+
+// observeBid counts the outcome of a PlaceBid call
+func observeBid(err error) {
+	if err == nil {
+		bidsTotal.WithLabelValues("accepted", "").Inc()
+		return
+	}
+	bidsTotal.WithLabelValues("rejected", rejectReason(err)).Inc()
+}
+
+// rejectReason maps a PlaceBid error to a low-cardinality label value
+func rejectReason(err error) string {
+	switch {
+	case errors.Is(err, biddingerrors.ErrBidTooLow):
+		return "too_low"
+	case errors.Is(err, biddingerrors.ErrBidConflict):
+		return "conflict"
+	case errors.Is(err, biddingerrors.ErrAuctionClosed):
+		return "closed"
+	case errors.Is(err, biddingerrors.ErrInvalidBid):
+		return "invalid"
+	case errors.Is(err, biddingerrors.ErrItemNotFound):
+		return "item_not_found"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
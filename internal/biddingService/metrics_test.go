@@ -0,0 +1,48 @@
+package bidding
+
+import (
+	"context"
+	"testing"
+
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/repository"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that PlaceBid counts outcomes by result and reason.
+// Not parallel: other tests place bids and move the shared counters.
+func TestBiddingService_PlaceBidMetrics(t *testing.T) {
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	service := NewBiddingService(repo)
+
+	counters := map[string]float64{}
+	read := func() map[string]float64 {
+		return map[string]float64{
+			"accepted":       bidsTotal.WithLabelValues("accepted", "").Value(),
+			"too_low":        bidsTotal.WithLabelValues("rejected", "too_low").Value(),
+			"invalid":        bidsTotal.WithLabelValues("rejected", "invalid").Value(),
+			"item_not_found": bidsTotal.WithLabelValues("rejected", "item_not_found").Value(),
+			"canceled":       bidsTotal.WithLabelValues("rejected", "canceled").Value(),
+		}
+	}
+	before := read()
+
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, _ = service.PlaceBid(ctx, "item1", "user1", 100)
+	_, _ = service.PlaceBid(ctx, "item1", "user2", 150)
+	_, _ = service.PlaceBid(ctx, "item1", "user3", 120)
+	_, _ = service.PlaceBid(ctx, "item1", "", 200)
+	_, _ = service.PlaceBid(ctx, "missing", "user1", 200)
+	_, _ = service.PlaceBid(canceled, "item1", "user1", 500)
+
+	after := read()
+	for name := range before {
+		counters[name] = after[name] - before[name]
+	}
+	require.Equal(t, map[string]float64{"accepted": 2, "too_low": 1, "invalid": 1, "item_not_found": 1, "canceled": 1}, counters)
+}
@@ -0,0 +1,79 @@
+package bidding
+
+import "sync"
+
+// leaderWaiters tracks callers blocked on an item's winning bid. Each watched item has
+// one channel that is closed when its leader changes, waking every waiter at once;
+// items nobody is watching cost nothing.
+type leaderWaiters struct {
+	mu       sync.Mutex
+	items    map[string]*itemWaiters
+	released chan struct{} // closed by releaseAll; created on first use
+}
+
+// itemWaiters is the wake-up channel shared by the waiters on one item
+type itemWaiters struct {
+	ch   chan struct{}
+	refs int
+}
+
+// watch returns a channel that is closed on the item's next leader change, and a
+// release func the caller must invoke when it stops waiting
+func (w *leaderWaiters) watch(itemID string) (<-chan struct{}, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.items == nil {
+		w.items = make(map[string]*itemWaiters)
+	}
+	iw, ok := w.items[itemID]
+	if !ok {
+		iw = &itemWaiters{ch: make(chan struct{})}
+		w.items[itemID] = iw
+	}
+	iw.refs++
+
+	return iw.ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		iw.refs--
+		if iw.refs == 0 && w.items[itemID] == iw {
+			delete(w.items, itemID)
+		}
+	}
+}
+
+// notify wakes everyone waiting on an item
+func (w *leaderWaiters) notify(itemID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if iw, ok := w.items[itemID]; ok {
+		close(iw.ch)
+		delete(w.items, itemID)
+	}
+}
+
+// releasedCh returns the channel closed once every wait is released
+func (w *leaderWaiters) releasedCh() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.released == nil {
+		w.released = make(chan struct{})
+	}
+	return w.released
+}
+
+// releaseAll ends every current and future wait; it is safe to call more than once
+func (w *leaderWaiters) releaseAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.released == nil {
+		w.released = make(chan struct{})
+	}
+	select {
+	case <-w.released:
+	default:
+		close(w.released)
+	}
+}
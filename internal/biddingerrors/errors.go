@@ -4,9 +4,18 @@ import "errors"
 
 // Repository-level errors
 var (
-	ErrItemNotFound = errors.New("item not found")
-	ErrNoBids       = errors.New("no bids found for item")
-	ErrUserNoBids   = errors.New("user has not placed any bids")
+	ErrItemNotFound   = errors.New("item not found")
+	ErrNoBids         = errors.New("no bids found for item")
+	ErrUserNoBids     = errors.New("user has not placed any bids")
+	ErrStorageClosed  = errors.New("storage is closed")
+	ErrBidConflict    = errors.New("another bid on the item was recorded first")
+	ErrAuctionClosed  = errors.New("auction is closed")
+	ErrChangesTrimmed = errors.New("changes are no longer retained")
+)
+
+// Dependency errors
+var (
+	ErrAuditUnavailable = errors.New("audit log unavailable")
 )
 
 // business logic errors
@@ -0,0 +1,553 @@
+// Package config loads the server settings. Each setting has a default and can be set
+// in a YAML or JSON file, an environment variable or a command-line flag; later sources
+// win in that order. Settings are validated once at startup.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bidding-tracker/internal/repository"
+	"bidding-tracker/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a setting's value, lowest precedence first
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+const (
+	// FileEnv names the config file in the environment
+	FileEnv = "CONFIG_FILE"
+	// FileFlag names the config file on the command line, overriding FileEnv
+	FileFlag = "config"
+)
+
+// maskedValue replaces secrets in Effective
+const maskedValue = "********"
+
+// ErrInvalid is wrapped by every error about a setting's value
+var ErrInvalid = errors.New("invalid setting")
+
+// Config holds every setting. Sections and fields are tagged with the key used in files
+// and flags (section.field); fields also name their environment variable, their flag
+// help and whether they are secret.
+type Config struct {
+	Server        Server        `key:"server"`
+	Storage       Storage       `key:"storage"`
+	Auth          Auth          `key:"auth"`
+	Auction       Auction       `key:"auction"`
+	RateLimit     RateLimit     `key:"rate_limit"`
+	Logging       Logging       `key:"logging"`
+	Notifications Notifications `key:"notifications"`
+	Events        Events        `key:"events"`
+	Outbox        Outbox        `key:"outbox"`
+	Audit         Audit         `key:"audit"`
+	Receipts      Receipts      `key:"receipts"`
+	Tracing       Tracing       `key:"tracing"`
+
+	sources map[string]string // setting key -> source of its value
+}
+
+// Server configures the HTTP listener and its shutdown
+type Server struct {
+	Port            int           `key:"port" env:"PORT" help:"HTTP listen port"`
+	ReadTimeout     time.Duration `key:"read_timeout" env:"SERVER_READ_TIMEOUT" help:"longest time to read a request, body included"`
+	WriteTimeout    time.Duration `key:"write_timeout" env:"SERVER_WRITE_TIMEOUT" help:"longest time to write a response; long polls and streams extend it"`
+	IdleTimeout     time.Duration `key:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" help:"how long an idle keep-alive connection stays open"`
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" help:"how long in-flight requests may take to finish on shutdown"`
+	TrustedProxies  []string      `key:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES" help:"comma-separated proxy IPs or CIDRs whose X-Forwarded-For is believed; empty trusts none"`
+}
+
+// Storage selects and configures the repository backend
+type Storage struct {
+	Backend          string        `key:"backend" env:"STORAGE_BACKEND" help:"storage backend: memory, file or sql"`
+	Dir              string        `key:"dir" env:"STORAGE_DIR" help:"directory of the file backend"`
+	Fsync            string        `key:"fsync" env:"STORAGE_FSYNC" help:"file backend fsync policy: always, interval or never"`
+	FsyncInterval    time.Duration `key:"fsync_interval" env:"STORAGE_FSYNC_INTERVAL" help:"WAL fsync period of the interval fsync policy"`
+	SnapshotEvery    int           `key:"snapshot_every" env:"STORAGE_SNAPSHOT_EVERY" help:"file backend snapshot after this many WAL records"`
+	SnapshotInterval time.Duration `key:"snapshot_interval" env:"STORAGE_SNAPSHOT_INTERVAL" help:"file backend snapshot period"`
+	SQLDriver        string        `key:"sql_driver" env:"SQL_DRIVER" help:"database/sql driver of the sql backend"`
+	SQLDSN           string        `key:"sql_dsn" env:"SQL_DSN" help:"data source name of the sql backend" secret:"true"`
+
+	OutboxRetention int `key:"outbox_retention" env:"STORAGE_OUTBOX_RETENTION" help:"change feed entries kept by the memory and file backends; 0 keeps all"`
+}
+
+// Auth configures JWT and API key authentication
+type Auth struct {
+	JWTHMACSecret           string        `key:"jwt_hmac_secret" env:"JWT_HMAC_SECRET" help:"HS256 secret; enables authentication" secret:"true"`
+	JWTEd25519PublicKeyFile string        `key:"jwt_ed25519_public_key_file" env:"JWT_ED25519_PUBLIC_KEY_FILE" help:"PEM Ed25519 public key for EdDSA tokens; enables authentication"`
+	JWTIssuer               string        `key:"jwt_issuer" env:"JWT_ISSUER" help:"required iss claim"`
+	JWTAudience             string        `key:"jwt_audience" env:"JWT_AUDIENCE" help:"required aud claim"`
+	JWTLeeway               time.Duration `key:"jwt_leeway" env:"JWT_LEEWAY" help:"clock skew allowed on exp and nbf"`
+	JWTMaxTTL               time.Duration `key:"jwt_max_ttl" env:"JWT_MAX_TTL" help:"longest accepted token lifetime; 0 for no limit"`
+	AdminAPIKey             string        `key:"admin_api_key" env:"ADMIN_API_KEY" help:"bootstrap admin API key; enables authentication" secret:"true"`
+	APIKeysFile             string        `key:"api_keys_file" env:"API_KEYS_FILE" help:"API key store file"`
+	APIKeysFlushInterval    time.Duration `key:"api_keys_flush_interval" env:"API_KEYS_FLUSH_INTERVAL" help:"how often API key usage is written to the key store file"`
+}
+
+// Auction holds bidding rules and startup data
+type Auction struct {
+	SeedItems    bool    `key:"seed_items" env:"AUCTION_SEED_ITEMS" help:"add the sample items at startup"`
+	MinIncrement float64 `key:"min_increment" env:"AUCTION_MIN_INCREMENT" help:"amount a bid must beat the winning bid by; 0 for any amount"`
+}
+
+// RateLimit holds the request rate limits
+type RateLimit struct {
+	Enabled        bool    `key:"enabled" env:"RATE_LIMIT_ENABLED" help:"limit request rates per user or client IP"`
+	BidsPerSecond  float64 `key:"bids_per_second" env:"RATE_LIMIT_BIDS_PER_SECOND" help:"sustained bid writes per second"`
+	BidsBurst      int     `key:"bids_burst" env:"RATE_LIMIT_BIDS_BURST" help:"bid writes allowed in a burst"`
+	ReadsPerSecond float64 `key:"reads_per_second" env:"RATE_LIMIT_READS_PER_SECOND" help:"sustained reads per second"`
+	ReadsBurst     int     `key:"reads_burst" env:"RATE_LIMIT_READS_BURST" help:"reads allowed in a burst"`
+}
+
+// Logging configures the default logger
+type Logging struct {
+	Level           string   `key:"level" env:"LOG_LEVEL" help:"debug, info, warn or error"`
+	Format          string   `key:"format" env:"LOG_FORMAT" help:"json or text"`
+	Output          string   `key:"output" env:"LOG_OUTPUT" help:"stdout, stderr or a file to append to"`
+	SuccessSampling int      `key:"success_sampling" env:"LOG_SUCCESS_SAMPLING" help:"keep one in N success lines per message"`
+	RedactFields    []string `key:"redact_fields" env:"LOG_REDACT_FIELDS" help:"comma-separated field names to mask in logs"`
+}
+
+// Notifications configures the outbid notification channels
+type Notifications struct {
+	LogFile      string `key:"log_file" env:"NOTIFY_LOG_FILE" help:"JSON-lines notification log; stdout when empty"`
+	SMTPAddr     string `key:"smtp_addr" env:"SMTP_ADDR" help:"SMTP server host:port; enables email"`
+	SMTPFrom     string `key:"smtp_from" env:"SMTP_FROM" help:"email sender address"`
+	SMTPUsername string `key:"smtp_username" env:"SMTP_USERNAME" help:"SMTP user"`
+	SMTPPassword string `key:"smtp_password" env:"SMTP_PASSWORD" help:"SMTP password" secret:"true"`
+	Workers      int    `key:"workers" env:"NOTIFY_WORKERS" help:"concurrent notification sends"`
+	MaxPending   int    `key:"max_pending" env:"NOTIFY_MAX_PENDING" help:"notifications queued, held or awaiting a retry before new ones are dropped"`
+}
+
+// Events configures the domain event bus
+type Events struct {
+	QueueSize int `key:"queue_size" env:"EVENTS_QUEUE_SIZE" help:"events queued per async subscriber (notifications, webhooks) before new ones are dropped"`
+}
+
+// Outbox configures where recorded changes are forwarded
+type Outbox struct {
+	Sink string `key:"sink" env:"OUTBOX_SINK" help:"stdout, file, or empty to disable forwarding"`
+	File string `key:"file" env:"OUTBOX_FILE" help:"JSON-lines file of the file sink"`
+}
+
+// Audit configures the audit log
+type Audit struct {
+	LogFile    string `key:"log_file" env:"AUDIT_LOG_FILE" help:"JSON-lines audit log"`
+	MaxEntries int    `key:"max_entries" env:"AUDIT_MAX_ENTRIES" help:"most recent entries kept in memory for GET /admin/audit; 0 keeps all"`
+}
+
+// Receipts configures bid receipt signing
+type Receipts struct {
+	SigningKeyFile string `key:"signing_key_file" env:"RECEIPT_SIGNING_KEY_FILE" help:"PEM PKCS#8 Ed25519 signing key; generated at startup when empty"`
+}
+
+// Tracing configures span export
+type Tracing struct {
+	Exporter string `key:"exporter" env:"TRACE_EXPORTER" help:"stdout, or empty to export nothing"`
+}
+
+// Default returns the settings used when nothing is configured
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            8080,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Storage: Storage{
+			Backend:   repository.BackendMemory,
+			Dir:       "data",
+			Fsync:     string(repository.FsyncAlways),
+			SQLDriver: "sqlite3",
+			SQLDSN:    "file:auction.db?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on",
+
+			FsyncInterval:    time.Second,
+			SnapshotEvery:    repository.DefaultSnapshotEvery,
+			SnapshotInterval: repository.DefaultSnapshotInterval,
+
+			OutboxRetention: 100000,
+		},
+		Auth: Auth{
+			JWTLeeway:            30 * time.Second,
+			APIKeysFile:          "api_keys.json",
+			APIKeysFlushInterval: 30 * time.Second,
+		},
+		Auction: Auction{SeedItems: true},
+		RateLimit: RateLimit{
+			BidsPerSecond:  5,
+			BidsBurst:      10,
+			ReadsPerSecond: 50,
+			ReadsBurst:     100,
+		},
+		Logging: Logging{
+			Level:           "info",
+			Format:          utils.FormatJSON,
+			Output:          "stdout",
+			SuccessSampling: 1,
+		},
+		Notifications: Notifications{SMTPFrom: "auctions@localhost", Workers: 4, MaxPending: 10000},
+		Events:        Events{QueueSize: 10000},
+		Outbox:        Outbox{File: "changes.jsonl"},
+		Audit:         Audit{LogFile: "audit.jsonl", MaxEntries: 100000},
+	}
+}
+
+// Load builds the configuration from the defaults, the config file named by the -config
+// flag or CONFIG_FILE, the environment read through getenv and the command-line args
+// (without the program name), then validates it. Empty environment variables count as
+// unset. With -h or -help it prints the flags and returns flag.ErrHelp.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	flags := make(map[string]string)
+	fs := flag.NewFlagSet("bidding-tracker", flag.ContinueOnError)
+	configFile := fs.String(FileFlag, "", "YAML or JSON config file (env "+FileEnv+")")
+	for _, s := range settings {
+		key := s.key
+		fs.Func(key, fmt.Sprintf("%s (env %s, default %q)", s.help, s.env, s.format()), func(v string) error {
+			flags[key] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	path := *configFile
+	if path == "" {
+		path = getenv(FileEnv)
+	}
+	var file map[string]string
+	if path != "" {
+		var err error
+		if file, err = readFile(path, settings); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	for _, s := range settings {
+		cfg.sources[s.key] = SourceDefault
+		if v, ok := file[s.key]; ok {
+			if err := s.set(v); err != nil {
+				return nil, fmt.Errorf("config file %s: %w", path, err)
+			}
+			cfg.sources[s.key] = SourceFile
+		}
+		if v := getenv(s.env); v != "" {
+			if err := s.set(v); err != nil {
+				return nil, fmt.Errorf("env %s: %w", s.env, err)
+			}
+			cfg.sources[s.key] = SourceEnv
+		}
+		if v, ok := flags[s.key]; ok {
+			if err := s.set(v); err != nil {
+				return nil, fmt.Errorf("flag -%s: %w", s.key, err)
+			}
+			cfg.sources[s.key] = SourceFlag
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s %s", ErrInvalid, key, fmt.Sprintf(format, args...)))
+		}
+	}
+	oneOf := func(key, v string, allowed ...string) {
+		for _, a := range allowed {
+			if v == a {
+				return
+			}
+		}
+		check(false, key, "must be one of %q, got %q", allowed, v)
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout", "must be positive, got %s", c.Server.ReadTimeout)
+	check(c.Server.WriteTimeout > 0, "server.write_timeout", "must be positive, got %s", c.Server.WriteTimeout)
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout", "must be positive, got %s", c.Server.IdleTimeout)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive, got %s", c.Server.ShutdownTimeout)
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(net.ParseIP(proxy) != nil || cidrErr == nil, "server.trusted_proxies", "must be IPs or CIDRs, got %q", proxy)
+	}
+
+	oneOf("storage.backend", c.Storage.Backend, repository.BackendMemory, repository.BackendFile, repository.BackendSQL)
+	oneOf("storage.fsync", c.Storage.Fsync, string(repository.FsyncAlways), string(repository.FsyncInterval), string(repository.FsyncNever))
+	if c.Storage.Backend == repository.BackendFile {
+		check(c.Storage.Dir != "", "storage.dir", "is required by the file backend")
+		check(c.Storage.FsyncInterval > 0, "storage.fsync_interval", "must be positive, got %s", c.Storage.FsyncInterval)
+		check(c.Storage.SnapshotEvery >= 1, "storage.snapshot_every", "must be at least 1, got %d", c.Storage.SnapshotEvery)
+		check(c.Storage.SnapshotInterval > 0, "storage.snapshot_interval", "must be positive, got %s", c.Storage.SnapshotInterval)
+	}
+	check(c.Storage.OutboxRetention >= 0, "storage.outbox_retention", "must not be negative, got %d", c.Storage.OutboxRetention)
+	if c.Storage.Backend == repository.BackendSQL {
+		check(c.Storage.SQLDriver != "", "storage.sql_driver", "is required by the sql backend")
+		check(c.Storage.SQLDSN != "", "storage.sql_dsn", "is required by the sql backend")
+	}
+
+	check(c.Auth.JWTLeeway >= 0, "auth.jwt_leeway", "must not be negative")
+	check(c.Auth.JWTMaxTTL >= 0, "auth.jwt_max_ttl", "must not be negative")
+	check(c.Auth.APIKeysFile != "", "auth.api_keys_file", "is required")
+	check(c.Auth.APIKeysFlushInterval > 0, "auth.api_keys_flush_interval", "must be positive, got %s", c.Auth.APIKeysFlushInterval)
+
+	check(c.Auction.MinIncrement >= 0, "auction.min_increment", "must not be negative, got %g", c.Auction.MinIncrement)
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.BidsPerSecond > 0, "rate_limit.bids_per_second", "must be positive, got %g", c.RateLimit.BidsPerSecond)
+		check(c.RateLimit.BidsBurst >= 1, "rate_limit.bids_burst", "must be at least 1, got %d", c.RateLimit.BidsBurst)
+		check(c.RateLimit.ReadsPerSecond > 0, "rate_limit.reads_per_second", "must be positive, got %g", c.RateLimit.ReadsPerSecond)
+		check(c.RateLimit.ReadsBurst >= 1, "rate_limit.reads_burst", "must be at least 1, got %d", c.RateLimit.ReadsBurst)
+	}
+
+	if _, err := utils.NewLogger(utils.LogConfig{Level: c.Logging.Level, Output: io.Discard}); err != nil {
+		check(false, "logging.level", "must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	oneOf("logging.format", c.Logging.Format, utils.FormatJSON, utils.FormatText)
+	check(c.Logging.Output != "", "logging.output", "is required")
+	check(c.Logging.SuccessSampling >= 1, "logging.success_sampling", "must be at least 1, got %d", c.Logging.SuccessSampling)
+
+	check(c.Notifications.Workers >= 1, "notifications.workers", "must be at least 1, got %d", c.Notifications.Workers)
+	check(c.Notifications.MaxPending >= 1, "notifications.max_pending", "must be at least 1, got %d", c.Notifications.MaxPending)
+	check(c.Events.QueueSize >= 1, "events.queue_size", "must be at least 1, got %d", c.Events.QueueSize)
+
+	oneOf("outbox.sink", c.Outbox.Sink, "", "stdout", "file")
+	if c.Outbox.Sink == "file" {
+		check(c.Outbox.File != "", "outbox.file", "is required by the file sink")
+	}
+	check(c.Audit.LogFile != "", "audit.log_file", "is required")
+	check(c.Audit.MaxEntries >= 0, "audit.max_entries", "must not be negative, got %d", c.Audit.MaxEntries)
+	oneOf("tracing.exporter", c.Tracing.Exporter, "", "stdout")
+
+	return errors.Join(errs...)
+}
+
+// Setting is one effective setting, as shown by GET /debug/config
+type Setting struct {
+	Key    string `json:"key"`
+	Env    string `json:"env"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Effective lists every setting sorted by key, with its value and where it came from.
+// Secret values are masked.
+func (c *Config) Effective() []Setting {
+	settings := c.settings()
+	out := make([]Setting, len(settings))
+	for i, s := range settings {
+		value := s.format()
+		if s.secret && value != "" {
+			value = maskedValue
+		}
+		source := c.sources[s.key]
+		if source == "" {
+			source = SourceDefault
+		}
+		out[i] = Setting{Key: s.key, Env: s.env, Value: value, Source: source}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// setting is one field of the configuration with its tags
+type setting struct {
+	key, env, help string
+	secret         bool
+	value          reflect.Value // the addressable field
+}
+
+// settings walks the tagged sections of c
+func (c *Config) settings() []setting {
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+
+	var out []setting
+	cv := reflect.ValueOf(c).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		section := cv.Type().Field(i).Tag.Get("key")
+		if section == "" {
+			continue
+		}
+		sv := cv.Field(i)
+		for j := 0; j < sv.NumField(); j++ {
+			f := sv.Type().Field(j)
+			out = append(out, setting{
+				key:    section + "." + f.Tag.Get("key"),
+				env:    f.Tag.Get("env"),
+				help:   f.Tag.Get("help"),
+				secret: f.Tag.Get("secret") == "true",
+				value:  sv.Field(j),
+			})
+		}
+	}
+	return out
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set parses raw into the setting's field
+func (s setting) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	v := s.value
+	var err error
+	switch {
+	case v.Type() == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(raw); err == nil {
+			v.SetInt(int64(d))
+		}
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(raw); err == nil {
+			v.SetBool(b)
+		}
+	case v.Kind() == reflect.Int:
+		var n int
+		if n, err = strconv.Atoi(raw); err == nil {
+			v.SetInt(int64(n))
+		}
+	case v.Kind() == reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(raw, 64); err == nil {
+			v.SetFloat(f)
+		}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("config: %s has unsupported type %s", s.key, v.Type()))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s cannot be %q", ErrInvalid, s.key, raw)
+	}
+	return nil
+}
+
+// format renders the setting's value the way set reads it
+func (s setting) format() string {
+	v := s.value
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// readFile reads a YAML (.yaml, .yml) or JSON (.json) file of sections into raw values
+// keyed like the settings. Unknown sections and keys are errors, so typos are caught.
+func readFile(path string, settings []setting) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".json":
+		err = json.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("unsupported extension %q: use .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.key] = true
+	}
+
+	values := make(map[string]string)
+	for section, body := range doc {
+		fields, ok := body.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a section of settings", ErrInvalid, section)
+		}
+		for name, v := range fields {
+			key := section + "." + name
+			if !known[key] {
+				return nil, fmt.Errorf("%w: unknown setting %s", ErrInvalid, key)
+			}
+			raw, err := scalar(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s %v", ErrInvalid, key, err)
+			}
+			values[key] = raw
+		}
+	}
+	return values, nil
+}
+
+// scalar renders a decoded file value as the text set reads; lists become comma-separated
+func scalar(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int, bool:
+		return fmt.Sprint(v), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("has unsupported value %v", v)
+	}
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// env returns a getenv reading from m
+func env(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+// writeFile writes a config file into a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// Test that defaults load and validate
+func TestLoad_Defaults(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg, err := Load(nil, env(nil))
+	require.NoError(t, err)
+	want := Default()
+	cfg.sources, want.sources = nil, nil
+	require.Equal(t, want, cfg)
+}
+
+// Test that flags beat the environment, which beats the file, which beats the defaults
+func TestLoad_Precedence(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+storage:
+  backend: file
+  dir: /var/lib/auction
+auth:
+  jwt_leeway: 1m
+auction:
+  min_increment: 2.5
+logging:
+  level: debug
+  redact_fields: [email, phone]
+`)
+
+	cfg, err := Load([]string{"-config", path, "-logging.level=warn"}, env(map[string]string{
+		"PORT":      "9100",
+		"LOG_LEVEL": "error",
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, 9100, cfg.Server.Port)
+	require.Equal(t, "file", cfg.Storage.Backend)
+	require.Equal(t, "/var/lib/auction", cfg.Storage.Dir)
+	require.Equal(t, time.Minute, cfg.Auth.JWTLeeway)
+	require.Equal(t, 2.5, cfg.Auction.MinIncrement)
+	require.Equal(t, "warn", cfg.Logging.Level)
+	require.Equal(t, []string{"email", "phone"}, cfg.Logging.RedactFields)
+	require.True(t, cfg.Auction.SeedItems)
+
+	sources := make(map[string]string)
+	for _, s := range cfg.Effective() {
+		sources[s.Key] = s.Source
+	}
+	require.Equal(t, SourceEnv, sources["server.port"])
+	require.Equal(t, SourceFile, sources["storage.dir"])
+	require.Equal(t, SourceFlag, sources["logging.level"])
+	require.Equal(t, SourceDefault, sources["auction.seed_items"])
+	require.Equal(t, SourceDefault, sources["storage.snapshot_every"])
+	require.Equal(t, SourceDefault, sources["storage.snapshot_interval"])
+	require.Equal(t, SourceDefault, sources["storage.fsync_interval"])
+}
+
+// Test that JSON files are read and CONFIG_FILE names the file
+func TestLoad_JSONFile(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	path := writeFile(t, "config.json", `{
+		"rate_limit": {"enabled": true, "bids_per_second": 0.5, "bids_burst": 3},
+		"auction": {"seed_items": false},
+		"logging": {"redact_fields": ["email"]}
+	}`)
+
+	cfg, err := Load(nil, env(map[string]string{FileEnv: path}))
+	require.NoError(t, err)
+	require.True(t, cfg.RateLimit.Enabled)
+	require.Equal(t, 0.5, cfg.RateLimit.BidsPerSecond)
+	require.Equal(t, 3, cfg.RateLimit.BidsBurst)
+	require.False(t, cfg.Auction.SeedItems)
+	require.Equal(t, []string{"email"}, cfg.Logging.RedactFields)
+}
+
+// Test that bad input is rejected with an error naming the setting and its source
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name    string
+		file    string // name of a config file holding content, passed with -config
+		content string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "bad_int_env", env: map[string]string{"PORT": "http"}, wantErr: `env PORT: invalid setting: server.port cannot be "http"`},
+		{name: "bad_duration_flag", args: []string{"-auth.jwt_leeway=soon"}, wantErr: `flag -auth.jwt_leeway: invalid setting: auth.jwt_leeway cannot be "soon"`},
+		{name: "unknown_flag", args: []string{"-storage.engine=x"}, wantErr: "flag provided but not defined: -storage.engine"},
+		{name: "positional_args", args: []string{"serve"}, wantErr: "unexpected arguments: serve"},
+		{name: "unknown_file_key", file: "c.yaml", content: "storage:\n  engine: sql\n", wantErr: "unknown setting storage.engine"},
+		{name: "file_not_a_section", file: "c.yaml", content: "port: 80\n", wantErr: "port must be a section of settings"},
+		{name: "bad_file_value", file: "c.json", content: `{"auction": {"min_increment": "lots"}}`, wantErr: `auction.min_increment cannot be "lots"`},
+		{name: "bad_extension", file: "c.toml", content: "", wantErr: `unsupported extension ".toml"`},
+		{name: "malformed_json", file: "c.json", content: "{", wantErr: "unexpected end of JSON input"},
+		{name: "missing_file", args: []string{"-config", "/no/such/config.yaml"}, wantErr: "no such file or directory"},
+		{name: "port_range", env: map[string]string{"PORT": "70000"}, wantErr: "server.port must be between 1 and 65535, got 70000"},
+		{name: "zero_shutdown_timeout", env: map[string]string{"SERVER_SHUTDOWN_TIMEOUT": "0s"}, wantErr: "server.shutdown_timeout must be positive, got 0s"},
+		{name: "unknown_backend", env: map[string]string{"STORAGE_BACKEND": "mongo"}, wantErr: `storage.backend must be one of ["memory" "file" "sql"], got "mongo"`},
+		{name: "negative_increment", env: map[string]string{"AUCTION_MIN_INCREMENT": "-1"}, wantErr: "auction.min_increment must not be negative"},
+		{name: "log_level", env: map[string]string{"LOG_LEVEL": "loud"}, wantErr: `logging.level must be debug, info, warn or error, got "loud"`},
+		{name: "rate_limit_when_enabled", env: map[string]string{"RATE_LIMIT_ENABLED": "true", "RATE_LIMIT_BIDS_BURST": "0"}, wantErr: "rate_limit.bids_burst must be at least 1, got 0"},
+		{name: "notification_workers", env: map[string]string{"NOTIFY_WORKERS": "0"}, wantErr: "notifications.workers must be at least 1, got 0"},
+		{name: "trusted_proxies", env: map[string]string{"SERVER_TRUSTED_PROXIES": "10.0.0.0/8,proxy.local"}, wantErr: `server.trusted_proxies must be IPs or CIDRs, got "proxy.local"`},
+		{name: "snapshot_every", env: map[string]string{"STORAGE_BACKEND": "file", "STORAGE_SNAPSHOT_EVERY": "0"}, wantErr: "storage.snapshot_every must be at least 1, got 0"},
+		{name: "snapshot_interval", env: map[string]string{"STORAGE_BACKEND": "file", "STORAGE_SNAPSHOT_INTERVAL": "0s"}, wantErr: "storage.snapshot_interval must be positive, got 0s"},
+		{name: "fsync_interval", env: map[string]string{"STORAGE_BACKEND": "file", "STORAGE_FSYNC_INTERVAL": "-1s"}, wantErr: "storage.fsync_interval must be positive, got -1s"},
+		{name: "events_queue_size", env: map[string]string{"EVENTS_QUEUE_SIZE": "0"}, wantErr: "events.queue_size must be at least 1, got 0"},
+		{name: "api_keys_flush_interval", env: map[string]string{"API_KEYS_FLUSH_INTERVAL": "0s"}, wantErr: "auth.api_keys_flush_interval must be positive, got 0s"},
+		{name: "audit_max_entries", env: map[string]string{"AUDIT_MAX_ENTRIES": "-1"}, wantErr: "audit.max_entries must not be negative, got -1"},
+		{name: "outbox_sink", env: map[string]string{"OUTBOX_SINK": "kafka"}, wantErr: `outbox.sink must be one of ["" "stdout" "file"], got "kafka"`},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			args := tc.args
+			if tc.file != "" {
+				args = append([]string{"-config", writeFile(t, tc.file, tc.content)}, args...)
+			}
+			_, err := Load(args, env(tc.env))
+			require.Error(t, err)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+// Test that every validation problem is reported at once
+func TestValidate_AllErrors(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg := Default()
+	cfg.Server.Port = 0
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Logging.SuccessSampling = 0
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, ErrInvalid)
+	require.ErrorContains(t, err, "server.port")
+	require.ErrorContains(t, err, "tracing.exporter")
+	require.ErrorContains(t, err, "logging.success_sampling")
+}
+
+// Test that -help prints the flags and reports flag.ErrHelp
+func TestLoad_Help(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	_, err := Load([]string{"-help"}, env(nil))
+	require.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+// Test that secrets are masked in the effective configuration
+func TestEffective_MasksSecrets(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg, err := Load([]string{"-notifications.smtp_password=hunter2"}, env(map[string]string{"ADMIN_API_KEY": "admin-key"}))
+	require.NoError(t, err)
+
+	for _, s := range cfg.Effective() {
+		require.NotContains(t, s.Value, "hunter2", s.Key)
+		require.NotContains(t, s.Value, "admin-key", s.Key)
+		switch s.Key {
+		case "notifications.smtp_password", "auth.admin_api_key", "storage.sql_dsn":
+			require.Equal(t, maskedValue, s.Value, s.Key)
+		}
+	}
+	require.Equal(t, "hunter2", cfg.Notifications.SMTPPassword)
+}
@@ -0,0 +1,245 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"bidding-tracker/utils"
+)
+
+// Subscriber receives domain events from the bus
+type Subscriber interface {
+	HandleEvent(ctx context.Context, ev Event) error
+}
+
+// SubscriberFunc adapts a function to the Subscriber interface
+type SubscriberFunc func(ctx context.Context, ev Event) error
+
+// HandleEvent calls f(ctx, ev)
+func (f SubscriberFunc) HandleEvent(ctx context.Context, ev Event) error {
+	return f(ctx, ev)
+}
+
+// Publisher is the side of the bus the service layer depends on
+type Publisher interface {
+	Publish(ctx context.Context, evs ...Event)
+}
+
+// DeliveryMode selects how a subscriber receives events
+type DeliveryMode int
+
+const (
+	// Sync delivers inside Publish, before it returns. Sync subscribers must be fast:
+	// the service publishes while holding the item's lock.
+	Sync DeliveryMode = iota
+	// Async queues events and delivers them from the subscriber's own goroutine.
+	// A slow subscriber never blocks the publisher: once its queue is full, new
+	// events are dropped for it and counted in events_dropped_total.
+	Async
+)
+
+// Bus fans domain events out to subscribers. Each subscriber sees events in
+// publish order, so events for one item arrive in the order the service
+// published them. A subscriber that panics or fails is logged and isolated;
+// other subscribers and later events are unaffected.
+type Bus struct {
+	mu        sync.RWMutex
+	subs      []*subscription
+	queueSize int
+	closed    bool
+}
+
+// BusConfig controls the queues of async subscribers
+type BusConfig struct {
+	QueueSize int // events queued per async subscriber before new ones are dropped
+}
+
+const defaultQueueSize = 10000
+
+// NewBus creates an empty event bus, applying defaults for unset config values
+func NewBus(cfg BusConfig) *Bus {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	return &Bus{queueSize: cfg.QueueSize}
+}
+
+// subscription is one registered subscriber and, in async mode, its queue
+type subscription struct {
+	name string
+	sub  Subscriber
+	mode DeliveryMode
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []queued
+	capacity int // longest queue before events are dropped
+	stopped  bool
+	done     chan struct{}
+}
+
+// queued is an event waiting for async delivery
+type queued struct {
+	ctx context.Context
+	ev  Event
+	at  time.Time // when it was published
+}
+
+// Subscribe registers a subscriber under a name used in logs.
+// The returned function unsubscribes it; an async subscriber first drains its queue.
+func (b *Bus) Subscribe(name string, sub Subscriber, mode DeliveryMode) (unsubscribe func()) {
+	s := &subscription{name: name, sub: sub, mode: mode, capacity: b.queueSize, done: make(chan struct{})}
+	s.cond = sync.NewCond(&s.mu)
+	if mode == Async {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, s)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			for i, existing := range b.subs {
+				if existing == s {
+					b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+					break
+				}
+			}
+			b.mu.Unlock()
+			s.stop()
+		})
+	}
+}
+
+// Publish delivers events to every subscriber in registration order.
+// Async deliveries use a context detached from ctx's cancellation, since they
+// usually outlive the request that caused them.
+func (b *Bus) Publish(ctx context.Context, evs ...Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+
+	for _, s := range b.subs {
+		for _, ev := range evs {
+			if s.mode == Sync {
+				s.deliver(ctx, ev)
+			} else {
+				s.enqueue(context.WithoutCancel(ctx), ev)
+			}
+		}
+	}
+}
+
+// Lag returns the number of events waiting in each async subscriber's queue, by name
+func (b *Bus) Lag() map[string]int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	lag := make(map[string]int)
+	for _, s := range b.subs {
+		if s.mode != Async {
+			continue
+		}
+		s.mu.Lock()
+		lag[s.name] += len(s.queue)
+		s.mu.Unlock()
+	}
+	return lag
+}
+
+// Close stops accepting events and waits for async subscribers to drain their queues
+func (b *Bus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.stop()
+	}
+}
+
+// deliver hands one event to the subscriber, containing panics and logging failures
+func (s *subscription) deliver(ctx context.Context, ev Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.ErrorContext(ctx, "EventBus: subscriber panicked", map[string]any{
+				"subscriber": s.name,
+				"event":      ev.EventName(),
+				"item_id":    ev.ItemID(),
+				"panic":      fmt.Sprint(r),
+			})
+		}
+	}()
+
+	if err := s.sub.HandleEvent(ctx, ev); err != nil {
+		utils.WarnContext(ctx, "EventBus: subscriber failed", map[string]any{
+			"subscriber": s.name,
+			"event":      ev.EventName(),
+			"item_id":    ev.ItemID(),
+			"error":      err.Error(),
+		})
+	}
+}
+
+// enqueue appends an event to an async subscriber's queue, or drops it when the queue is full
+func (s *subscription) enqueue(ctx context.Context, ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	if len(s.queue) >= s.capacity {
+		dropped.WithLabelValues(s.name).Inc()
+		utils.WarnContext(ctx, "EventBus: subscriber queue full, event dropped", map[string]any{
+			"subscriber": s.name,
+			"event":      ev.EventName(),
+			"item_id":    ev.ItemID(),
+		})
+		return
+	}
+	s.queue = append(s.queue, queued{ctx: ctx, ev: ev, at: time.Now()})
+	s.cond.Signal()
+}
+
+// run delivers queued events in order until the subscription is stopped and drained
+func (s *subscription) run() {
+	defer close(s.done)
+	delay := deliveryDelay.WithLabelValues(s.name)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.stopped {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		next := s.queue[0]
+		s.queue[0] = queued{}
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		delay.Observe(time.Since(next.at).Seconds())
+		s.deliver(next.ctx, next.ev)
+	}
+}
+
+// stop refuses further events and waits for queued ones to be delivered
+func (s *subscription) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.cond.Signal()
+	s.mu.Unlock()
+	<-s.done
+}
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a subscriber that records the bid amounts it receives
+type recorder struct {
+	mu      sync.Mutex
+	amounts []float64
+}
+
+func (r *recorder) HandleEvent(_ context.Context, ev Event) error {
+	if e, ok := ev.(BidPlaced); ok {
+		r.mu.Lock()
+		r.amounts = append(r.amounts, e.Bid.Amount)
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *recorder) got() []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]float64(nil), r.amounts...)
+}
+
+// bidPlaced builds a BidPlaced event for an item
+func bidPlaced(itemID string, amount float64) Event {
+	return BidPlaced{Bid: models.Bid{ItemID: itemID, Amount: amount, CreatedAt: time.Now()}}
+}
+
+func TestBus_DeliveryOrder(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		mode DeliveryMode
+	}{
+		{name: "sync", mode: Sync},
+		{name: "async", mode: Async},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			bus := NewBus(BusConfig{})
+			rec := &recorder{}
+			bus.Subscribe("recorder", rec, tc.mode)
+
+			var want []float64
+			for i := 1; i <= 100; i++ {
+				bus.Publish(context.Background(), bidPlaced("item1", float64(i)))
+				want = append(want, float64(i))
+			}
+			bus.Close()
+
+			require.Equal(t, want, rec.got())
+		})
+	}
+}
+
+func TestBus_PanicIsolation(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		mode DeliveryMode
+	}{
+		{name: "sync_panic", mode: Sync},
+		{name: "async_panic", mode: Async},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			bus := NewBus(BusConfig{})
+			bus.Subscribe("panicky", SubscriberFunc(func(_ context.Context, ev Event) error {
+				if ev.(BidPlaced).Bid.Amount == 1 {
+					panic("boom")
+				}
+				return nil
+			}), tc.mode)
+			bus.Subscribe("failing", SubscriberFunc(func(context.Context, Event) error {
+				return errors.New("always fails")
+			}), tc.mode)
+			after := &recorder{}
+			bus.Subscribe("after", after, tc.mode)
+
+			require.NotPanics(t, func() {
+				bus.Publish(context.Background(), bidPlaced("item1", 1), bidPlaced("item1", 2))
+			})
+			bus.Close()
+
+			// later subscribers and later events still get through
+			require.Equal(t, []float64{1, 2}, after.got())
+		})
+	}
+}
+
+func TestBus_AsyncDoesNotBlockPublisher(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	release := make(chan struct{})
+	bus := NewBus(BusConfig{})
+	rec := &recorder{}
+	bus.Subscribe("slow", SubscriberFunc(func(ctx context.Context, ev Event) error {
+		<-release
+		return rec.HandleEvent(ctx, ev)
+	}), Async)
+
+	// the canceled context must not stop async delivery
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 1; i <= 10; i++ {
+		bus.Publish(ctx, bidPlaced("item1", float64(i)))
+	}
+	require.GreaterOrEqual(t, bus.Lag()["slow"], 9)
+
+	close(release)
+	bus.Close()
+	require.Len(t, rec.got(), 10)
+	require.Empty(t, bus.Lag())
+}
+
+// Test that a full async queue drops new events and counts them instead of growing
+func TestBus_QueueFullDropsEvents(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	bus := NewBus(BusConfig{QueueSize: 3})
+	rec := &recorder{}
+	bus.Subscribe("queue-full-test", SubscriberFunc(func(ctx context.Context, ev Event) error {
+		once.Do(func() { close(started) })
+		<-release
+		return rec.HandleEvent(ctx, ev)
+	}), Async)
+
+	bus.Publish(context.Background(), bidPlaced("item1", 1))
+	<-started // the first event is being delivered, so it no longer takes queue space
+	for i := 2; i <= 6; i++ {
+		bus.Publish(context.Background(), bidPlaced("item1", float64(i)))
+	}
+	require.Equal(t, 3, bus.Lag()["queue-full-test"])
+	require.Equal(t, float64(2), dropped.WithLabelValues("queue-full-test").Value())
+
+	close(release)
+	bus.Close()
+	require.Equal(t, []float64{1, 2, 3, 4}, rec.got())
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	bus := NewBus(BusConfig{})
+	defer bus.Close()
+	rec := &recorder{}
+	unsubscribe := bus.Subscribe("recorder", rec, Async)
+
+	bus.Publish(context.Background(), bidPlaced("item1", 1))
+	unsubscribe()
+	unsubscribe() // second call is a no-op
+	bus.Publish(context.Background(), bidPlaced("item1", 2))
+
+	require.Equal(t, []float64{1}, rec.got())
+}
+
+// Test that async deliveries record their queueing delay per subscriber
+func TestBus_DeliveryDelayMetric(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	bus := NewBus(BusConfig{})
+	bus.Subscribe("delay-metric-test", &recorder{}, Async)
+	for i := 1; i <= 3; i++ {
+		bus.Publish(context.Background(), bidPlaced("item1", float64(i)))
+	}
+	bus.Close()
+
+	require.Equal(t, uint64(3), deliveryDelay.WithLabelValues("delay-metric-test").Count())
+}
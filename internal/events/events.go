@@ -0,0 +1,53 @@
+package events
+
+import (
+	"bidding-tracker/internal/models"
+	"time"
+)
+
+// Event names, stable across releases so subscribers can persist or forward them
+const (
+	NameBidPlaced     = "bid_placed"
+	NameLeaderChanged = "leader_changed"
+	NameAuctionClosed = "auction_closed"
+)
+
+// Event is a domain event published by the service layer
+type Event interface {
+	// EventName identifies the event type
+	EventName() string
+	// ItemID is the item the event belongs to; delivery is ordered per item
+	ItemID() string
+	// OccurredAt is when the change happened
+	OccurredAt() time.Time
+}
+
+// BidPlaced is published for every accepted bid
+type BidPlaced struct {
+	Bid models.Bid
+}
+
+func (e BidPlaced) EventName() string     { return NameBidPlaced }
+func (e BidPlaced) ItemID() string        { return e.Bid.ItemID }
+func (e BidPlaced) OccurredAt() time.Time { return e.Bid.CreatedAt }
+
+// LeaderChanged is published when a different user takes the lead on an item
+type LeaderChanged struct {
+	Leader   models.Bid
+	Previous *models.Bid // nil for the item's first bid
+}
+
+func (e LeaderChanged) EventName() string     { return NameLeaderChanged }
+func (e LeaderChanged) ItemID() string        { return e.Leader.ItemID }
+func (e LeaderChanged) OccurredAt() time.Time { return e.Leader.CreatedAt }
+
+// AuctionClosed is published when bidding on an item ends
+type AuctionClosed struct {
+	Item     string
+	Winner   *models.Bid // nil when the auction closed without bids
+	ClosedAt time.Time
+}
+
+func (e AuctionClosed) EventName() string     { return NameAuctionClosed }
+func (e AuctionClosed) ItemID() string        { return e.Item }
+func (e AuctionClosed) OccurredAt() time.Time { return e.ClosedAt }
@@ -0,0 +1,11 @@
+package events
+
+import "bidding-tracker/internal/metrics"
+
+// deliveryDelay measures how long async events wait in a subscriber's queue
+var deliveryDelay = metrics.Default.NewHistogramVec("events_delivery_delay_seconds",
+	"Time from publish to delivery for async subscribers, by subscriber.", metrics.DefBuckets, "subscriber")
+
+// dropped counts events an async subscriber missed because its queue was full
+var dropped = metrics.Default.NewCounterVec("events_dropped_total",
+	"Events dropped because an async subscriber's queue was full, by subscriber.", "subscriber")
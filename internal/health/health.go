@@ -0,0 +1,159 @@
+// Package health runs the readiness checks behind /readyz
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// defaultTimeout bounds each check so one hung dependency cannot stall a probe
+const defaultTimeout = 2 * time.Second
+
+// Check reports whether a dependency is ready; a nil error means ready
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the outcome of every registered check. Status is ok only when all checks pass.
+type Report struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Ready reports whether every check passed
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker holds the readiness checks. Checks can be registered at any time.
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewChecker creates a checker that gives each check up to timeout (default 2s)
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a named check; results are reported in registration order
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run executes every check concurrently and waits for all of them
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, CheckedAt: time.Now().UTC(), Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx, nc)
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range report.Checks {
+		if r.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	return report
+}
+
+// run executes one check under the checker's timeout, converting a panic into a failure
+func (c *Checker) run(ctx context.Context, nc namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	res := Result{Name: nc.name, Status: StatusOK}
+
+	// a check that ignores its context is abandoned at the deadline
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- nc.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		res.Status, res.Error = StatusFailing, err.Error()
+	}
+	res.Duration = time.Since(start).String()
+	return res
+}
+
+// Flag is a check that fails with a reason until marked ready, e.g. while the
+// server is starting up or draining for shutdown
+type Flag struct {
+	reason atomic.Pointer[string] // nil when ready
+}
+
+// NewFlag creates a flag that is not ready for the given reason
+func NewFlag(reason string) *Flag {
+	f := &Flag{}
+	f.SetNotReady(reason)
+	return f
+}
+
+// SetReady marks the flag ready
+func (f *Flag) SetReady() {
+	f.reason.Store(nil)
+}
+
+// SetNotReady makes the check fail with reason
+func (f *Flag) SetNotReady(reason string) {
+	f.reason.Store(&reason)
+}
+
+// Check implements Check
+func (f *Flag) Check(context.Context) error {
+	if reason := f.reason.Load(); reason != nil {
+		return errors.New(*reason)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the report aggregates check results in registration order
+func TestChecker_Run(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("database unreachable") }
+	hang := func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
+	stuck := func(context.Context) error { time.Sleep(time.Second); return nil }
+	boom := func(context.Context) error { panic("boom") }
+
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		order      []string
+		wantStatus string
+		wantErrors map[string]string
+	}{
+		{name: "no_checks", wantStatus: StatusOK},
+		{name: "all_pass", checks: map[string]Check{"a": ok, "b": ok}, order: []string{"a", "b"}, wantStatus: StatusOK},
+		{name: "one_fails", checks: map[string]Check{"storage": down, "startup": ok}, order: []string{"storage", "startup"}, wantStatus: StatusFailing, wantErrors: map[string]string{"storage": "database unreachable"}},
+		{name: "times_out", checks: map[string]Check{"slow": hang}, order: []string{"slow"}, wantStatus: StatusFailing, wantErrors: map[string]string{"slow": context.DeadlineExceeded.Error()}},
+		{name: "ignores_context", checks: map[string]Check{"stuck": stuck}, order: []string{"stuck"}, wantStatus: StatusFailing, wantErrors: map[string]string{"stuck": context.DeadlineExceeded.Error()}},
+		{name: "panics", checks: map[string]Check{"bad": boom}, order: []string{"bad"}, wantStatus: StatusFailing, wantErrors: map[string]string{"bad": "check panicked: boom"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			checker := NewChecker(50 * time.Millisecond)
+			for _, name := range tc.order {
+				checker.Register(name, tc.checks[name])
+			}
+
+			report := checker.Run(context.Background())
+			require.Equal(t, tc.wantStatus, report.Status)
+			require.Equal(t, tc.wantStatus == StatusOK, report.Ready())
+			require.Len(t, report.Checks, len(tc.order))
+			for i, r := range report.Checks {
+				require.Equal(t, tc.order[i], r.Name)
+				require.NotEmpty(t, r.Duration)
+				if want, failing := tc.wantErrors[r.Name]; failing {
+					require.Equal(t, StatusFailing, r.Status)
+					require.Equal(t, want, r.Error)
+				} else {
+					require.Equal(t, StatusOK, r.Status)
+					require.Empty(t, r.Error)
+				}
+			}
+		})
+	}
+}
+
+// Test Flag transitions
+func TestFlag(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	flag := NewFlag("starting up")
+	require.EqualError(t, flag.Check(context.Background()), "starting up")
+
+	flag.SetReady()
+	require.NoError(t, flag.Check(context.Background()))
+
+	flag.SetNotReady("draining")
+	require.EqualError(t, flag.Check(context.Background()), "draining")
+}
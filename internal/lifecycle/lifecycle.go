@@ -0,0 +1,131 @@
+// Package lifecycle runs the HTTP server until it is told to stop and then shuts the
+// process down in order: stop accepting connections, drain in-flight requests within a
+// deadline, then stop background work and flush storage through registered hooks.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"bidding-tracker/utils"
+)
+
+// defaultDrainTimeout bounds how long in-flight requests may take to finish
+const defaultDrainTimeout = 30 * time.Second
+
+// hook is one component stopped after requests have drained
+type hook struct {
+	name string
+	stop func() error
+}
+
+// Manager serves HTTP and owns the shutdown sequence
+type Manager struct {
+	drainTimeout time.Duration
+
+	mu      sync.Mutex
+	onDrain []func()
+	hooks   []hook
+}
+
+// New creates a manager that gives in-flight requests up to drainTimeout (default 30s)
+// to finish on shutdown
+func New(drainTimeout time.Duration) *Manager {
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+	return &Manager{drainTimeout: drainTimeout}
+}
+
+// OnDrain registers fn to run as soon as shutdown starts, before requests drain: e.g.
+// failing readiness, or ending long polls and streams that would otherwise hold the
+// drain open until its deadline
+func (m *Manager) OnDrain(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onDrain = append(m.onDrain, fn)
+}
+
+// OnStop registers a hook run after requests have drained. Hooks run newest first, like
+// defers, so a component registered after its dependencies is stopped before them.
+func (m *Manager) OnStop(name string, stop func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Serve serves srv on ln until ctx is done, then shuts down. Serving failures also shut
+// down. It returns the serving error, context.DeadlineExceeded when requests were still
+// running at the drain deadline, and hook errors, joined; the hooks run in every case.
+func (m *Manager) Serve(ctx context.Context, srv *http.Server, ln net.Listener) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ln)
+	}()
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+		utils.Info("Shutdown: draining requests", map[string]any{"timeout": m.drainTimeout.String()})
+	case err := <-served:
+		utils.Error("Shutdown: server failed", map[string]any{"error": err.Error()})
+		errs = append(errs, fmt.Errorf("serve: %w", err))
+	}
+
+	m.mu.Lock()
+	onDrain := append([]func(){}, m.onDrain...)
+	hooks := append([]hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	for _, fn := range onDrain {
+		fn()
+	}
+	if err := m.drain(srv); err != nil {
+		errs = append(errs, err)
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := runHook(hooks[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// drain closes the listener and waits for in-flight requests until the deadline
+func (m *Manager) drain(srv *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.drainTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := srv.Shutdown(ctx); err != nil {
+		// the remaining connections are cut when the process exits
+		utils.Warn("Shutdown: requests still running at the drain deadline", map[string]any{"error": err.Error()})
+		return fmt.Errorf("drain: %w", err)
+	}
+	utils.Info("Shutdown: requests drained", map[string]any{"took": time.Since(start).String()})
+	return nil
+}
+
+// runHook stops one component, converting a panic into an error so later hooks still run
+func runHook(h hook) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("stop %s: panic: %v", h.name, p)
+		}
+		if err != nil {
+			utils.Error("Shutdown: stop failed", map[string]any{"component": h.name, "error": err.Error()})
+		}
+	}()
+
+	start := time.Now()
+	if err := h.stop(); err != nil {
+		return fmt.Errorf("stop %s: %w", h.name, err)
+	}
+	utils.Info("Shutdown: stopped", map[string]any{"component": h.name, "took": time.Since(start).String()})
+	return nil
+}
@@ -0,0 +1,164 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the shutdown steps in the order they happen
+type recorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *recorder) add(step string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.steps...)
+}
+
+// slowServer serves a handler that signals entered and then blocks until release is closed
+func slowServer(t *testing.T, rec *recorder) (srv *http.Server, ln net.Listener, entered <-chan struct{}, release chan struct{}) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	in := make(chan struct{}, 1)
+	release = make(chan struct{})
+	srv = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		in <- struct{}{}
+		<-release
+		rec.add("request finished")
+		w.WriteHeader(http.StatusCreated)
+	})}
+	return srv, ln, in, release
+}
+
+// Test the shutdown sequence: drain hooks, listener closed, in-flight request finished,
+// then stop hooks newest first
+func TestManager_Serve(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	rec := &recorder{}
+	srv, ln, entered, release := slowServer(t, rec)
+	addr := ln.Addr().String()
+
+	m := New(5 * time.Second)
+	m.OnDrain(func() { rec.add("not ready") })
+	m.OnStop("storage", func() error { rec.add("storage"); return nil })
+	m.OnStop("subscribers", func() error { rec.add("subscribers"); return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- m.Serve(ctx, srv, ln) }()
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Post("http://"+addr+"/bids", "application/json", nil)
+		if err != nil {
+			status <- 0
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-entered
+
+	cancel()
+	require.Eventually(t, func() bool {
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond, "new connections are refused while draining")
+	require.Equal(t, []string{"not ready"}, rec.get(), "stop hooks wait for in-flight requests")
+
+	close(release)
+	require.Equal(t, http.StatusCreated, <-status, "the in-flight bid completes")
+	require.NoError(t, <-served)
+	require.Equal(t, []string{"not ready", "request finished", "subscribers", "storage"}, rec.get())
+}
+
+// Test that requests still running at the drain deadline are reported and hooks still run
+func TestManager_DrainDeadline(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	rec := &recorder{}
+	srv, ln, entered, release := slowServer(t, rec)
+	t.Cleanup(func() { close(release) })
+
+	m := New(100 * time.Millisecond)
+	m.OnStop("storage", func() error { rec.add("storage"); return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- m.Serve(ctx, srv, ln) }()
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	cancel()
+	err := <-served
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, []string{"storage"}, rec.get())
+}
+
+// Test that every hook runs even when some fail or panic, and their errors are returned
+func TestManager_HookErrors(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+
+	rec := &recorder{}
+	errFlush := errors.New("flush failed")
+	m := New(time.Second)
+	m.OnStop("storage", func() error { rec.add("storage"); return nil })
+	m.OnStop("api keys", func() error { return errFlush })
+	m.OnStop("webhooks", func() error { panic("boom") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = m.Serve(ctx, srv, ln)
+	require.ErrorIs(t, err, errFlush)
+	require.ErrorContains(t, err, "stop webhooks: panic: boom")
+	require.Equal(t, []string{"storage"}, rec.get())
+}
+
+// Test that a server that cannot serve still shuts down and reports why
+func TestManager_ServeFailure(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+
+	rec := &recorder{}
+	m := New(time.Second)
+	m.OnStop("storage", func() error { rec.add("storage"); return nil })
+
+	err = m.Serve(context.Background(), &http.Server{Handler: http.NotFoundHandler()}, ln)
+	require.ErrorContains(t, err, "serve:")
+	require.Equal(t, []string{"storage"}, rec.get())
+}
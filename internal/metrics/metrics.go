@@ -0,0 +1,345 @@
+// Package metrics is a small metrics registry that renders the Prometheus text
+// exposition format. Instrumented packages declare their metrics against Default
+// at package level; the router serves Default at /metrics.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType is the Prometheus text exposition format version served at /metrics
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefBuckets are latency buckets in seconds suited to HTTP requests
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the process-wide registry
+var Default = NewRegistry()
+
+// family is one named metric with its series
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families by name
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// register adds a family, panicking on a duplicate name as that is a programming error
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.families[name]; exists {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.families[name] = f
+}
+
+// WriteText renders every family in the Prometheus text format, sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w) // the client went away
+	})
+}
+
+// series holds the label sets of a vector in creation order
+type series[T any] struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	byKey  map[string]*T
+	values map[string][]string
+}
+
+func newSeries[T any](name, help, kind string, labels []string) *series[T] {
+	return &series[T]{name: name, help: help, kind: kind, labels: labels, byKey: make(map[string]*T), values: make(map[string][]string)}
+}
+
+// get returns the series for the label values, creating it with mk
+func (s *series[T]) get(values []string, mk func() *T) *T {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", s.name, len(s.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.byKey[key]
+	if !ok {
+		v = mk()
+		s.byKey[key] = v
+		s.values[key] = append([]string(nil), values...)
+	}
+	return v
+}
+
+// each visits the series sorted by label values
+func (s *series[T]) each(fn func(labels string, v *T)) {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.byKey))
+	for k := range s.byKey {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	type entry struct {
+		labels string
+		v      *T
+	}
+	entries := make([]entry, len(keys))
+	for i, k := range keys {
+		entries[i] = entry{labels: formatLabels(s.labels, s.values[k]), v: s.byKey[k]}
+	}
+	s.mu.Unlock()
+
+	for _, e := range entries {
+		fn(e.labels, e.v)
+	}
+}
+
+func (s *series[T]) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, escapeHelp(s.help), s.name, s.kind)
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	bits atomic.Uint64
+}
+
+// Inc adds one
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increases the counter by v, which must not be negative
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	for {
+		old := c.bits.Load()
+		if c.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Value returns the current count
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	s *series[Counter]
+}
+
+// NewCounterVec registers a counter with the given label names
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{s: newSeries[Counter](name, help, "counter", labels)}
+	r.register(name, v)
+	return v
+}
+
+// WithLabelValues returns the counter for the label values, in label order
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	return v.s.get(values, func() *Counter { return &Counter{} })
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.s.writeHeader(w)
+	v.s.each(func(labels string, c *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", v.s.name, braces(labels), formatValue(c.Value()))
+	})
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	upper []float64 // shared with the vector
+
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	count  uint64
+	sum    float64
+}
+
+// Observe records one value
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v) // first bucket with upper >= v
+	h.mu.Lock()
+	h.counts[i]++
+	h.count++
+	h.sum += v
+	h.mu.Unlock()
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Sum returns the total of all observations
+func (h *Histogram) Sum() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sum
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	s       *series[Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	v := &HistogramVec{s: newSeries[Histogram](name, help, "histogram", labels), buckets: b}
+	r.register(name, v)
+	return v
+}
+
+// WithLabelValues returns the histogram for the label values, in label order
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return v.s.get(values, func() *Histogram {
+		return &Histogram{upper: v.buckets, counts: make([]uint64, len(v.buckets)+1)}
+	})
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.s.writeHeader(w)
+	v.s.each(func(labels string, h *Histogram) {
+		h.mu.Lock()
+		counts := append([]uint64(nil), h.counts...)
+		count, sum := h.count, h.sum
+		h.mu.Unlock()
+
+		sep := ""
+		if labels != "" {
+			sep = ","
+		}
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", v.s.name, labels, sep, formatValue(upper), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", v.s.name, labels, sep, count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.s.name, braces(labels), formatValue(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.s.name, braces(labels), count)
+	})
+}
+
+// gaugeFunc is a gauge whose values are read at scrape time
+type gaugeFunc struct {
+	name, help string
+	label      string // empty for an unlabelled gauge
+	collect    func() map[string]float64
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on every scrape
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, &gaugeFunc{name: name, help: help, collect: func() map[string]float64 {
+		return map[string]float64{"": fn()}
+	}})
+}
+
+// NewGaugeVecFunc registers a gauge with one label whose values, keyed by label
+// value, are read from fn on every scrape
+func (r *Registry) NewGaugeVecFunc(name, help, label string, fn func() map[string]float64) {
+	r.register(name, &gaugeFunc{name: name, help: help, label: label, collect: fn})
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, escapeHelp(g.help), g.name)
+	values := g.collect()
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		labels := ""
+		if g.label != "" {
+			labels = formatLabels([]string{g.label}, []string{k})
+		}
+		fmt.Fprintf(w, "%s%s %s\n", g.name, braces(labels), formatValue(values[k]))
+	}
+}
+
+// formatLabels renders name="value" pairs without braces
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test the rendered exposition format for each metric type
+func TestRegistry_WriteText(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	reg := NewRegistry()
+	requests := reg.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	requests.WithLabelValues("/items/:item_id", "200").Add(2)
+	requests.WithLabelValues("/bids", "201").Inc()
+	requests.WithLabelValues(`say "hi"\`+"\n", "500").Inc()
+
+	latency := reg.NewHistogramVec("latency_seconds", "Latency\nin seconds.", []float64{1, 0.1}, "op")
+	h := latency.WithLabelValues("read")
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		h.Observe(v)
+	}
+
+	unlabelled := reg.NewHistogramVec("wait_seconds", "Wait.", []float64{1})
+	unlabelled.WithLabelValues().Observe(2)
+
+	reg.NewGaugeFunc("items", "Items.", func() float64 { return 3 })
+	reg.NewGaugeFunc("unknown", "Unknown.", func() float64 { return math.NaN() })
+	reg.NewGaugeVecFunc("queue_depth", "Queued.", "subscriber", func() map[string]float64 {
+		return map[string]float64{"webhooks": 2, "notifications": 0}
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, reg.WriteText(&buf))
+	require.Equal(t, `# HELP items Items.
+# TYPE items gauge
+items 3
+# HELP latency_seconds Latency\nin seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="read",le="0.1"} 2
+latency_seconds_bucket{op="read",le="1"} 3
+latency_seconds_bucket{op="read",le="+Inf"} 4
+latency_seconds_sum{op="read"} 3.65
+latency_seconds_count{op="read"} 4
+# HELP queue_depth Queued.
+# TYPE queue_depth gauge
+queue_depth{subscriber="notifications"} 0
+queue_depth{subscriber="webhooks"} 2
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/bids",status="201"} 1
+requests_total{route="/items/:item_id",status="200"} 2
+requests_total{route="say \"hi\"\\\n",status="500"} 1
+# HELP unknown Unknown.
+# TYPE unknown gauge
+unknown NaN
+# HELP wait_seconds Wait.
+# TYPE wait_seconds histogram
+wait_seconds_bucket{le="1"} 0
+wait_seconds_bucket{le="+Inf"} 1
+wait_seconds_sum 2
+wait_seconds_count 1
+`, buf.String())
+
+	require.Equal(t, 2.0, requests.WithLabelValues("/items/:item_id", "200").Value())
+	require.Equal(t, uint64(4), h.Count())
+	require.InDelta(t, 3.65, h.Sum(), 1e-9)
+}
+
+// Test the HTTP handler and misuse panics
+func TestRegistry_Handler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	reg := NewRegistry()
+	counter := reg.NewCounterVec("events_total", "Events.", "type")
+	counter.WithLabelValues("bid").Inc()
+
+	w := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), `events_total{type="bid"} 1`)
+
+	require.Panics(t, func() { reg.NewCounterVec("events_total", "Again.") }, "duplicate name")
+	require.Panics(t, func() { counter.WithLabelValues("bid", "extra") }, "wrong label count")
+	require.Panics(t, func() { counter.WithLabelValues("bid").Add(-1) }, "negative add")
+}
@@ -10,10 +10,12 @@ type User struct {
 
 // Item represents an auction item
 type Item struct {
-	ItemID        string  `json:"item_id"`
-	Title         string  `json:"title"`
-	Description   string  `json:"description"`
-	StartingPrice float64 `json:"starting_price"`
+	ItemID        string     `json:"item_id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	StartingPrice float64    `json:"starting_price"`
+	Owner         string     `json:"owner,omitempty"`     // user ID of the seller; only they or an admin may close the auction
+	ClosedAt      *time.Time `json:"closed_at,omitempty"` // when bidding ended; nil while the auction is open
 }
 
 // Bid represents a user's bid on an item
@@ -23,4 +25,7 @@ type Bid struct {
 	UserID    string    `json:"user_id"`
 	Amount    float64   `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	Seq       uint64    `json:"seq,omitempty"`       // position in the item's bid history, starting at 1
+	PrevHash  string    `json:"prev_hash,omitempty"` // hash of the item's previous bid; "" for the first
+	Hash      string    `json:"hash,omitempty"`      // chain hash over this bid and PrevHash
 }
@@ -0,0 +1,175 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Kind identifies what a notification is about
+type Kind string
+
+const (
+	KindOutbid      Kind = "outbid"
+	KindAuctionWon  Kind = "auction_won"
+	KindAuctionLost Kind = "auction_lost"
+)
+
+// ErrInvalidPreferences is returned when preferences fail validation
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+
+// Notification is a rendered message for one user on one channel
+type Notification struct {
+	UserID    string    `json:"user_id"`
+	To        string    `json:"to,omitempty"` // channel address, e.g. the user's email
+	Kind      Kind      `json:"kind"`
+	ItemID    string    `json:"item_id"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notifier delivers notifications over one channel
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// QuietHours is a daily window, in the user's timezone, during which delivery is deferred.
+// A window whose end is before its start spans midnight.
+type QuietHours struct {
+	Start    string `json:"start"`    // "HH:MM"
+	End      string `json:"end"`      // "HH:MM"
+	Timezone string `json:"timezone"` // IANA name; empty means UTC
+}
+
+// Preferences are one user's notification settings
+type Preferences struct {
+	UserID     string      `json:"user_id"`
+	Email      string      `json:"email,omitempty"`
+	Channels   []string    `json:"channels,omitempty"` // empty means every configured channel
+	Disabled   []Kind      `json:"disabled,omitempty"` // kinds the user opted out of
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// Validate checks that quiet hours are well-formed
+func (p Preferences) Validate() error {
+	if p.QuietHours == nil {
+		return nil
+	}
+	if _, err := parseClock(p.QuietHours.Start); err != nil {
+		return fmt.Errorf("%w: quiet_hours.start: %v", ErrInvalidPreferences, err)
+	}
+	if _, err := parseClock(p.QuietHours.End); err != nil {
+		return fmt.Errorf("%w: quiet_hours.end: %v", ErrInvalidPreferences, err)
+	}
+	if _, err := time.LoadLocation(p.QuietHours.Timezone); err != nil {
+		return fmt.Errorf("%w: quiet_hours.timezone: %v", ErrInvalidPreferences, err)
+	}
+	return nil
+}
+
+// wants reports whether the user accepts a kind of notification
+func (p Preferences) wants(kind Kind) bool {
+	for _, k := range p.Disabled {
+		if k == kind {
+			return false
+		}
+	}
+	return true
+}
+
+// usesChannel reports whether the user accepts notifications on a channel
+func (p Preferences) usesChannel(channel string) bool {
+	if len(p.Channels) == 0 {
+		return true
+	}
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferenceStore holds user preferences
+type PreferenceStore interface {
+	Get(ctx context.Context, userID string) (Preferences, error)
+	Set(ctx context.Context, prefs Preferences) error
+}
+
+// MemoryPreferences is an in-memory PreferenceStore. Users without stored
+// preferences get the defaults: every kind, every channel, no quiet hours.
+type MemoryPreferences struct {
+	mu    sync.RWMutex
+	prefs map[string]Preferences
+}
+
+// NewMemoryPreferences creates an empty preference store
+func NewMemoryPreferences() *MemoryPreferences {
+	return &MemoryPreferences{prefs: make(map[string]Preferences)}
+}
+
+// Get returns a user's preferences, or the defaults if none were set
+func (m *MemoryPreferences) Get(_ context.Context, userID string) (Preferences, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.prefs[userID]; ok {
+		return p, nil
+	}
+	return Preferences{UserID: userID}, nil
+}
+
+// Set validates and stores a user's preferences
+func (m *MemoryPreferences) Set(_ context.Context, prefs Preferences) error {
+	if err := prefs.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// deliverAt returns when a notification created at now may be delivered:
+// now itself, or the end of the user's quiet hours if now falls inside them
+func deliverAt(q *QuietHours, now time.Time) time.Time {
+	if q == nil {
+		return now
+	}
+	start, err1 := parseClock(q.Start)
+	end, err2 := parseClock(q.End)
+	loc, err3 := time.LoadLocation(q.Timezone)
+	if err1 != nil || err2 != nil || err3 != nil || start == end {
+		return now
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		quiet = minute >= start || minute < end // window spans midnight
+	}
+	if !quiet {
+		return now
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until
+}
@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliverAt(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	day := func(h, m int) time.Time { return time.Date(2024, 3, 10, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		quiet *QuietHours
+		now   time.Time
+		want  time.Time
+	}{
+		{name: "no_quiet_hours", quiet: nil, now: day(23, 0), want: day(23, 0)},
+		{name: "outside_window", quiet: &QuietHours{Start: "09:00", End: "17:00"}, now: day(8, 59), want: day(8, 59)},
+		{name: "inside_window", quiet: &QuietHours{Start: "09:00", End: "17:00"}, now: day(12, 30), want: day(17, 0)},
+		{name: "window_end_is_exclusive", quiet: &QuietHours{Start: "09:00", End: "17:00"}, now: day(17, 0), want: day(17, 0)},
+		{name: "overnight_before_midnight", quiet: &QuietHours{Start: "22:00", End: "07:00"}, now: day(23, 15), want: day(31, 0)},
+		{name: "overnight_after_midnight", quiet: &QuietHours{Start: "22:00", End: "07:00"}, now: day(3, 0), want: day(7, 0)},
+		{name: "overnight_outside", quiet: &QuietHours{Start: "22:00", End: "07:00"}, now: day(12, 0), want: day(12, 0)},
+		{name: "user_timezone", quiet: &QuietHours{Start: "22:00", End: "07:00", Timezone: "America/New_York"}, now: day(4, 0), want: day(11, 0)},
+		{name: "empty_window", quiet: &QuietHours{Start: "10:00", End: "10:00"}, now: day(10, 0), want: day(10, 0)},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			require.True(t, tc.want.Equal(deliverAt(tc.quiet, tc.now)), "want %v, got %v", tc.want, deliverAt(tc.quiet, tc.now))
+		})
+	}
+}
+
+func TestMemoryPreferences(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name      string
+		prefs     Preferences
+		wantError bool
+	}{
+		{name: "no_quiet_hours", prefs: Preferences{UserID: "user1", Email: "user1@example.com"}},
+		{name: "valid_quiet_hours", prefs: Preferences{UserID: "user1", QuietHours: &QuietHours{Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"}}},
+		{name: "bad_start", prefs: Preferences{UserID: "user1", QuietHours: &QuietHours{Start: "25:00", End: "07:00"}}, wantError: true},
+		{name: "bad_end", prefs: Preferences{UserID: "user1", QuietHours: &QuietHours{Start: "22:00", End: "7am"}}, wantError: true},
+		{name: "bad_timezone", prefs: Preferences{UserID: "user1", QuietHours: &QuietHours{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}}, wantError: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			store := NewMemoryPreferences()
+			err := store.Set(context.Background(), tc.prefs)
+			if tc.wantError {
+				require.ErrorIs(t, err, ErrInvalidPreferences)
+				got, _ := store.Get(context.Background(), tc.prefs.UserID)
+				require.Equal(t, Preferences{UserID: tc.prefs.UserID}, got, "invalid preferences must not be stored")
+				return
+			}
+			require.NoError(t, err)
+			got, err := store.Get(context.Background(), tc.prefs.UserID)
+			require.NoError(t, err)
+			require.Equal(t, tc.prefs, got)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoAddress is returned by channels that need an address the user has not provided
+var ErrNoAddress = errors.New("notification: recipient has no address for channel")
+
+// SMTPConfig configures the SMTP channel
+type SMTPConfig struct {
+	Addr     string // host:port
+	From     string
+	Username string // optional; enables PLAIN auth
+	Password string
+}
+
+// smtpTimeout bounds a whole SMTP exchange when the context has no deadline
+const smtpTimeout = 30 * time.Second
+
+// SMTPNotifier delivers notifications as plain-text email
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPNotifier creates an SMTP channel
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Notify sends one email to n.To, upgrading to TLS when the server offers STARTTLS
+func (s *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.To == "" {
+		return ErrNoAddress
+	}
+	if err := s.send(ctx, n.To, s.message(n)); err != nil {
+		return fmt.Errorf("notification: smtp send to %s: %w", n.To, err)
+	}
+	return nil
+}
+
+// send runs one SMTP transaction, bounded by the context deadline
+func (s *SMTPNotifier) send(ctx context.Context, to string, msg []byte) error {
+	host, _, err := net.SplitHostPort(s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message formats a notification as an RFC 5322 email
+func (s *SMTPNotifier) message(n Notification) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", n.Subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	return []byte(msg.String())
+}
+
+// LogNotifier writes each notification as a JSON line, e.g. to a file or stdout
+type LogNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogNotifier creates a channel that writes to w
+func NewLogNotifier(w io.Writer) *LogNotifier {
+	return &LogNotifier{w: w}
+}
+
+// OpenFileNotifier creates a LogNotifier appending to a file, and returns the file for closing
+func OpenFileNotifier(path string) (*LogNotifier, *os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("notification: open %s: %w", path, err)
+	}
+	return NewLogNotifier(f), f, nil
+}
+
+// Notify appends the notification as one JSON line
+func (l *LogNotifier) Notify(_ context.Context, n Notification) error {
+	line, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("notification: encode: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(line); err != nil {
+		return fmt.Errorf("notification: write: %w", err)
+	}
+	return nil
+}
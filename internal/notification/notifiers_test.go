@@ -0,0 +1,191 @@
+package notification
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer is a minimal SMTP server that accepts every message
+type fakeSMTPServer struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []smtpMessage
+	rejectTo string // recipient refused with 550
+}
+
+// smtpMessage is one message received by the fake server
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeSMTPServer) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 fake.smtp ready")
+	var msg smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 fake.smtp")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			msg = smtpMessage{from: strings.Trim(cmd[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			to := strings.Trim(cmd[len("RCPT TO:"):], "<>")
+			if to == s.rejectTo {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name      string
+		to        string
+		reject    string
+		wantError error
+		wantSent  bool
+	}{
+		{name: "delivers_message", to: "user1@example.com", wantSent: true},
+		{name: "missing_address", to: "", wantError: ErrNoAddress},
+		{name: "recipient_rejected", to: "nobody@example.com", reject: "nobody@example.com"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			srv := newFakeSMTPServer(t)
+			srv.rejectTo = tc.reject
+			notifier := NewSMTPNotifier(SMTPConfig{Addr: srv.addr(), From: "auctions@example.com"})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := notifier.Notify(ctx, Notification{
+				UserID:    "user1",
+				To:        tc.to,
+				Kind:      KindOutbid,
+				ItemID:    "item1",
+				Subject:   "You have been outbid on item1",
+				Body:      "Hi user1,\nYou were outbid.\n",
+				CreatedAt: time.Now(),
+			})
+
+			switch {
+			case tc.wantError != nil:
+				require.ErrorIs(t, err, tc.wantError)
+			case !tc.wantSent:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+
+			msgs := srv.received()
+			if !tc.wantSent {
+				require.Empty(t, msgs)
+				return
+			}
+			require.Len(t, msgs, 1)
+			require.Equal(t, "auctions@example.com", msgs[0].from)
+			require.Equal(t, []string{tc.to}, msgs[0].to)
+			require.Contains(t, msgs[0].data, "Subject: You have been outbid on item1\r\n")
+			require.Contains(t, msgs[0].data, "Hi user1,\r\nYou were outbid.\r\n")
+		})
+	}
+}
+
+func TestLogNotifier(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	var buf bytes.Buffer
+	notifier := NewLogNotifier(&buf)
+	n := Notification{UserID: "user1", Kind: KindAuctionWon, ItemID: "item1", Subject: "s", Body: "b", CreatedAt: time.Now().UTC()}
+	require.NoError(t, notifier.Notify(context.Background(), n))
+	require.NoError(t, notifier.Notify(context.Background(), n))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var got Notification
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	require.Equal(t, n, got)
+}
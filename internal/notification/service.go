@@ -0,0 +1,294 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/models"
+	"bidding-tracker/internal/retry"
+	"bidding-tracker/utils"
+)
+
+// BidReader is the repository access needed to find an item's bidders
+type BidReader interface {
+	GetBidsByItem(ctx context.Context, itemID string) ([]models.Bid, error)
+}
+
+// ErrQueueFull is returned when a notification is dropped because MaxPending deliveries
+// are already waiting
+var ErrQueueFull = errors.New("notification: delivery queue full")
+
+const (
+	defaultWorkers    = 4
+	defaultMaxPending = 10000
+)
+
+// Config configures the notification service
+type Config struct {
+	Notifiers   map[string]Notifier // delivery channels by name, e.g. "email", "log"
+	Preferences PreferenceStore
+	Templates   *Templates       // nil uses DefaultTemplates
+	Retry       retry.Policy     // per-channel retries; zero value uses retry.Default
+	Workers     int              // concurrent sends; 0 uses 4
+	MaxPending  int              // deliveries queued, deferred or awaiting a retry; 0 uses 10000
+	Now         func() time.Time // nil uses time.Now; for tests
+}
+
+// Service turns domain events into user notifications. It is registered as an
+// events.Bus subscriber and delivers in the background, so slow channels and
+// retries never hold up bidding.
+type Service struct {
+	cfg  Config
+	bids BidReader
+
+	// a fixed pool of workers sends from queue. Deliveries waiting for quiet hours or a
+	// retry sit on timers rather than workers, and each holds a slot until it finishes,
+	// so at most MaxPending are kept.
+	queue   chan delivery
+	slots   chan struct{}
+	pending sync.WaitGroup // accepted deliveries that have not finished
+
+	stop chan struct{}
+	wg   sync.WaitGroup // workers
+}
+
+// delivery is one notification on one channel
+type delivery struct {
+	channel  string
+	notifier Notifier
+	n        Notification
+	attempt  int // attempts made so far
+}
+
+// NewService creates a notification service. bids is used to find the losing
+// bidders when an auction closes and may be nil.
+func NewService(cfg Config, bids BidReader) *Service {
+	if cfg.Templates == nil {
+		cfg.Templates = DefaultTemplates()
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry = retry.Default
+	}
+	if cfg.Preferences == nil {
+		cfg.Preferences = NewMemoryPreferences()
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = defaultMaxPending
+	}
+
+	s := &Service{
+		cfg:   cfg,
+		bids:  bids,
+		queue: make(chan delivery, cfg.MaxPending),
+		slots: make(chan struct{}, cfg.MaxPending),
+		stop:  make(chan struct{}),
+	}
+	s.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+// HandleEvent reacts to leader changes and auction close
+func (s *Service) HandleEvent(ctx context.Context, ev events.Event) error {
+	switch e := ev.(type) {
+	case events.LeaderChanged:
+		if e.Previous == nil || e.Previous.UserID == e.Leader.UserID {
+			return nil
+		}
+		return s.notify(ctx, e.Previous.UserID, KindOutbid, TemplateData{
+			UserID:        e.Previous.UserID,
+			ItemID:        e.ItemID(),
+			Amount:        e.Previous.Amount,
+			WinningAmount: e.Leader.Amount,
+		})
+	case events.AuctionClosed:
+		return s.auctionClosed(ctx, e)
+	}
+	return nil
+}
+
+// auctionClosed notifies the winner and every other bidder
+func (s *Service) auctionClosed(ctx context.Context, e events.AuctionClosed) error {
+	var errs []error
+	if e.Winner != nil {
+		errs = append(errs, s.notify(ctx, e.Winner.UserID, KindAuctionWon, TemplateData{
+			UserID:        e.Winner.UserID,
+			ItemID:        e.ItemID(),
+			Amount:        e.Winner.Amount,
+			WinningAmount: e.Winner.Amount,
+		}))
+	}
+	if s.bids == nil || e.Winner == nil {
+		return errors.Join(errs...)
+	}
+
+	bids, err := s.bids.GetBidsByItem(ctx, e.ItemID())
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("notification: load bidders for %s: %w", e.ItemID(), err))...)
+	}
+
+	// each losing bidder's highest bid
+	highest := make(map[string]float64)
+	var order []string
+	for _, b := range bids {
+		if b.UserID == e.Winner.UserID {
+			continue
+		}
+		if amount, ok := highest[b.UserID]; !ok {
+			order = append(order, b.UserID)
+			highest[b.UserID] = b.Amount
+		} else if b.Amount > amount {
+			highest[b.UserID] = b.Amount
+		}
+	}
+	for _, userID := range order {
+		errs = append(errs, s.notify(ctx, userID, KindAuctionLost, TemplateData{
+			UserID:        userID,
+			ItemID:        e.ItemID(),
+			Amount:        highest[userID],
+			WinningAmount: e.Winner.Amount,
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// notify renders a notification and schedules it on each channel the user accepts
+func (s *Service) notify(ctx context.Context, userID string, kind Kind, data TemplateData) error {
+	prefs, err := s.cfg.Preferences.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("notification: load preferences for %s: %w", userID, err)
+	}
+	if !prefs.wants(kind) {
+		return nil
+	}
+
+	subject, body, err := s.cfg.Templates.Render(kind, data)
+	if err != nil {
+		return err
+	}
+
+	now := s.cfg.Now()
+	n := Notification{
+		UserID:    userID,
+		To:        prefs.Email,
+		Kind:      kind,
+		ItemID:    data.ItemID,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: now.UTC(),
+	}
+	delay := deliverAt(prefs.QuietHours, now).Sub(now)
+
+	var errs []error
+	for name, notifier := range s.cfg.Notifiers {
+		if !prefs.usesChannel(name) {
+			continue
+		}
+		d := delivery{channel: name, notifier: notifier, n: n}
+		if !s.submit(d, delay) {
+			utils.Error("Notification: dropped, delivery queue full", d.fields())
+			errs = append(errs, fmt.Errorf("%w: %s to %s", ErrQueueFull, name, userID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// submit accepts a delivery to be sent after delay, or reports false when MaxPending
+// deliveries are already waiting
+func (s *Service) submit(d delivery, delay time.Duration) bool {
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return false
+	}
+	s.pending.Add(1)
+	if delay > 0 {
+		utils.Info("Notification: deferred for quiet hours", fieldsWith(d.fields(), "delay", delay.String()))
+	}
+	s.schedule(d, delay)
+	return true
+}
+
+// schedule queues an accepted delivery for the workers after delay. The queue holds
+// MaxPending entries and every accepted delivery holds a slot, so sends never block.
+func (s *Service) schedule(d delivery, delay time.Duration) {
+	if delay <= 0 {
+		s.queue <- d
+		return
+	}
+	time.AfterFunc(delay, func() {
+		select {
+		case <-s.stop: // abandoned on shutdown
+		default:
+			s.queue <- d
+		}
+	})
+}
+
+// worker sends queued deliveries until the service is closed
+func (s *Service) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case d := <-s.queue:
+			s.deliver(d)
+		}
+	}
+}
+
+// deliver makes one attempt and schedules the next after a backoff, until success,
+// a permanent failure or the attempt limit
+func (s *Service) deliver(d delivery) {
+	d.attempt++
+	fields := fieldsWith(d.fields(), "attempt", d.attempt)
+
+	err := d.notifier.Notify(context.Background(), d.n)
+	switch {
+	case err == nil:
+		utils.Info("Notification: delivered", fields)
+	case errors.Is(err, ErrNoAddress) || d.attempt >= s.cfg.Retry.MaxAttempts:
+		utils.Error("Notification: delivery failed", fieldsWith(fields, "error", err.Error()))
+	default:
+		backoff := s.cfg.Retry.Backoff(d.attempt + 1)
+		utils.Warn("Notification: delivery failed, retrying", fieldsWith(fields, "retry_in", backoff.String()))
+		s.schedule(d, backoff) // keeps its slot
+		return
+	}
+	<-s.slots
+	s.pending.Done()
+}
+
+// fields returns the log fields identifying a delivery
+func (d delivery) fields() map[string]any {
+	return map[string]any{"channel": d.channel, "user_id": d.n.UserID, "kind": string(d.n.Kind), "item_id": d.n.ItemID}
+}
+
+// Close abandons pending retries and deferred deliveries and waits for in-flight sends
+func (s *Service) Close() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// fieldsWith returns a copy of log fields with one more key
+func fieldsWith(fields map[string]any, key string, value any) map[string]any {
+	out := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
@@ -0,0 +1,300 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/models"
+	"bidding-tracker/internal/retry"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifier records deliveries and fails the first failures attempts
+type fakeNotifier struct {
+	mu        sync.Mutex
+	failures  int
+	err       error
+	attempts  int
+	delivered []Notification
+	done      chan struct{} // receives once per successful delivery
+}
+
+func newFakeNotifier(failures int, err error) *fakeNotifier {
+	return &fakeNotifier{failures: failures, err: err, done: make(chan struct{}, 16)}
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.attempts <= f.failures {
+		return f.err
+	}
+	f.delivered = append(f.delivered, n)
+	f.done <- struct{}{}
+	return nil
+}
+
+func (f *fakeNotifier) snapshot() (int, []Notification) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts, append([]Notification(nil), f.delivered...)
+}
+
+// waitDelivered blocks until n deliveries were recorded
+func (f *fakeNotifier) waitDelivered(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-f.done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d", i+1)
+		}
+	}
+}
+
+// fakeBids serves a fixed bid history
+type fakeBids []models.Bid
+
+func (b fakeBids) GetBidsByItem(context.Context, string) ([]models.Bid, error) {
+	return b, nil
+}
+
+var fastRetry = retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestService_Outbid(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	previous := models.Bid{ItemID: "item1", UserID: "user1", Amount: 100}
+	leader := models.Bid{ItemID: "item1", UserID: "user2", Amount: 150}
+
+	tests := []struct {
+		name       string
+		ev         events.Event
+		prefs      *Preferences
+		wantUsers  []string
+		wantEmail  string
+		wantInBody string
+	}{
+		{
+			name:       "outbid_user_notified",
+			ev:         events.LeaderChanged{Leader: leader, Previous: &previous},
+			wantUsers:  []string{"user1"},
+			wantInBody: "Your bid of 100.00 on item1 is no longer the highest. The leading bid is now 150.00.",
+		},
+		{
+			name:      "email_from_preferences",
+			ev:        events.LeaderChanged{Leader: leader, Previous: &previous},
+			prefs:     &Preferences{UserID: "user1", Email: "user1@example.com"},
+			wantUsers: []string{"user1"},
+			wantEmail: "user1@example.com",
+		},
+		{
+			name:      "first_bid_notifies_nobody",
+			ev:        events.LeaderChanged{Leader: leader},
+			wantUsers: nil,
+		},
+		{
+			name:      "bid_placed_ignored",
+			ev:        events.BidPlaced{Bid: leader},
+			wantUsers: nil,
+		},
+		{
+			name:      "kind_disabled",
+			ev:        events.LeaderChanged{Leader: leader, Previous: &previous},
+			prefs:     &Preferences{UserID: "user1", Disabled: []Kind{KindOutbid}},
+			wantUsers: nil,
+		},
+		{
+			name:      "channel_not_selected",
+			ev:        events.LeaderChanged{Leader: leader, Previous: &previous},
+			prefs:     &Preferences{UserID: "user1", Channels: []string{"email"}},
+			wantUsers: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			prefs := NewMemoryPreferences()
+			if tc.prefs != nil {
+				require.NoError(t, prefs.Set(context.Background(), *tc.prefs))
+			}
+			notifier := newFakeNotifier(0, nil)
+			svc := NewService(Config{Notifiers: map[string]Notifier{"log": notifier}, Preferences: prefs, Retry: fastRetry}, nil)
+
+			require.NoError(t, svc.HandleEvent(context.Background(), tc.ev))
+			notifier.waitDelivered(t, len(tc.wantUsers))
+			svc.Close()
+
+			_, delivered := notifier.snapshot()
+			var users []string
+			for _, n := range delivered {
+				users = append(users, n.UserID)
+				require.Equal(t, KindOutbid, n.Kind)
+				require.Equal(t, tc.wantEmail, n.To)
+				require.Contains(t, n.Body, tc.wantInBody)
+			}
+			require.Equal(t, tc.wantUsers, users)
+		})
+	}
+}
+
+func TestService_AuctionClosed(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	winner := models.Bid{ItemID: "item1", UserID: "user3", Amount: 300}
+	history := fakeBids{
+		{ItemID: "item1", UserID: "user1", Amount: 100},
+		{ItemID: "item1", UserID: "user2", Amount: 150},
+		{ItemID: "item1", UserID: "user1", Amount: 200},
+		winner,
+	}
+
+	notifier := newFakeNotifier(0, nil)
+	svc := NewService(Config{Notifiers: map[string]Notifier{"log": notifier}, Retry: fastRetry}, history)
+
+	require.NoError(t, svc.HandleEvent(context.Background(), events.AuctionClosed{Item: "item1", Winner: &winner}))
+	notifier.waitDelivered(t, 3)
+	svc.Close()
+
+	_, delivered := notifier.snapshot()
+	got := make(map[string]Notification)
+	for _, n := range delivered {
+		got[n.UserID] = n
+	}
+	require.Len(t, got, 3)
+	require.Equal(t, KindAuctionWon, got["user3"].Kind)
+	require.Equal(t, KindAuctionLost, got["user1"].Kind)
+	require.Contains(t, got["user1"].Body, "Your highest bid was 200.00")
+	require.Equal(t, KindAuctionLost, got["user2"].Kind)
+}
+
+func TestService_Retry(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name          string
+		failures      int
+		err           error
+		wantAttempts  int
+		wantDelivered int
+	}{
+		{name: "succeeds_first_time", failures: 0, err: nil, wantAttempts: 1, wantDelivered: 1},
+		{name: "succeeds_after_retries", failures: 2, err: errors.New("temporary"), wantAttempts: 3, wantDelivered: 1},
+		{name: "gives_up_after_max_attempts", failures: 10, err: errors.New("down"), wantAttempts: 3, wantDelivered: 0},
+		{name: "permanent_failure_not_retried", failures: 10, err: ErrNoAddress, wantAttempts: 1, wantDelivered: 0},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			notifier := newFakeNotifier(tc.failures, tc.err)
+			svc := NewService(Config{Notifiers: map[string]Notifier{"log": notifier}, Retry: fastRetry}, nil)
+
+			previous := models.Bid{ItemID: "item1", UserID: "user1", Amount: 100}
+			ev := events.LeaderChanged{Leader: models.Bid{ItemID: "item1", UserID: "user2", Amount: 150}, Previous: &previous}
+			require.NoError(t, svc.HandleEvent(context.Background(), ev))
+
+			// deliveries finish on their own once they succeed or give up
+			svc.pending.Wait()
+			svc.Close()
+
+			attempts, delivered := notifier.snapshot()
+			require.Equal(t, tc.wantAttempts, attempts)
+			require.Len(t, delivered, tc.wantDelivered)
+		})
+	}
+}
+
+func TestService_QuietHoursDeferDelivery(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	// quiet hours end 100ms after the event
+	now := time.Date(2024, 3, 10, 21, 59, 59, int(900*time.Millisecond), time.UTC)
+	prefs := NewMemoryPreferences()
+	require.NoError(t, prefs.Set(context.Background(), Preferences{UserID: "user1", QuietHours: &QuietHours{Start: "21:00", End: "22:00"}}))
+
+	notifier := newFakeNotifier(0, nil)
+	svc := NewService(Config{
+		Notifiers:   map[string]Notifier{"log": notifier},
+		Preferences: prefs,
+		Retry:       fastRetry,
+		Now:         func() time.Time { return now },
+	}, nil)
+	defer svc.Close()
+
+	previous := models.Bid{ItemID: "item1", UserID: "user1", Amount: 100}
+	start := time.Now()
+	require.NoError(t, svc.HandleEvent(context.Background(), events.LeaderChanged{
+		Leader:   models.Bid{ItemID: "item1", UserID: "user2", Amount: 150},
+		Previous: &previous,
+	}))
+
+	notifier.waitDelivered(t, 1)
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+// blockingNotifier holds every send until release is closed and records the peak
+// number of concurrent sends
+type blockingNotifier struct {
+	release chan struct{}
+	started chan struct{}
+
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (b *blockingNotifier) Notify(context.Context, Notification) error {
+	b.mu.Lock()
+	b.running++
+	b.peak = max(b.peak, b.running)
+	b.mu.Unlock()
+	b.started <- struct{}{}
+
+	<-b.release
+	b.mu.Lock()
+	b.running--
+	b.mu.Unlock()
+	return nil
+}
+
+// Test that sends run on a fixed number of workers and notifications beyond MaxPending
+// are dropped rather than queued without bound
+func TestService_BoundedDelivery(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	notifier := &blockingNotifier{release: make(chan struct{}), started: make(chan struct{}, 16)}
+	svc := NewService(Config{Notifiers: map[string]Notifier{"log": notifier}, Retry: fastRetry, Workers: 2, MaxPending: 3}, nil)
+
+	outbid := func(userID string) error {
+		previous := models.Bid{ItemID: "item1", UserID: userID, Amount: 100}
+		return svc.HandleEvent(context.Background(), events.LeaderChanged{Leader: models.Bid{ItemID: "item1", UserID: "leader", Amount: 150}, Previous: &previous})
+	}
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		require.NoError(t, outbid(userID))
+	}
+	require.ErrorIs(t, outbid("user4"), ErrQueueFull)
+
+	// both workers are busy; the third delivery waits for one of them
+	<-notifier.started
+	<-notifier.started
+	close(notifier.release)
+	svc.pending.Wait()
+	require.Len(t, notifier.started, 1, "the queued delivery was sent once a worker was free")
+
+	require.NoError(t, outbid("user5"), "finished deliveries free their slots")
+	svc.pending.Wait()
+	svc.Close()
+	require.Equal(t, 2, notifier.peak)
+}
@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// TemplateData is what notification templates are rendered with
+type TemplateData struct {
+	UserID        string
+	ItemID        string
+	Amount        float64 // the recipient's own highest bid
+	WinningAmount float64 // the current or final winning bid
+}
+
+// messageTemplate is the subject and body template for one kind
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// Templates renders notifications per kind
+type Templates struct {
+	byKind map[Kind]messageTemplate
+}
+
+// defaultTemplates are the built-in subject and body texts
+var defaultTemplates = map[Kind][2]string{
+	KindOutbid: {
+		`You have been outbid on {{.ItemID}}`,
+		"Hi {{.UserID}},\n\nYour bid of {{printf \"%.2f\" .Amount}} on {{.ItemID}} is no longer the highest. The leading bid is now {{printf \"%.2f\" .WinningAmount}}.\n",
+	},
+	KindAuctionWon: {
+		`You won {{.ItemID}}`,
+		"Hi {{.UserID}},\n\nThe auction for {{.ItemID}} has closed and your bid of {{printf \"%.2f\" .WinningAmount}} won.\n",
+	},
+	KindAuctionLost: {
+		`Auction closed: {{.ItemID}}`,
+		"Hi {{.UserID}},\n\nThe auction for {{.ItemID}} has closed. Your highest bid was {{printf \"%.2f\" .Amount}}; the winning bid was {{printf \"%.2f\" .WinningAmount}}.\n",
+	},
+}
+
+// DefaultTemplates returns the built-in templates
+func DefaultTemplates() *Templates {
+	t := &Templates{byKind: make(map[Kind]messageTemplate)}
+	for kind, texts := range defaultTemplates {
+		// built-in templates are known to parse
+		if err := t.Set(kind, texts[0], texts[1]); err != nil {
+			panic(err)
+		}
+	}
+	return t
+}
+
+// Set parses and installs the subject and body templates for a kind
+func (t *Templates) Set(kind Kind, subject, body string) error {
+	st, err := template.New(string(kind) + "_subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return fmt.Errorf("notification: parse %s subject: %w", kind, err)
+	}
+	bt, err := template.New(string(kind) + "_body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return fmt.Errorf("notification: parse %s body: %w", kind, err)
+	}
+	t.byKind[kind] = messageTemplate{subject: st, body: bt}
+	return nil
+}
+
+// Render produces the subject and body for a kind
+func (t *Templates) Render(kind Kind, data TemplateData) (subject, body string, err error) {
+	mt, ok := t.byKind[kind]
+	if !ok {
+		return "", "", fmt.Errorf("notification: no template for %s", kind)
+	}
+
+	var sb, bb bytes.Buffer
+	if err := mt.subject.Execute(&sb, data); err != nil {
+		return "", "", fmt.Errorf("notification: render %s subject: %w", kind, err)
+	}
+	if err := mt.body.Execute(&bb, data); err != nil {
+		return "", "", fmt.Errorf("notification: render %s body: %w", kind, err)
+	}
+	return sb.String(), bb.String(), nil
+}
@@ -0,0 +1,174 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/events"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/repository"
+
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// seedRepo records n bids on item1
+func seedRepo(t *testing.T, repo *repository.MemoryRepo, from, n int) {
+	t.Helper()
+	for i := from; i < from+n; i++ {
+		require.NoError(t, repo.RecordBidForItem(context.Background(), model.Bid{
+			BidID:     fmt.Sprintf("bid%d", i),
+			ItemID:    "item1",
+			UserID:    "user1",
+			Amount:    float64(100 + i),
+			CreatedAt: time.Date(2025, 1, 1, 0, 0, i, 0, time.UTC),
+		}))
+	}
+}
+
+// offsets decodes the offsets of JSON change lines
+func offsets(t *testing.T, lines string) []uint64 {
+	t.Helper()
+	var out []uint64
+	for _, line := range strings.Split(strings.TrimSpace(lines), "\n") {
+		if line == "" {
+			continue
+		}
+		var c repository.Change
+		require.NoError(t, json.Unmarshal([]byte(line), &c))
+		out = append(out, c.Offset)
+	}
+	return out
+}
+
+func TestPublisher_WriterSink(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1"}))
+	seedRepo(t, repo, 0, 5)
+
+	var out syncBuffer
+	p := NewPublisher(Config{Feed: repo, Sink: NewWriterSink(&out), After: 2, BatchSize: 2, PollInterval: time.Hour})
+
+	// changes recorded later are forwarded when a bid event wakes the publisher
+	seedRepo(t, repo, 5, 2)
+	require.NoError(t, p.HandleEvent(context.Background(), events.BidPlaced{}))
+	require.Eventually(t, func() bool { return len(offsets(t, out.String())) == 5 }, 5*time.Second, 5*time.Millisecond)
+	p.Close()
+
+	require.Equal(t, []uint64{3, 4, 5, 6, 7}, offsets(t, out.String()))
+}
+
+// Test that a publisher behind the outbox retention skips the trimmed changes instead of stalling
+func TestPublisher_SkipsTrimmedChanges(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo(repository.WithOutboxRetention(2))
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1"}))
+	seedRepo(t, repo, 0, 4) // offsets 1 and 2 are trimmed
+
+	var out syncBuffer
+	p := NewPublisher(Config{Feed: repo, Sink: NewWriterSink(&out), PollInterval: time.Hour})
+	require.Eventually(t, func() bool { return len(offsets(t, out.String())) == 2 }, 5*time.Second, 5*time.Millisecond)
+	p.Close()
+
+	require.Equal(t, []uint64{3, 4}, offsets(t, out.String()))
+}
+
+func TestFileSink(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name       string
+		existing   string // file content before opening
+		wantLast   uint64
+		wantResume []uint64 // offsets in the file after publishing everything
+		wantError  bool
+	}{
+		{
+			name:       "new_file",
+			wantLast:   0,
+			wantResume: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:       "resumes_after_last_line",
+			existing:   `{"offset":1}` + "\n" + `{"offset":2}` + "\n",
+			wantLast:   2,
+			wantResume: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:       "torn_final_line_dropped",
+			existing:   `{"offset":1}` + "\n" + `{"offs`,
+			wantLast:   1,
+			wantResume: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:      "corrupt_line",
+			existing:  `not json` + "\n" + `{"offset":2}` + "\n",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			path := filepath.Join(t.TempDir(), "changes.jsonl")
+			if tc.existing != "" {
+				require.NoError(t, os.WriteFile(path, []byte(tc.existing), 0o644))
+			}
+
+			sink, err := OpenFileSink(path)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantLast, sink.LastOffset())
+
+			repo := repository.NewMemoryRepo()
+			require.NoError(t, repo.AddItem(model.Item{ItemID: "item1"}))
+			seedRepo(t, repo, 0, 4)
+
+			p := NewPublisher(Config{Feed: repo, Sink: sink, PollInterval: time.Hour})
+			p.Close() // Close forwards everything outstanding
+
+			// a replayed batch is not written twice
+			changes, err := repo.GetChanges(context.Background(), 0, 100)
+			require.NoError(t, err)
+			require.NoError(t, sink.Write(context.Background(), changes))
+			require.NoError(t, sink.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantResume, offsets(t, string(data)))
+		})
+	}
+}
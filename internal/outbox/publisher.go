@@ -0,0 +1,121 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/repository"
+	"bidding-tracker/utils"
+)
+
+// Config configures the outbox publisher
+type Config struct {
+	Feed         repository.ChangeFeed
+	Sink         Sink
+	After        uint64        // start after this offset; ignored when the sink is a Positioner
+	BatchSize    int           // changes read per GetChanges call, defaults to 500
+	PollInterval time.Duration // how often to poll when idle, defaults to one second
+}
+
+// Publisher forwards the repository outbox to a sink. It polls the change feed
+// and is nudged by domain events, so new bids are usually forwarded immediately.
+// Delivery is at least once; consumers deduplicate by offset unless the sink is a Positioner.
+type Publisher struct {
+	cfg   Config
+	after uint64 // last offset handed to the sink
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPublisher creates a publisher and starts forwarding in the background
+func NewPublisher(cfg Config) *Publisher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+
+	p := &Publisher{
+		cfg:   cfg,
+		after: cfg.After,
+		wake:  make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+	}
+	if pos, ok := cfg.Sink.(Positioner); ok {
+		p.after = pos.LastOffset()
+	}
+
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+// HandleEvent wakes the publisher; it never blocks, so it can be a synchronous bus subscriber
+func (p *Publisher) HandleEvent(_ context.Context, ev events.Event) error {
+	if ev.EventName() != events.NameBidPlaced {
+		return nil
+	}
+	select {
+	case p.wake <- struct{}{}:
+	default: // a wake-up is already pending
+	}
+	return nil
+}
+
+// Close forwards any remaining changes and stops the publisher
+func (p *Publisher) Close() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// run forwards changes until Close is called
+func (p *Publisher) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.drain()
+		select {
+		case <-p.stop:
+			p.drain()
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain forwards batches until the feed is caught up or an error occurs; failed batches are retried on the next wake-up
+func (p *Publisher) drain() {
+	ctx := context.Background()
+	for {
+		changes, err := p.cfg.Feed.GetChanges(ctx, p.after, p.cfg.BatchSize)
+		var trimmed *repository.TrimmedError
+		if errors.As(err, &trimmed) {
+			// the sink fell behind the outbox retention; skip what is gone rather than stall
+			utils.Error("Outbox: changes trimmed before they were forwarded", map[string]any{"after": p.after, "oldest": trimmed.Oldest, "lost": trimmed.Oldest - 1 - p.after})
+			p.after = trimmed.Oldest - 1
+			continue
+		}
+		if err != nil {
+			utils.Error("Outbox: read changes failed", map[string]any{"after": p.after, "error": err.Error()})
+			return
+		}
+		if len(changes) == 0 {
+			return
+		}
+		if err := p.cfg.Sink.Write(ctx, changes); err != nil {
+			utils.Error("Outbox: publish changes failed", map[string]any{"after": p.after, "count": len(changes), "error": err.Error()})
+			return
+		}
+		p.after = changes[len(changes)-1].Offset
+	}
+}
@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"bidding-tracker/internal/repository"
+)
+
+// Sink receives batches of outbox changes in offset order
+type Sink interface {
+	Write(ctx context.Context, changes []repository.Change) error
+}
+
+// Positioner is implemented by sinks that remember what they have already stored.
+// The publisher resumes after LastOffset, so such a sink never receives a change twice.
+type Positioner interface {
+	LastOffset() uint64
+}
+
+// WriterSink writes each change as a JSON line to an io.Writer such as stdout
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink that writes JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write appends one JSON line per change
+func (s *WriterSink) Write(_ context.Context, changes []repository.Change) error {
+	buf, err := encodeLines(changes)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(buf); err != nil {
+		return fmt.Errorf("outbox: write changes: %w", err)
+	}
+	return nil
+}
+
+// FileSink appends changes as JSON lines to a file and fsyncs every batch.
+// The file doubles as the checkpoint: on open, the offset of its last complete
+// line is where publishing resumes, and a torn final line is truncated away.
+type FileSink struct {
+	mu   sync.Mutex
+	f    *os.File
+	last uint64
+}
+
+// OpenFileSink opens (or creates) the change file at path and recovers its last offset
+func OpenFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: open %s: %w", path, err)
+	}
+
+	last, valid, err := scanChangeFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("outbox: truncate %s: %w", path, err)
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("outbox: seek %s: %w", path, err)
+	}
+	return &FileSink{f: f, last: last}, nil
+}
+
+// Write appends the changes not yet in the file and fsyncs
+func (s *FileSink) Write(_ context.Context, changes []repository.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// skip anything already stored so a replayed batch cannot duplicate lines
+	for len(changes) > 0 && changes[0].Offset <= s.last {
+		changes = changes[1:]
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	buf, err := encodeLines(changes)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(buf); err != nil {
+		return fmt.Errorf("outbox: write changes: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("outbox: fsync changes: %w", err)
+	}
+	s.last = changes[len(changes)-1].Offset
+	return nil
+}
+
+// LastOffset returns the offset of the last change stored in the file
+func (s *FileSink) LastOffset() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+// encodeLines renders changes as newline-terminated JSON
+func encodeLines(changes []repository.Change) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, c := range changes {
+		if err := enc.Encode(c); err != nil {
+			return nil, fmt.Errorf("outbox: encode change %d: %w", c.Offset, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// scanChangeFile returns the last offset in a change file and the length of its complete lines
+func scanChangeFile(r io.Reader) (last uint64, valid int64, err error) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return last, valid, nil // anything left is a torn final line
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("outbox: read change file: %w", err)
+		}
+
+		var c repository.Change
+		if err := json.Unmarshal(line, &c); err != nil {
+			return 0, 0, fmt.Errorf("outbox: corrupt change file at byte %d: %w", valid, err)
+		}
+		last = c.Offset
+		valid += int64(len(line))
+	}
+}
@@ -0,0 +1,138 @@
+// Package ratelimit limits request rates per client with token buckets. Each key (a user
+// or client IP) gets its own bucket that refills at a steady rate up to a burst size;
+// buckets of clients that went quiet are reclaimed.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// defaultSweepInterval is how often idle buckets are looked for
+const defaultSweepInterval = time.Minute
+
+// Limit is a sustained rate and the burst allowed on top of it. The zero Limit is unlimited.
+type Limit struct {
+	PerSecond float64 // tokens added per second
+	Burst     int     // bucket size: requests allowed at once after a quiet period
+}
+
+// Unlimited reports whether the limit lets every request through
+func (l Limit) Unlimited() bool {
+	return l.PerSecond <= 0 || l.Burst <= 0
+}
+
+// Config configures a Limiter
+type Config struct {
+	Limit         Limit
+	SweepInterval time.Duration    // how often idle buckets are reclaimed; 0 uses one minute
+	Now           func() time.Time // nil uses time.Now; for tests
+}
+
+// Decision is the outcome of one request against its bucket
+type Decision struct {
+	Allowed    bool
+	Limit      int           // burst size
+	Remaining  int           // whole tokens left after this request
+	RetryAfter time.Duration // when denied, how long until a token is available
+	Reset      time.Duration // how long until the bucket is full again
+}
+
+// bucket holds a key's tokens as of last
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter keeps one token bucket per key
+type Limiter struct {
+	limit Limit
+	sweep time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates a limiter. Idle buckets are reclaimed while requests arrive, so no
+// background goroutine needs stopping.
+func NewLimiter(cfg Config) *Limiter {
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = defaultSweepInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Limiter{
+		limit:     cfg.Limit,
+		sweep:     cfg.SweepInterval,
+		now:       cfg.Now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: cfg.Now(),
+	}
+}
+
+// Limit returns the limit applied to every key
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// Allow takes a token from key's bucket if one is available
+func (l *Limiter) Allow(key string) Decision {
+	if l.limit.Unlimited() {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.sweep {
+		l.reclaim(now)
+	}
+
+	burst := float64(l.limit.Burst)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.limit.PerSecond)
+	b.last = now
+
+	d := Decision{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.duration(burst - b.tokens)
+	return d
+}
+
+// Len returns the number of buckets held
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// reclaim drops the buckets that have refilled completely. A full bucket is the same as a
+// new one, so dropping it changes no decision.
+func (l *Limiter) reclaim(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.limit.PerSecond >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// duration returns how long refilling tokens takes
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.limit.PerSecond * float64(time.Second)))
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a manually advanced time source
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Test the burst, the refill rate and the reported headers' values
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	clk := newClock()
+	l := NewLimiter(Config{Limit: Limit{PerSecond: 2, Burst: 3}, Now: clk.Now})
+
+	for i, remaining := range []int{2, 1, 0} {
+		d := l.Allow("user1")
+		require.True(t, d.Allowed, "request %d is within the burst", i)
+		require.Equal(t, 3, d.Limit)
+		require.Equal(t, remaining, d.Remaining)
+	}
+
+	d := l.Allow("user1")
+	require.False(t, d.Allowed)
+	require.Equal(t, 0, d.Remaining)
+	require.Equal(t, 500*time.Millisecond, d.RetryAfter)
+	require.Equal(t, 1500*time.Millisecond, d.Reset)
+
+	require.True(t, l.Allow("user2").Allowed, "keys have separate buckets")
+
+	clk.Advance(250 * time.Millisecond)
+	d = l.Allow("user1")
+	require.False(t, d.Allowed)
+	require.Equal(t, 250*time.Millisecond, d.RetryAfter)
+
+	clk.Advance(250 * time.Millisecond)
+	require.True(t, l.Allow("user1").Allowed, "one token refilled after half a second")
+	require.False(t, l.Allow("user1").Allowed)
+
+	clk.Advance(time.Hour)
+	d = l.Allow("user1")
+	require.True(t, d.Allowed)
+	require.Equal(t, 2, d.Remaining, "the bucket never holds more than the burst")
+}
+
+// Test that the zero limit lets everything through without keeping buckets
+func TestLimiter_Unlimited(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	l := NewLimiter(Config{})
+	for i := 0; i < 100; i++ {
+		require.True(t, l.Allow("user1").Allowed)
+	}
+	require.Zero(t, l.Len())
+}
+
+// Test that buckets of quiet clients are reclaimed once full, and active ones are kept
+func TestLimiter_ReclaimsIdleBuckets(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	clk := newClock()
+	l := NewLimiter(Config{Limit: Limit{PerSecond: 1, Burst: 10}, SweepInterval: time.Second, Now: clk.Now})
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		l.Allow(ip)
+	}
+	for i := 0; i < 10; i++ {
+		l.Allow("bot")
+	}
+	require.Equal(t, 4, l.Len())
+
+	// one second later the single-request clients are full again; the bot is not
+	clk.Advance(time.Second)
+	l.Allow("user1")
+	require.Equal(t, 2, l.Len(), "only the bot and the new client remain")
+
+	clk.Advance(10 * time.Second)
+	l.Allow("user1")
+	require.Equal(t, 1, l.Len())
+
+	d := l.Allow("bot")
+	require.True(t, d.Allowed)
+	require.Equal(t, 9, d.Remaining, "a reclaimed bucket starts full")
+}
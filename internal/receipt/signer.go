@@ -0,0 +1,71 @@
+// Package receipt signs accepted bids so bidders can prove the server accepted them
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"bidding-tracker/client"
+	model "bidding-tracker/internal/models"
+)
+
+// Signer signs bid receipts with an Ed25519 key
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer for key
+func NewSigner(key ed25519.PrivateKey) (*Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("receipt: signing key is %d bytes, want %d", len(key), ed25519.PrivateKeySize)
+	}
+	return &Signer{key: key, keyID: client.KeyID(key.Public().(ed25519.PublicKey))}, nil
+}
+
+// KeyID identifies the signing key in the published key set
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign returns the signed receipt for an accepted bid
+func (s *Signer) Sign(bid model.Bid) client.Receipt {
+	r := client.Receipt{
+		BidID:     bid.BidID,
+		ItemID:    bid.ItemID,
+		UserID:    bid.UserID,
+		Amount:    bid.Amount,
+		CreatedAt: bid.CreatedAt.UTC(),
+		Sequence:  bid.Seq,
+		KeyID:     s.keyID,
+	}
+	r.Signature = base64.RawURLEncoding.EncodeToString(ed25519.Sign(s.key, r.SigningPayload()))
+	return r
+}
+
+// KeySet returns the public keys to publish for receipt verification
+func (s *Signer) KeySet() client.KeySet {
+	return client.KeySet{Keys: []client.PublicKey{client.NewPublicKey(s.key.Public().(ed25519.PublicKey))}}
+}
+
+// ParsePrivateKey reads a PEM-encoded PKCS #8 Ed25519 private key,
+// as written by `openssl genpkey -algorithm ed25519`
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("receipt: no PEM block found in signing key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("receipt: parse signing key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("receipt: signing key is %T, not Ed25519", key)
+	}
+	return priv, nil
+}
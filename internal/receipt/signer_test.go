@@ -0,0 +1,88 @@
+package receipt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"bidding-tracker/client"
+	model "bidding-tracker/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that signed receipts cover the bid and verify with the published key
+func TestSigner_Sign(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := NewSigner(key)
+	require.NoError(t, err)
+	require.Equal(t, client.KeyID(pub), signer.KeyID())
+
+	bid := model.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 120, CreatedAt: time.Now(), Seq: 2, Hash: "h"}
+	r := signer.Sign(bid)
+	require.Equal(t, client.Receipt{
+		BidID:     "bid1",
+		ItemID:    "item1",
+		UserID:    "user1",
+		Amount:    120,
+		CreatedAt: bid.CreatedAt.UTC(),
+		Sequence:  2,
+		KeyID:     signer.KeyID(),
+		Signature: r.Signature,
+	}, r)
+	require.NoError(t, client.VerifyReceipt(pub, r))
+	require.NoError(t, signer.KeySet().Verify(r))
+}
+
+// Test NewSigner and ParsePrivateKey input validation
+func TestParsePrivateKey(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(key any) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "ed25519", data: encode(edKey)},
+		{name: "ecdsa", data: encode(ecKey), wantErr: true},
+		{name: "not_pem", data: []byte("secret"), wantErr: true},
+		{name: "bad_der", data: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1, 2, 3}}), wantErr: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			key, err := ParsePrivateKey(tc.data)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, edKey, key)
+		})
+	}
+
+	_, err = NewSigner(edKey[:10])
+	require.Error(t, err)
+}
@@ -0,0 +1,534 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+)
+
+// FsyncPolicy controls when the write-ahead log is flushed to stable storage
+type FsyncPolicy string
+
+const (
+	FsyncAlways   FsyncPolicy = "always"   // fsync after every record
+	FsyncInterval FsyncPolicy = "interval" // fsync from a background ticker
+	FsyncNever    FsyncPolicy = "never"    // leave flushing to the OS
+)
+
+// FileRepoConfig configures the file-backed repository
+type FileRepoConfig struct {
+	Dir              string        // directory holding the WAL and snapshot files
+	Fsync            FsyncPolicy   // defaults to FsyncAlways
+	FsyncInterval    time.Duration // flush period for FsyncInterval, defaults to one second
+	SnapshotEvery    int           // write a snapshot after this many WAL records, 0 disables
+	SnapshotInterval time.Duration // write a snapshot on this period, 0 disables
+	OutboxRetention  int           // outbox changes kept in memory and in snapshots, 0 keeps all
+}
+
+// snapshot is the on-disk image of the repository state at WAL sequence Seq
+type snapshot struct {
+	Seq       uint64                 `json:"seq"`
+	Items     map[string]model.Item  `json:"items"`
+	Bids      map[string][]model.Bid `json:"bids"`
+	UserItems map[string][]string    `json:"user_items"`
+	Outbox    []Change               `json:"outbox,omitempty"`
+}
+
+// walFile is the WAL file handle; tests substitute one that fails
+type walFile interface {
+	io.Writer
+	io.Seeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+	Stat() (os.FileInfo, error)
+}
+
+// FileRepo is a durable AuctionDB that keeps its working set in a MemoryRepo
+// and logs every mutation to a checksummed write-ahead log before applying it.
+// State is recovered on startup from the latest snapshot plus the WAL tail.
+type FileRepo struct {
+	mem *MemoryRepo
+	cfg FileRepoConfig
+
+	mu        sync.Mutex // serializes WAL appends, snapshots and close
+	wal       walFile
+	offset    int64  // end of the last complete WAL record
+	seq       uint64 // sequence of the last logged record
+	sinceSnap int    // records appended since the last snapshot
+	dirty     bool   // records written but not yet fsynced
+	closed    bool
+	broken    error // set when a failed append could not be rolled back; later appends fail with it
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFileRepo opens (or creates) a file-backed repository in cfg.Dir and recovers its state
+func NewFileRepo(cfg FileRepoConfig) (*FileRepo, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("file repo: directory is required")
+	}
+	if cfg.Fsync == "" {
+		cfg.Fsync = FsyncAlways
+	}
+	if cfg.Fsync == FsyncInterval && cfg.FsyncInterval <= 0 {
+		cfg.FsyncInterval = time.Second
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("file repo: create directory %s: %w", cfg.Dir, err)
+	}
+
+	r := &FileRepo{
+		mem:  NewMemoryRepo(WithOutboxRetention(cfg.OutboxRetention)),
+		cfg:  cfg,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	if err := r.recover(); err != nil {
+		return nil, err
+	}
+
+	go r.run()
+
+	return r, nil
+}
+
+// recover loads the latest snapshot, replays the WAL tail and opens the WAL for appending
+func (r *FileRepo) recover() error {
+	snap, err := loadSnapshot(filepath.Join(r.cfg.Dir, snapshotFileName))
+	if err != nil {
+		return err
+	}
+	if snap != nil {
+		r.mem.restoreState(snap)
+		r.seq = snap.Seq
+	}
+
+	walPath := filepath.Join(r.cfg.Dir, walFileName)
+	replayed := 0
+	valid, err := readWAL(walPath, func(rec walRecord) error {
+		if rec.Seq <= r.seq {
+			return nil // already contained in the snapshot
+		}
+		if err := r.mem.apply(rec); err != nil {
+			return fmt.Errorf("file repo: replay record %d: %w", rec.Seq, err)
+		}
+		r.seq = rec.Seq
+		replayed++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(walPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("file repo: open wal: %w", err)
+	}
+
+	// drop a torn final record so new appends start on a record boundary
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return fmt.Errorf("file repo: truncate wal: %w", err)
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return fmt.Errorf("file repo: seek wal: %w", err)
+	}
+
+	r.wal = f
+	r.offset = valid
+	r.sinceSnap = replayed
+
+	utils.Info("FileRepo: state recovered", map[string]any{
+		"dir":              r.cfg.Dir,
+		"snapshot_seq":     snapshotSeq(snap),
+		"replayed_records": replayed,
+		"last_seq":         r.seq,
+	})
+	return nil
+}
+
+// RecordBidForItem logs the bid to the WAL and then applies it to the in-memory state
+func (r *FileRepo) RecordBidForItem(ctx context.Context, bid model.Bid) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// checked before logging, so the WAL never holds a bid that replay would reject
+	item, ok := r.mem.item(bid.ItemID)
+	if !ok {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, biddingerrors.ErrItemNotFound)
+	}
+	if item.ClosedAt != nil {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, biddingerrors.ErrAuctionClosed)
+	}
+
+	if err := r.appendLocked(walRecord{Type: walRecordBid, Bid: &bid}); err != nil {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, err)
+	}
+
+	// the record is durable, so the in-memory apply must not be interrupted by ctx
+	if err := r.mem.RecordBidForItem(context.Background(), bid); err != nil {
+		return err
+	}
+
+	r.maybeSnapshotLocked()
+	return nil
+}
+
+// CloseItem logs the close to the WAL and then ends bidding on the in-memory item
+func (r *FileRepo) CloseItem(ctx context.Context, itemID string, closedAt time.Time) (model.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// checked before logging, so the WAL never holds a close that replay would reject
+	item, ok := r.mem.item(itemID)
+	if !ok {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, biddingerrors.ErrItemNotFound)
+	}
+	if item.ClosedAt != nil {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, biddingerrors.ErrAuctionClosed)
+	}
+
+	closedAt = closedAt.UTC()
+	if err := r.appendLocked(walRecord{Type: walRecordClose, Item: &model.Item{ItemID: itemID, ClosedAt: &closedAt}}); err != nil {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, err)
+	}
+	item, err := r.mem.CloseItem(context.Background(), itemID, closedAt)
+	if err != nil {
+		return model.Item{}, err
+	}
+
+	r.maybeSnapshotLocked()
+	return item, nil
+}
+
+// AddItem logs the item to the WAL and then adds (or replaces) it in the in-memory state
+func (r *FileRepo) AddItem(item model.Item) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.appendLocked(walRecord{Type: walRecordItem, Item: &item}); err != nil {
+		return fmt.Errorf("add item %s: %w", item.ItemID, err)
+	}
+	r.mem.AddItem(item)
+
+	r.maybeSnapshotLocked()
+	return nil
+}
+
+// GetBidsByItem returns all bids for an item
+func (r *FileRepo) GetBidsByItem(ctx context.Context, itemID string) ([]model.Bid, error) {
+	return r.mem.GetBidsByItem(ctx, itemID)
+}
+
+// GetWinningBid returns the highest bid for an item
+func (r *FileRepo) GetWinningBid(ctx context.Context, itemID string) (model.Bid, error) {
+	return r.mem.GetWinningBid(ctx, itemID)
+}
+
+// GetItem returns an item
+func (r *FileRepo) GetItem(ctx context.Context, itemID string) (model.Item, error) {
+	return r.mem.GetItem(ctx, itemID)
+}
+
+// GetItemsByUser returns all items a user has bid on
+func (r *FileRepo) GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error) {
+	return r.mem.GetItemsByUser(ctx, userID)
+}
+
+// CountOpenItems returns the number of items whose auction is not closed
+func (r *FileRepo) CountOpenItems(ctx context.Context) (int, error) {
+	return r.mem.CountOpenItems(ctx)
+}
+
+// Snapshot writes the current state to disk and truncates the WAL
+func (r *FileRepo) Snapshot() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return biddingerrors.ErrStorageClosed
+	}
+	return r.snapshotLocked()
+}
+
+// Sync flushes any buffered WAL records to stable storage
+func (r *FileRepo) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return biddingerrors.ErrStorageClosed
+	}
+	return r.syncLocked()
+}
+
+// Ping reports whether the WAL is open and still accessible. Recovery completes before
+// NewFileRepo returns, so a FileRepo that answers has finished replaying its WAL.
+func (r *FileRepo) Ping(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return biddingerrors.ErrStorageClosed
+	}
+	if _, err := r.wal.Stat(); err != nil {
+		return fmt.Errorf("file repo: stat WAL: %w", err)
+	}
+	return nil
+}
+
+// Close stops background work, flushes the WAL and releases the file handle
+func (r *FileRepo) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	syncErr := r.syncLocked()
+	closeErr := r.wal.Close()
+	return errors.Join(syncErr, closeErr)
+}
+
+// appendLocked frames and writes a record to the WAL. The caller must hold r.mu.
+func (r *FileRepo) appendLocked(rec walRecord) error {
+	if r.closed {
+		return biddingerrors.ErrStorageClosed
+	}
+	if r.broken != nil {
+		return r.broken
+	}
+
+	rec.Seq = r.seq + 1
+	buf, err := encodeWALRecord(rec)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.wal.Write(buf); err != nil {
+		// roll back a partial write so the log stays on a record boundary
+		r.rollbackLocked()
+		return fmt.Errorf("wal: append record %d: %w", rec.Seq, err)
+	}
+
+	if r.cfg.Fsync == FsyncAlways {
+		if err := r.wal.Sync(); err != nil {
+			// the record is in the file but the bid is rejected, so remove it: otherwise the
+			// next append reuses its seq and replay keeps only one of the two
+			r.rollbackLocked()
+			return fmt.Errorf("wal: fsync record %d: %w", rec.Seq, err)
+		}
+	} else {
+		r.dirty = true
+	}
+
+	r.seq = rec.Seq
+	r.offset += int64(len(buf))
+	r.sinceSnap++
+	return nil
+}
+
+// rollbackLocked truncates the WAL back to the end of the last complete record. If that
+// fails the file no longer matches seq and offset, so the repository stops accepting
+// writes. The caller must hold r.mu.
+func (r *FileRepo) rollbackLocked() {
+	err := r.wal.Truncate(r.offset)
+	if err == nil {
+		_, err = r.wal.Seek(r.offset, io.SeekStart)
+	}
+	if err != nil {
+		r.broken = fmt.Errorf("wal: roll back to offset %d: %w", r.offset, err)
+		utils.Error("FileRepo: WAL rollback failed, refusing further writes", map[string]any{"dir": r.cfg.Dir, "error": err.Error()})
+	}
+}
+
+// syncLocked fsyncs the WAL if it has unflushed records. The caller must hold r.mu.
+func (r *FileRepo) syncLocked() error {
+	if !r.dirty {
+		return nil
+	}
+	if err := r.wal.Sync(); err != nil {
+		return fmt.Errorf("wal: fsync: %w", err)
+	}
+	r.dirty = false
+	return nil
+}
+
+// maybeSnapshotLocked writes a snapshot once SnapshotEvery records have accumulated
+func (r *FileRepo) maybeSnapshotLocked() {
+	if r.cfg.SnapshotEvery <= 0 || r.sinceSnap < r.cfg.SnapshotEvery {
+		return
+	}
+	if err := r.snapshotLocked(); err != nil {
+		// the WAL still holds every record, so a failed snapshot only delays compaction
+		utils.Error("FileRepo: snapshot failed", map[string]any{"dir": r.cfg.Dir, "error": err.Error()})
+	}
+}
+
+// snapshotLocked persists the in-memory state and truncates the WAL. The caller must hold r.mu.
+func (r *FileRepo) snapshotLocked() error {
+	snap := r.mem.exportState()
+	snap.Seq = r.seq
+
+	if err := writeSnapshot(r.cfg.Dir, snap); err != nil {
+		return err
+	}
+
+	// records up to snap.Seq are now covered by the snapshot; recovery skips any
+	// that survive a crash between the rename above and this truncate
+	if err := r.wal.Truncate(0); err != nil {
+		return fmt.Errorf("wal: truncate after snapshot: %w", err)
+	}
+	if _, err := r.wal.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("wal: seek after snapshot: %w", err)
+	}
+	if err := r.wal.Sync(); err != nil {
+		return fmt.Errorf("wal: fsync after snapshot: %w", err)
+	}
+
+	r.offset = 0
+	r.sinceSnap = 0
+	r.dirty = false
+
+	utils.Info("FileRepo: snapshot written", map[string]any{"dir": r.cfg.Dir, "seq": snap.Seq})
+	return nil
+}
+
+// run performs interval fsyncs and periodic snapshots until Close is called
+func (r *FileRepo) run() {
+	defer close(r.done)
+
+	var fsyncTick, snapTick <-chan time.Time
+	if r.cfg.Fsync == FsyncInterval {
+		t := time.NewTicker(r.cfg.FsyncInterval)
+		defer t.Stop()
+		fsyncTick = t.C
+	}
+	if r.cfg.SnapshotInterval > 0 {
+		t := time.NewTicker(r.cfg.SnapshotInterval)
+		defer t.Stop()
+		snapTick = t.C
+	}
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-fsyncTick:
+			if err := r.Sync(); err != nil && !errors.Is(err, biddingerrors.ErrStorageClosed) {
+				utils.Error("FileRepo: interval fsync failed", map[string]any{"dir": r.cfg.Dir, "error": err.Error()})
+			}
+		case <-snapTick:
+			r.mu.Lock()
+			if !r.closed && r.sinceSnap > 0 {
+				if err := r.snapshotLocked(); err != nil {
+					utils.Error("FileRepo: periodic snapshot failed", map[string]any{"dir": r.cfg.Dir, "error": err.Error()})
+				}
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+// loadSnapshot reads the snapshot at path, returning nil if none exists yet
+func loadSnapshot(path string) (*snapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file repo: read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("file repo: decode snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// writeSnapshot atomically replaces the snapshot file via write-to-temp, fsync and rename
+func writeSnapshot(dir string, snap *snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("file repo: encode snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, snapshotFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("file repo: create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("file repo: write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("file repo: fsync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("file repo: close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, snapshotFileName)); err != nil {
+		return fmt.Errorf("file repo: install snapshot: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir fsyncs a directory so a rename inside it survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("file repo: open directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("file repo: fsync directory: %w", err)
+	}
+	return nil
+}
+
+// snapshotSeq returns the sequence a snapshot covers, or 0 when there is none
+func snapshotSeq(snap *snapshot) uint64 {
+	if snap == nil {
+		return 0
+	}
+	return snap.Seq
+}
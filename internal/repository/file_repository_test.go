@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	model "bidding-tracker/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Helper to open a file repo and close it when the test ends
+func openFileRepo(t *testing.T, cfg FileRepoConfig) *FileRepo {
+	t.Helper()
+	repo, err := NewFileRepo(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+// Helper to seed a file repo with items and increasing bids
+func seedFileRepo(t *testing.T, repo *FileRepo, itemCount, bidsPerItem int) []model.Bid {
+	t.Helper()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var bids []model.Bid
+	for i := 0; i < itemCount; i++ {
+		itemID := fmt.Sprintf("item%d", i)
+		require.NoError(t, repo.AddItem(newItem(itemID, fmt.Sprintf("Item %d", i), 50)))
+		for j := 0; j < bidsPerItem; j++ {
+			b := newBid(fmt.Sprintf("bid-%d-%d", i, j), itemID, fmt.Sprintf("user%d", j), float64(100+j), base.Add(time.Duration(j)*time.Second))
+			require.NoError(t, repo.RecordBidForItem(context.Background(), b))
+			bids = append(bids, b)
+		}
+	}
+	return bids
+}
+
+// Helper to assert a recovered repo holds exactly the given bids
+func requireRecovered(t *testing.T, repo *FileRepo, itemCount int, want []model.Bid) {
+	t.Helper()
+	for i := 0; i < itemCount; i++ {
+		itemID := fmt.Sprintf("item%d", i)
+		var wantForItem []model.Bid
+		for _, b := range want {
+			if b.ItemID == itemID {
+				wantForItem = append(wantForItem, b)
+			}
+		}
+
+		bids, err := repo.GetBidsByItem(context.Background(), itemID)
+		require.NoError(t, err)
+		require.Equal(t, wantForItem, bids)
+
+		winning, err := repo.GetWinningBid(context.Background(), itemID)
+		require.NoError(t, err)
+		require.Equal(t, wantForItem[len(wantForItem)-1], winning)
+	}
+
+	items, err := repo.GetItemsByUser(context.Background(), "user0")
+	require.NoError(t, err)
+	require.Len(t, items, itemCount)
+}
+
+// Test recovery by replaying the WAL with each fsync policy
+func TestFileRepo_RecoverFromWAL(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name   string
+		policy FsyncPolicy
+	}{
+		{name: "fsync_always", policy: FsyncAlways},
+		{name: "fsync_interval", policy: FsyncInterval},
+		{name: "fsync_never", policy: FsyncNever},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			cfg := FileRepoConfig{Dir: t.TempDir(), Fsync: tc.policy, FsyncInterval: 10 * time.Millisecond}
+
+			repo, err := NewFileRepo(cfg)
+			require.NoError(t, err)
+			bids := seedFileRepo(t, repo, 3, 4)
+			require.NoError(t, repo.Close())
+
+			recovered := openFileRepo(t, cfg)
+			requireRecovered(t, recovered, 3, bids)
+		})
+	}
+}
+
+// Test that a close survives recovery from the WAL and from a snapshot
+func TestFileRepo_RecoverClosedItem(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name          string
+		snapshotEvery int
+	}{
+		{name: "wal", snapshotEvery: 0},
+		{name: "snapshot", snapshotEvery: 1},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			cfg := FileRepoConfig{Dir: t.TempDir(), SnapshotEvery: tc.snapshotEvery}
+			closedAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+			repo, err := NewFileRepo(cfg)
+			require.NoError(t, err)
+			bids := seedFileRepo(t, repo, 2, 2)
+			_, err = repo.CloseItem(context.Background(), "item0", closedAt)
+			require.NoError(t, err)
+			require.NoError(t, repo.Close())
+
+			recovered := openFileRepo(t, cfg)
+			requireRecovered(t, recovered, 2, bids)
+			err = recovered.RecordBidForItem(context.Background(), newBid("late", "item0", "user9", 500, closedAt))
+			require.ErrorIs(t, err, biddingerrors.ErrAuctionClosed)
+			require.NoError(t, recovered.RecordBidForItem(context.Background(), newBid("open", "item1", "user9", 500, closedAt)))
+		})
+	}
+}
+
+// Test recovery from a snapshot plus the WAL tail written after it
+func TestFileRepo_RecoverFromSnapshotAndTail(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg := FileRepoConfig{Dir: t.TempDir(), SnapshotEvery: 5}
+
+	repo, err := NewFileRepo(cfg)
+	require.NoError(t, err)
+	// 2 items + 6 bids = 8 records: one snapshot at record 5, three records in the tail
+	bids := seedFileRepo(t, repo, 2, 3)
+	require.NoError(t, repo.Close())
+
+	snap, err := loadSnapshot(filepath.Join(cfg.Dir, snapshotFileName))
+	require.NoError(t, err)
+	require.NotNil(t, snap)
+	require.Equal(t, uint64(5), snap.Seq)
+
+	var tail []uint64
+	_, err = readWAL(filepath.Join(cfg.Dir, walFileName), func(rec walRecord) error {
+		tail = append(tail, rec.Seq)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{6, 7, 8}, tail)
+
+	recovered := openFileRepo(t, cfg)
+	requireRecovered(t, recovered, 2, bids)
+
+	// Sequences keep increasing after recovery
+	require.NoError(t, recovered.RecordBidForItem(context.Background(), newBid("bid-after", "item0", "user9", 500, time.Now().UTC())))
+	require.Equal(t, uint64(9), recovered.seq)
+}
+
+// Test that an explicit snapshot survives a crash before the WAL is truncated
+func TestFileRepo_SnapshotSkipsReplayedRecords(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg := FileRepoConfig{Dir: t.TempDir()}
+	walPath := filepath.Join(cfg.Dir, walFileName)
+
+	repo, err := NewFileRepo(cfg)
+	require.NoError(t, err)
+	bids := seedFileRepo(t, repo, 1, 3)
+
+	// Keep a copy of the WAL as it was before the snapshot truncated it
+	walBefore, err := os.ReadFile(walPath)
+	require.NoError(t, err)
+	require.NoError(t, repo.Snapshot())
+	require.NoError(t, repo.Close())
+
+	// Simulate a crash between installing the snapshot and truncating the WAL
+	require.NoError(t, os.WriteFile(walPath, walBefore, 0o644))
+
+	recovered := openFileRepo(t, cfg)
+	requireRecovered(t, recovered, 1, bids)
+}
+
+// Test that a torn final record is dropped and the log stays writable
+func TestFileRepo_TornFinalRecord(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	frame, err := encodeWALRecord(walRecord{Seq: 100, Type: walRecordBid, Bid: &model.Bid{BidID: "torn", ItemID: "item0"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		torn []byte
+	}{
+		{name: "partial_header", torn: frame[:5]},
+		{name: "partial_payload", torn: frame[:len(frame)-3]},
+		{name: "bad_checksum", torn: append(append([]byte(nil), frame[:len(frame)-1]...), frame[len(frame)-1]^0xff)},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			cfg := FileRepoConfig{Dir: t.TempDir()}
+			walPath := filepath.Join(cfg.Dir, walFileName)
+
+			repo, err := NewFileRepo(cfg)
+			require.NoError(t, err)
+			bids := seedFileRepo(t, repo, 1, 2)
+			require.NoError(t, repo.Close())
+
+			f, err := os.OpenFile(walPath, os.O_APPEND|os.O_WRONLY, 0o644)
+			require.NoError(t, err)
+			_, err = f.Write(tc.torn)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			recovered, err := NewFileRepo(cfg)
+			require.NoError(t, err)
+			requireRecovered(t, recovered, 1, bids)
+
+			// New writes land after the last valid record and survive another restart
+			next := newBid("bid-next", "item0", "user5", 900, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, recovered.RecordBidForItem(context.Background(), next))
+			require.NoError(t, recovered.Close())
+
+			reopened := openFileRepo(t, cfg)
+			requireRecovered(t, reopened, 1, append(bids, next))
+		})
+	}
+}
+
+// Test that corruption before the final record is reported instead of silently dropping data
+func TestFileRepo_CorruptMiddleRecord(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	cfg := FileRepoConfig{Dir: t.TempDir()}
+	walPath := filepath.Join(cfg.Dir, walFileName)
+
+	repo, err := NewFileRepo(cfg)
+	require.NoError(t, err)
+	seedFileRepo(t, repo, 1, 3)
+	require.NoError(t, repo.Close())
+
+	data, err := os.ReadFile(walPath)
+	require.NoError(t, err)
+	data[walHeaderSize+2] ^= 0xff // flip a payload byte of the first record
+	require.NoError(t, os.WriteFile(walPath, data, 0o644))
+
+	_, err = NewFileRepo(cfg)
+	require.ErrorIs(t, err, errWALCorrupt)
+}
+
+// Test write-path errors
+func TestFileRepo_WriteErrors(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo, err := NewFileRepo(FileRepoConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+
+	err = repo.RecordBidForItem(context.Background(), newBid("bid1", "itemX", "user1", 100, time.Now()))
+	require.ErrorIs(t, err, biddingerrors.ErrItemNotFound)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = repo.RecordBidForItem(ctx, newBid("bid2", "item1", "user1", 100, time.Now()))
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, repo.Close())
+	require.NoError(t, repo.Close()) // idempotent
+
+	err = repo.RecordBidForItem(context.Background(), newBid("bid3", "item1", "user1", 100, time.Now()))
+	require.ErrorIs(t, err, biddingerrors.ErrStorageClosed)
+	require.ErrorIs(t, repo.AddItem(newItem("item2", "Item 2", 50)), biddingerrors.ErrStorageClosed)
+}
+
+// faultyWAL fails Sync and, optionally, Truncate while its flags are set
+type faultyWAL struct {
+	walFile
+	failSync     bool
+	failTruncate bool
+}
+
+var errInjected = errors.New("injected I/O error")
+
+func (f *faultyWAL) Sync() error {
+	if f.failSync {
+		return errInjected
+	}
+	return f.walFile.Sync()
+}
+
+func (f *faultyWAL) Truncate(size int64) error {
+	if f.failTruncate {
+		return errInjected
+	}
+	return f.walFile.Truncate(size)
+}
+
+// Test that a record whose fsync failed is removed from the WAL, so later records keep
+// unique sequence numbers and recovery sees only the accepted bids
+func TestFileRepo_FsyncFailure(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	dir := t.TempDir()
+	repo, err := NewFileRepo(FileRepoConfig{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddItem(newItem("item0", "Item 0", 50)))
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	accepted := newBid("bid1", "item0", "user0", 100, base)
+	require.NoError(t, repo.RecordBidForItem(context.Background(), accepted))
+
+	faulty := &faultyWAL{walFile: repo.wal, failSync: true}
+	repo.wal = faulty
+	err = repo.RecordBidForItem(context.Background(), newBid("bid2", "item0", "user2", 200, base.Add(time.Second)))
+	require.ErrorIs(t, err, errInjected)
+
+	faulty.failSync = false
+	retried := newBid("bid3", "item0", "user1", 150, base.Add(2*time.Second))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), retried))
+	require.NoError(t, repo.Close())
+
+	reopened := openFileRepo(t, FileRepoConfig{Dir: dir})
+	requireRecovered(t, reopened, 1, []model.Bid{accepted, retried})
+}
+
+// Test that the repository refuses writes once a failed record cannot be rolled back
+func TestFileRepo_FsyncFailureWithoutRollback(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := openFileRepo(t, FileRepoConfig{Dir: t.TempDir()})
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+
+	faulty := &faultyWAL{walFile: repo.wal, failSync: true, failTruncate: true}
+	repo.wal = faulty
+	err := repo.RecordBidForItem(context.Background(), newBid("bid1", "item1", "user1", 100, time.Now()))
+	require.ErrorIs(t, err, errInjected)
+
+	faulty.failSync, faulty.failTruncate = false, false
+	err = repo.RecordBidForItem(context.Background(), newBid("bid2", "item1", "user1", 100, time.Now()))
+	require.ErrorIs(t, err, errInjected, "the WAL no longer matches the in-memory state")
+	require.ErrorIs(t, repo.AddItem(newItem("item2", "Item 2", 50)), errInjected)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"bidding-tracker/internal/metrics"
+)
+
+// lockWait measures how long MemoryRepo operations wait for an item's lock, by operation
+var lockWait = metrics.Default.NewHistogramVec("repository_lock_wait_seconds",
+	"Time spent waiting for an item lock in the in-memory repository, by operation.",
+	[]float64{1e-6, 1e-5, 1e-4, 1e-3, .01, .1, 1}, "op")
+
+var (
+	recordLockWait  = lockWait.WithLabelValues("record_bid")
+	bidsLockWait    = lockWait.WithLabelValues("get_bids")
+	winningLockWait = lockWait.WithLabelValues("get_winning_bid")
+)
+
+// lock takes the entry's write lock, recording the wait in h
+func (e *itemEntry) lock(h *metrics.Histogram) {
+	start := time.Now()
+	e.mu.Lock()
+	h.Observe(time.Since(start).Seconds())
+}
+
+// rlock takes the entry's read lock, recording the wait in h
+func (e *itemEntry) rlock(h *metrics.Histogram) {
+	start := time.Now()
+	e.mu.RLock()
+	h.Observe(time.Since(start).Seconds())
+}
+
+// txRetries counts SQLRepo transactions run again after a serialization conflict, by operation
+var txRetries = metrics.Default.NewCounterVec("repository_sql_tx_retries_total",
+	"SQL transactions run again after losing a serialization conflict to a concurrent one, by operation.", "op")
+
+var (
+	recordTxRetries = txRetries.WithLabelValues("record_bid")
+	closeTxRetries  = txRetries.WithLabelValues("close_item")
+)
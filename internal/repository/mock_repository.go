@@ -6,7 +6,9 @@ package repository
 
 import (
 	models "bidding-tracker/internal/models"
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,61 +36,91 @@ func (m *MockAuctionDB) EXPECT() *MockAuctionDBMockRecorder {
 	return m.recorder
 }
 
+// CloseItem mocks base method.
+func (m *MockAuctionDB) CloseItem(ctx context.Context, itemID string, closedAt time.Time) (models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseItem", ctx, itemID, closedAt)
+	ret0, _ := ret[0].(models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseItem indicates an expected call of CloseItem.
+func (mr *MockAuctionDBMockRecorder) CloseItem(ctx, itemID, closedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseItem", reflect.TypeOf((*MockAuctionDB)(nil).CloseItem), ctx, itemID, closedAt)
+}
+
 // GetBidsByItem mocks base method.
-func (m *MockAuctionDB) GetBidsByItem(itemID string) ([]models.Bid, error) {
+func (m *MockAuctionDB) GetBidsByItem(ctx context.Context, itemID string) ([]models.Bid, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBidsByItem", itemID)
+	ret := m.ctrl.Call(m, "GetBidsByItem", ctx, itemID)
 	ret0, _ := ret[0].([]models.Bid)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBidsByItem indicates an expected call of GetBidsByItem.
-func (mr *MockAuctionDBMockRecorder) GetBidsByItem(itemID interface{}) *gomock.Call {
+func (mr *MockAuctionDBMockRecorder) GetBidsByItem(ctx, itemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBidsByItem", reflect.TypeOf((*MockAuctionDB)(nil).GetBidsByItem), ctx, itemID)
+}
+
+// GetItem mocks base method.
+func (m *MockAuctionDB) GetItem(ctx context.Context, itemID string) (models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, itemID)
+	ret0, _ := ret[0].(models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockAuctionDBMockRecorder) GetItem(ctx, itemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBidsByItem", reflect.TypeOf((*MockAuctionDB)(nil).GetBidsByItem), itemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockAuctionDB)(nil).GetItem), ctx, itemID)
 }
 
 // GetItemsByUser mocks base method.
-func (m *MockAuctionDB) GetItemsByUser(userID string) ([]models.Item, error) {
+func (m *MockAuctionDB) GetItemsByUser(ctx context.Context, userID string) ([]models.Item, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetItemsByUser", userID)
+	ret := m.ctrl.Call(m, "GetItemsByUser", ctx, userID)
 	ret0, _ := ret[0].([]models.Item)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetItemsByUser indicates an expected call of GetItemsByUser.
-func (mr *MockAuctionDBMockRecorder) GetItemsByUser(userID interface{}) *gomock.Call {
+func (mr *MockAuctionDBMockRecorder) GetItemsByUser(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByUser", reflect.TypeOf((*MockAuctionDB)(nil).GetItemsByUser), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByUser", reflect.TypeOf((*MockAuctionDB)(nil).GetItemsByUser), ctx, userID)
 }
 
 // GetWinningBid mocks base method.
-func (m *MockAuctionDB) GetWinningBid(itemID string) (models.Bid, error) {
+func (m *MockAuctionDB) GetWinningBid(ctx context.Context, itemID string) (models.Bid, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWinningBid", itemID)
+	ret := m.ctrl.Call(m, "GetWinningBid", ctx, itemID)
 	ret0, _ := ret[0].(models.Bid)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWinningBid indicates an expected call of GetWinningBid.
-func (mr *MockAuctionDBMockRecorder) GetWinningBid(itemID interface{}) *gomock.Call {
+func (mr *MockAuctionDBMockRecorder) GetWinningBid(ctx, itemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWinningBid", reflect.TypeOf((*MockAuctionDB)(nil).GetWinningBid), itemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWinningBid", reflect.TypeOf((*MockAuctionDB)(nil).GetWinningBid), ctx, itemID)
 }
 
 // RecordBidForItem mocks base method.
-func (m *MockAuctionDB) RecordBidForItem(bid models.Bid) error {
+func (m *MockAuctionDB) RecordBidForItem(ctx context.Context, bid models.Bid) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RecordBidForItem", bid)
+	ret := m.ctrl.Call(m, "RecordBidForItem", ctx, bid)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RecordBidForItem indicates an expected call of RecordBidForItem.
-func (mr *MockAuctionDBMockRecorder) RecordBidForItem(bid interface{}) *gomock.Call {
+func (mr *MockAuctionDBMockRecorder) RecordBidForItem(ctx, bid interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordBidForItem", reflect.TypeOf((*MockAuctionDB)(nil).RecordBidForItem), bid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordBidForItem", reflect.TypeOf((*MockAuctionDB)(nil).RecordBidForItem), ctx, bid)
 }
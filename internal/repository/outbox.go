@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	model "bidding-tracker/internal/models"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ChangeType identifies the kind of change recorded in the outbox
+type ChangeType string
+
+const (
+	ChangeBidRecorded ChangeType = "bid_recorded"
+)
+
+// Change is one outbox entry. Offsets start at 1, increase by one per change and
+// are never reused, so a consumer resumes by asking for the changes after the last offset it processed.
+type Change struct {
+	Offset uint64     `json:"offset"`
+	Type   ChangeType `json:"type"`
+	ItemID string     `json:"item_id"`
+	Bid    model.Bid  `json:"bid"`
+}
+
+// ChangeFeed reads the outbox in offset order
+type ChangeFeed interface {
+	// GetChanges returns up to limit changes with an offset greater than after.
+	// An empty result means the consumer is caught up. When changes after the offset
+	// were trimmed by retention it fails with a *TrimmedError.
+	GetChanges(ctx context.Context, after uint64, limit int) ([]Change, error)
+}
+
+// TrimmedError reports that a consumer asked for changes the outbox no longer retains.
+// It wraps biddingerrors.ErrChangesTrimmed; the consumer resyncs and resumes after Oldest-1.
+type TrimmedError struct {
+	After  uint64 // the requested offset
+	Oldest uint64 // the oldest offset still retained
+}
+
+func (e *TrimmedError) Error() string {
+	return fmt.Sprintf("changes after %d requested, oldest retained is %d: %v", e.After, e.Oldest, biddingerrors.ErrChangesTrimmed)
+}
+
+func (e *TrimmedError) Unwrap() error { return biddingerrors.ErrChangesTrimmed }
+
+// outbox is the in-memory change log kept by MemoryRepo. With a retention it keeps at
+// least the latest retain changes, dropping the older ones in batches once twice as
+// many have accumulated, so trimming costs O(1) per change.
+type outbox struct {
+	mu      sync.RWMutex
+	retain  int      // changes kept; 0 keeps every change
+	trimmed uint64   // changes dropped so far
+	changes []Change // changes[i].Offset == trimmed+i+1
+}
+
+// appendBid records a bid change and returns its offset
+func (o *outbox) appendBid(bid model.Bid) uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	offset := o.trimmed + uint64(len(o.changes)) + 1
+	o.changes = append(o.changes, Change{Offset: offset, Type: ChangeBidRecorded, ItemID: bid.ItemID, Bid: bid})
+	if o.retain > 0 && len(o.changes) >= 2*o.retain {
+		drop := len(o.changes) - o.retain
+		o.changes = append([]Change(nil), o.changes[drop:]...)
+		o.trimmed += uint64(drop)
+	}
+	return offset
+}
+
+// read returns up to limit changes after an offset
+func (o *outbox) read(after uint64, limit int) ([]Change, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if after < o.trimmed {
+		return nil, &TrimmedError{After: after, Oldest: o.trimmed + 1}
+	}
+	from := after - o.trimmed
+	if from >= uint64(len(o.changes)) || limit <= 0 {
+		return nil, nil
+	}
+	end := min(from+uint64(limit), uint64(len(o.changes)))
+	return append([]Change(nil), o.changes[from:end]...), nil
+}
+
+// export returns a copy of every change for snapshotting
+func (o *outbox) export() []Change {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return append([]Change(nil), o.changes...)
+}
+
+// restore replaces the log with snapshotted changes, which may have been trimmed
+func (o *outbox) restore(changes []Change) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.changes = append([]Change(nil), changes...)
+	o.trimmed = 0
+	if len(changes) > 0 {
+		o.trimmed = changes[0].Offset - 1
+	}
+}
+
+// GetChanges returns up to limit outbox changes after an offset
+func (r *MemoryRepo) GetChanges(ctx context.Context, after uint64, limit int) ([]Change, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("get changes after %d: %w", after, err)
+	}
+	changes, err := r.changes.read(after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get changes after %d: %w", after, err)
+	}
+	return changes, nil
+}
+
+// GetChanges returns up to limit outbox changes after an offset
+func (r *FileRepo) GetChanges(ctx context.Context, after uint64, limit int) ([]Change, error) {
+	return r.mem.GetChanges(ctx, after, limit)
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	model "bidding-tracker/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test GetChanges paging and resume across restarts on every backend
+func TestStore_GetChanges(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		open func(t *testing.T) (open func() Store)
+	}{
+		{
+			name: "memory",
+			open: func(t *testing.T) func() Store {
+				repo := NewMemoryRepo()
+				return func() Store { return repo }
+			},
+		},
+		{
+			name: "file",
+			open: func(t *testing.T) func() Store {
+				cfg := FileRepoConfig{Dir: t.TempDir(), SnapshotEvery: 4} // restarts recover from a snapshot plus the WAL tail
+				return func() Store { return openFileRepo(t, cfg) }
+			},
+		},
+		{
+			name: "sql",
+			open: func(t *testing.T) func() Store {
+				_, dsn := openSQLRepo(t)
+				return func() Store {
+					db, err := sql.Open("sqlite3", dsn)
+					require.NoError(t, err)
+					repo, err := NewSQLRepo(context.Background(), db, "sqlite3")
+					require.NoError(t, err)
+					t.Cleanup(func() { _ = repo.Close() })
+					return repo
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			ctx := context.Background()
+			open := tc.open(t)
+			store := open()
+
+			base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			require.NoError(t, store.AddItem(newItem("item1", "Item 1", 10)))
+			require.NoError(t, store.AddItem(newItem("item2", "Item 2", 10)))
+
+			var bids []model.Bid
+			seqs := map[string]uint64{}
+			for i := 0; i < 5; i++ {
+				bid := newBid(fmt.Sprintf("bid%d", i), fmt.Sprintf("item%d", i%2+1), "user1", float64(100+i), base.Add(time.Duration(i)*time.Second))
+				seqs[bid.ItemID]++
+				bid.Seq = seqs[bid.ItemID] // numbered as the service does
+				require.NoError(t, store.RecordBidForItem(ctx, bid))
+				bids = append(bids, bid)
+			}
+			// a rejected bid leaves no change behind
+			require.Error(t, store.RecordBidForItem(ctx, newBid("bid-x", "itemX", "user1", 100, base)))
+
+			// page through in twos
+			var got []Change
+			var after uint64
+			for {
+				page, err := store.GetChanges(ctx, after, 2)
+				require.NoError(t, err)
+				if len(page) == 0 {
+					break
+				}
+				require.LessOrEqual(t, len(page), 2)
+				got = append(got, page...)
+				after = page[len(page)-1].Offset
+			}
+
+			require.Len(t, got, len(bids))
+			for i, c := range got {
+				require.Equal(t, uint64(i+1), c.Offset)
+				require.Equal(t, ChangeBidRecorded, c.Type)
+				require.Equal(t, bids[i].ItemID, c.ItemID)
+				require.Equal(t, bids[i], c.Bid)
+			}
+
+			// after a restart the feed is unchanged and offsets continue
+			require.NoError(t, store.Close())
+			restarted := open()
+			again, err := restarted.GetChanges(ctx, 0, 100)
+			require.NoError(t, err)
+			require.Equal(t, got, again)
+
+			next := newBid("bid-next", "item1", "user2", 500, base.Add(time.Minute))
+			next.Seq = seqs[next.ItemID] + 1
+			require.NoError(t, restarted.RecordBidForItem(ctx, next))
+			tail, err := restarted.GetChanges(ctx, uint64(len(bids)), 100)
+			require.NoError(t, err)
+			require.Len(t, tail, 1)
+			require.Equal(t, uint64(len(bids)+1), tail[0].Offset)
+			require.Equal(t, next, tail[0].Bid)
+		})
+	}
+}
+
+// Test that retention bounds the outbox, reports trimmed offsets and survives a restart
+func TestOutbox_Retention(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		open func(t *testing.T) (open func() Store)
+	}{
+		{
+			name: "memory",
+			open: func(t *testing.T) func() Store {
+				repo := NewMemoryRepo(WithOutboxRetention(3))
+				return func() Store { return repo }
+			},
+		},
+		{
+			name: "file_snapshot",
+			open: func(t *testing.T) func() Store {
+				cfg := FileRepoConfig{Dir: t.TempDir(), SnapshotEvery: 4, OutboxRetention: 3}
+				return func() Store { return openFileRepo(t, cfg) }
+			},
+		},
+		{
+			name: "file_wal",
+			open: func(t *testing.T) func() Store {
+				cfg := FileRepoConfig{Dir: t.TempDir(), OutboxRetention: 3}
+				return func() Store { return openFileRepo(t, cfg) }
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			ctx := context.Background()
+			open := tc.open(t)
+			store := open()
+			base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			require.NoError(t, store.AddItem(newItem("item1", "Item 1", 10)))
+
+			record := func(s Store, from, n int) {
+				for i := from; i < from+n; i++ {
+					require.NoError(t, s.RecordBidForItem(ctx, newBid(fmt.Sprintf("bid%d", i), "item1", "user1", float64(100+i), base.Add(time.Duration(i)*time.Second))))
+				}
+			}
+			// the sixth change trims the log back to the latest three
+			record(store, 0, 6)
+
+			_, err := store.GetChanges(ctx, 2, 10)
+			var trimmed *TrimmedError
+			require.ErrorAs(t, err, &trimmed)
+			require.ErrorIs(t, err, biddingerrors.ErrChangesTrimmed)
+			require.Equal(t, uint64(4), trimmed.Oldest)
+
+			changes, err := store.GetChanges(ctx, 3, 10)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{4, 5, 6}, changeOffsets(changes))
+
+			require.NoError(t, store.Close())
+			restarted := open()
+			record(restarted, 6, 1)
+			changes, err = restarted.GetChanges(ctx, 3, 10)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{4, 5, 6, 7}, changeOffsets(changes), "offsets continue after a restart")
+		})
+	}
+}
+
+// changeOffsets returns the offsets of a list of changes
+func changeOffsets(changes []Change) []uint64 {
+	out := make([]uint64, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, c.Offset)
+	}
+	return out
+}
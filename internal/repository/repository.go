@@ -3,109 +3,415 @@ package repository
 import (
 	"bidding-tracker/internal/biddingerrors"
 	model "bidding-tracker/internal/models"
+	"context"
 	"fmt"
+	"hash/fnv"
 	"sync"
+	"time"
 )
 
 // AuctionDB defines the bid storage interface for the auction system
 type AuctionDB interface {
-	RecordBidForItem(bid model.Bid) error
-	GetBidsByItem(itemID string) ([]model.Bid, error)
-	GetWinningBid(itemID string) (model.Bid, error)
-	GetItemsByUser(userID string) ([]model.Item, error)
+	RecordBidForItem(ctx context.Context, bid model.Bid) error
+	GetBidsByItem(ctx context.Context, itemID string) ([]model.Bid, error)
+	GetWinningBid(ctx context.Context, itemID string) (model.Bid, error)
+	GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error)
+	GetItem(ctx context.Context, itemID string) (model.Item, error)
+	// CloseItem ends bidding on an item; later bids fail with ErrAuctionClosed
+	CloseItem(ctx context.Context, itemID string, closedAt time.Time) (model.Item, error)
 }
 
-// MemoryRepo is a concurrency-safe in-memory implementation of AuctionDB
-type MemoryRepo struct {
+// cancelCheckInterval is how many elements a scan processes between context cancellation checks
+const cancelCheckInterval = 1024
+
+// userIndexShards is the number of independently locked shards of the user index
+const userIndexShards = 32
+
+// itemEntry holds one item and its bids behind the item's own lock
+type itemEntry struct {
+	mu      sync.RWMutex
+	item    model.Item
+	bids    []model.Bid
+	leader  model.Bid // current winning bid, maintained on every append
+	hasBids bool
+}
+
+// appendBid records a bid and updates the leader. The caller must hold e.mu.
+func (e *itemEntry) appendBid(bid model.Bid) {
+	e.bids = append(e.bids, bid)
+	if !e.hasBids || outbids(bid, e.leader) {
+		e.leader = bid
+		e.hasBids = true
+	}
+}
+
+// outbids reports whether bid b beats the current leader: a higher amount wins,
+// and on equal amounts the earlier bid wins
+func outbids(b, leader model.Bid) bool {
+	return b.Amount > leader.Amount || (b.Amount == leader.Amount && b.CreatedAt.Before(leader.CreatedAt))
+}
+
+// userItemSet is the ordered, de-duplicated set of items a user has bid on
+type userItemSet struct {
+	order []string            // itemIDs in the order of the user's first bid
+	seen  map[string]struct{} // membership index over order
+}
+
+// add inserts an itemID if it is not already present
+func (s *userItemSet) add(itemID string) {
+	if _, ok := s.seen[itemID]; ok {
+		return
+	}
+	s.seen[itemID] = struct{}{}
+	s.order = append(s.order, itemID)
+}
+
+// newUserItemSet builds a set from an ordered list of itemIDs
+func newUserItemSet(itemIDs []string) *userItemSet {
+	s := &userItemSet{seen: make(map[string]struct{}, len(itemIDs))}
+	for _, id := range itemIDs {
+		s.add(id)
+	}
+	return s
+}
+
+// userShard is one lock-striped partition of the user -> items index
+type userShard struct {
 	mu        sync.RWMutex
-	bids      map[string][]model.Bid // key: itemID -> value: list of bids
-	items     map[string]model.Item  // key: itemID -> value: item
-	userItems map[string][]string    // key: userID -> value: list of itemIDs user has bid on
+	userItems map[string]*userItemSet // key: userID -> value: set of itemIDs user has bid on
+}
+
+// MemoryRepo is a concurrency-safe in-memory implementation of AuctionDB.
+// Each item has its own lock, so writes to different items proceed in parallel;
+// the user index is striped across shards with separate locks.
+type MemoryRepo struct {
+	itemsMu sync.RWMutex          // guards the items map itself, not the entries
+	items   map[string]*itemEntry // key: itemID -> value: item and its bids
+
+	users [userIndexShards]userShard
+
+	changes outbox // bid changes in recording order, read by GetChanges
 }
 
 // NewMemoryRepo creates a new in-memory repository instance
-func NewMemoryRepo() *MemoryRepo {
-	return &MemoryRepo{
-		bids:      make(map[string][]model.Bid),
-		items:     make(map[string]model.Item),
-		userItems: make(map[string][]string),
+func NewMemoryRepo(opts ...MemoryOption) *MemoryRepo {
+	r := &MemoryRepo{
+		items: make(map[string]*itemEntry),
 	}
+	for i := range r.users {
+		r.users[i].userItems = make(map[string]*userItemSet)
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// MemoryOption configures optional MemoryRepo behaviour
+type MemoryOption func(*MemoryRepo)
+
+// WithOutboxRetention keeps at least the latest n outbox changes and drops older ones;
+// consumers that fall further behind get a *TrimmedError. 0 keeps every change.
+func WithOutboxRetention(n int) MemoryOption {
+	return func(r *MemoryRepo) {
+		r.changes.retain = max(n, 0)
+	}
+}
+
+// entry returns the entry for an item, or nil if the item does not exist
+func (r *MemoryRepo) entry(itemID string) *itemEntry {
+	r.itemsMu.RLock()
+	defer r.itemsMu.RUnlock()
+	return r.items[itemID]
+}
+
+// userShard returns the index shard responsible for a user
+func (r *MemoryRepo) userShard(userID string) *userShard {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return &r.users[h.Sum32()%userIndexShards]
 }
 
 // RecordBidForItem records a user's bid on an item
-func (r *MemoryRepo) RecordBidForItem(bid model.Bid) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *MemoryRepo) RecordBidForItem(ctx context.Context, bid model.Bid) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, err)
+	}
 
-	if _, ok := r.items[bid.ItemID]; !ok {
+	e := r.entry(bid.ItemID)
+	if e == nil {
 		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, biddingerrors.ErrItemNotFound)
 	}
 
-	r.bids[bid.ItemID] = append(r.bids[bid.ItemID], bid)
+	e.lock(recordLockWait)
+	defer e.mu.Unlock()
 
-	for _, id := range r.userItems[bid.UserID] {
-		if id == bid.ItemID {
-			return nil
-		}
+	if e.item.ClosedAt != nil {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, biddingerrors.ErrAuctionClosed)
+	}
+	e.appendBid(bid)
+	// logged under the item lock, so each item's changes are in recording order
+	r.changes.appendBid(bid)
+
+	// updated while the item lock is held so readers never see the bid without its index entry
+	// (lock order is always item -> user shard)
+	shard := r.userShard(bid.UserID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	set, ok := shard.userItems[bid.UserID]
+	if !ok {
+		set = newUserItemSet(nil)
+		shard.userItems[bid.UserID] = set
 	}
-	r.userItems[bid.UserID] = append(r.userItems[bid.UserID], bid.ItemID)
+	set.add(bid.ItemID)
 
 	return nil
 }
 
 // GetBidsByItem returns all bids for an item
-func (r *MemoryRepo) GetBidsByItem(itemID string) ([]model.Bid, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *MemoryRepo) GetBidsByItem(ctx context.Context, itemID string) ([]model.Bid, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("get bids for item %s: %w", itemID, err)
+	}
 
-	bids, ok := r.bids[itemID]
-	if !ok || len(bids) == 0 {
+	e := r.entry(itemID)
+	if e == nil {
 		return nil, fmt.Errorf("get bids for item %s: %w", itemID, biddingerrors.ErrNoBids)
 	}
-	return append([]model.Bid(nil), bids...), nil
+
+	e.rlock(bidsLockWait)
+	defer e.mu.RUnlock()
+
+	if len(e.bids) == 0 {
+		return nil, fmt.Errorf("get bids for item %s: %w", itemID, biddingerrors.ErrNoBids)
+	}
+	return append([]model.Bid(nil), e.bids...), nil
 }
 
 // GetWinningBid returns the highest bid for an item
-func (r *MemoryRepo) GetWinningBid(itemID string) (model.Bid, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *MemoryRepo) GetWinningBid(ctx context.Context, itemID string) (model.Bid, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Bid{}, fmt.Errorf("get winning bid for item %s: %w", itemID, err)
+	}
 
-	bids, ok := r.bids[itemID]
-	if !ok || len(bids) == 0 {
+	e := r.entry(itemID)
+	if e == nil {
 		return model.Bid{}, fmt.Errorf("get winning bid for item %s: %w", itemID, biddingerrors.ErrNoBids)
 	}
 
-	winning := bids[0]
-	for _, b := range bids[1:] {
-		if b.Amount > winning.Amount || (b.Amount == winning.Amount && b.CreatedAt.Before(winning.CreatedAt)) {
-			winning = b
-		}
+	e.rlock(winningLockWait)
+	defer e.mu.RUnlock()
+
+	if !e.hasBids {
+		return model.Bid{}, fmt.Errorf("get winning bid for item %s: %w", itemID, biddingerrors.ErrNoBids)
 	}
-	return winning, nil
+	return e.leader, nil
 }
 
 // GetItemsByUser returns all items a user has bid on
-func (r *MemoryRepo) GetItemsByUser(userID string) ([]model.Item, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *MemoryRepo) GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("get items for user %s: %w", userID, err)
+	}
+
+	shard := r.userShard(userID)
+	shard.mu.RLock()
+	var itemIDs []string
+	if set, ok := shard.userItems[userID]; ok {
+		itemIDs = append(itemIDs, set.order...)
+	}
+	shard.mu.RUnlock()
 
-	itemIDs, ok := r.userItems[userID]
-	if !ok || len(itemIDs) == 0 {
+	if len(itemIDs) == 0 {
 		return nil, fmt.Errorf("get items for user %s: %w", userID, biddingerrors.ErrUserNoBids)
 	}
 
 	items := make([]model.Item, 0, len(itemIDs))
-	for _, id := range itemIDs {
-		if item, exists := r.items[id]; exists {
-			items = append(items, item)
+	for i, id := range itemIDs {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("get items for user %s: %w", userID, err)
+			}
+		}
+		if e := r.entry(id); e != nil {
+			e.mu.RLock()
+			items = append(items, e.item)
+			e.mu.RUnlock()
 		}
 	}
 	return items, nil
 }
 
-// AddItem adds an item to the repository. This method is intended for tests only.
-func (r *MemoryRepo) AddItem(item model.Item) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.items[item.ItemID] = item
+// GetItem returns an item
+func (r *MemoryRepo) GetItem(ctx context.Context, itemID string) (model.Item, error) {
+	item, ok := r.item(itemID)
+	if !ok {
+		return model.Item{}, fmt.Errorf("get item %s: %w", itemID, biddingerrors.ErrItemNotFound)
+	}
+	return item, nil
+}
+
+// CloseItem ends bidding on an item
+func (r *MemoryRepo) CloseItem(ctx context.Context, itemID string, closedAt time.Time) (model.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, err)
+	}
+
+	e := r.entry(itemID)
+	if e == nil {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, biddingerrors.ErrItemNotFound)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.item.ClosedAt != nil {
+		return model.Item{}, fmt.Errorf("close item %s: %w", itemID, biddingerrors.ErrAuctionClosed)
+	}
+	closedAt = closedAt.UTC()
+	e.item.ClosedAt = &closedAt
+	return e.item, nil
+}
+
+// AddItem adds an item to the repository. It is used for seeding, WAL replay and tests.
+// Replacing an item keeps a closed auction closed.
+func (r *MemoryRepo) AddItem(item model.Item) error {
+	r.itemsMu.Lock()
+	defer r.itemsMu.Unlock()
+
+	if e, ok := r.items[item.ItemID]; ok {
+		e.mu.Lock()
+		if item.ClosedAt == nil {
+			item.ClosedAt = e.item.ClosedAt
+		}
+		e.item = item
+		e.mu.Unlock()
+		return nil
+	}
+	r.items[item.ItemID] = &itemEntry{item: item}
+	return nil
+}
+
+// Close is a no-op; it lets MemoryRepo satisfy Store
+func (r *MemoryRepo) Close() error {
+	return nil
+}
+
+// CountOpenItems returns the number of items whose auction is not closed
+func (r *MemoryRepo) CountOpenItems(ctx context.Context) (int, error) {
+	r.itemsMu.RLock()
+	defer r.itemsMu.RUnlock()
+
+	n := 0
+	for _, e := range r.items {
+		e.mu.RLock()
+		if e.item.ClosedAt == nil {
+			n++
+		}
+		e.mu.RUnlock()
+	}
+	return n, nil
+}
+
+// Ping always succeeds; memory is always reachable
+func (r *MemoryRepo) Ping(ctx context.Context) error {
+	return nil
+}
+
+// item returns an item and whether it exists
+func (r *MemoryRepo) item(itemID string) (model.Item, bool) {
+	e := r.entry(itemID)
+	if e == nil {
+		return model.Item{}, false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.item, true
+}
+
+// apply replays a logged mutation onto the in-memory state
+func (r *MemoryRepo) apply(rec walRecord) error {
+	switch rec.Type {
+	case walRecordItem:
+		if rec.Item == nil {
+			return fmt.Errorf("%w: item record without item", errWALCorrupt)
+		}
+		return r.AddItem(*rec.Item)
+	case walRecordBid:
+		if rec.Bid == nil {
+			return fmt.Errorf("%w: bid record without bid", errWALCorrupt)
+		}
+		return r.RecordBidForItem(context.Background(), *rec.Bid)
+	case walRecordClose:
+		if rec.Item == nil || rec.Item.ClosedAt == nil {
+			return fmt.Errorf("%w: close record without closing time", errWALCorrupt)
+		}
+		_, err := r.CloseItem(context.Background(), rec.Item.ItemID, *rec.Item.ClosedAt)
+		return err
+	default:
+		return fmt.Errorf("%w: unknown record type %q", errWALCorrupt, rec.Type)
+	}
+}
+
+// exportState returns a deep copy of the repository state for snapshotting.
+// Callers must prevent concurrent writes for the copy to be consistent.
+func (r *MemoryRepo) exportState() *snapshot {
+	snap := &snapshot{
+		Items:     make(map[string]model.Item),
+		Bids:      make(map[string][]model.Bid),
+		UserItems: make(map[string][]string),
+		Outbox:    r.changes.export(),
+	}
+
+	r.itemsMu.RLock()
+	for id, e := range r.items {
+		e.mu.RLock()
+		snap.Items[id] = e.item
+		if len(e.bids) > 0 {
+			snap.Bids[id] = append([]model.Bid(nil), e.bids...)
+		}
+		e.mu.RUnlock()
+	}
+	r.itemsMu.RUnlock()
+
+	for i := range r.users {
+		shard := &r.users[i]
+		shard.mu.RLock()
+		for id, set := range shard.userItems {
+			snap.UserItems[id] = append([]string(nil), set.order...)
+		}
+		shard.mu.RUnlock()
+	}
+	return snap
+}
+
+// restoreState replaces the repository state with the contents of a snapshot.
+// It must be called before the repository is shared.
+func (r *MemoryRepo) restoreState(snap *snapshot) {
+	r.itemsMu.Lock()
+	r.items = make(map[string]*itemEntry, len(snap.Items))
+	for id, item := range snap.Items {
+		e := &itemEntry{item: item}
+		for _, bid := range snap.Bids[id] {
+			e.appendBid(bid)
+		}
+		r.items[id] = e
+	}
+	r.itemsMu.Unlock()
+
+	for i := range r.users {
+		shard := &r.users[i]
+		shard.mu.Lock()
+		shard.userItems = make(map[string]*userItemSet)
+		shard.mu.Unlock()
+	}
+	for id, itemIDs := range snap.UserItems {
+		shard := r.userShard(id)
+		shard.mu.Lock()
+		shard.userItems[id] = newUserItemSet(itemIDs)
+		shard.mu.Unlock()
+	}
+
+	r.changes.restore(snap.Outbox)
 }
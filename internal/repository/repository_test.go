@@ -2,6 +2,7 @@ package repository
 
 import (
 	model "bidding-tracker/internal/models"
+	"context"
 	"fmt"
 	"math"
 	"sync"
@@ -32,13 +33,21 @@ func newBid(bidID, itemID, userID string, amount float64, createdAt time.Time) m
 	}
 }
 
+// Helper to read an item straight from the repository
+func itemOf(repo *MemoryRepo, itemID string) model.Item {
+	e := repo.entry(itemID)
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.item
+}
+
 // Test RecordBidForItem
 func TestMemoryRepo_RecordBidForItem(t *testing.T) {
 	t.Parallel() // Allow running in parallel with other test functions
 
 	// Initialize repo and seed with an item
 	repo := NewMemoryRepo()
-	repo.items["item1"] = newItem("item1", "Item 1", 50)
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
 
 	// Table-driven test cases
 	tests := []struct {
@@ -62,13 +71,13 @@ func TestMemoryRepo_RecordBidForItem(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Run table test cases in parallel
 
-			err := repo.RecordBidForItem(tc.bid)
+			err := repo.RecordBidForItem(context.Background(), tc.bid)
 			if tc.wantError {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
 				if repo.items != nil {
-					bids, err := repo.GetBidsByItem(tc.bid.ItemID)
+					bids, err := repo.GetBidsByItem(context.Background(), tc.bid.ItemID)
 					require.NoError(t, err)
 					require.Contains(t, bids, tc.bid)
 				}
@@ -79,9 +88,9 @@ func TestMemoryRepo_RecordBidForItem(t *testing.T) {
 	// Special case: user placing the same bid twice (idempotent behavior)
 	t.Run("user_already_bid_on_same_item", func(t *testing.T) {
 		bid := newBid("bid-existing", "item1", "userX", 300, time.Now())
-		require.NoError(t, repo.RecordBidForItem(bid))
+		require.NoError(t, repo.RecordBidForItem(context.Background(), bid))
 		// Record the same bid again
-		require.NoError(t, repo.RecordBidForItem(bid))
+		require.NoError(t, repo.RecordBidForItem(context.Background(), bid))
 	})
 
 	// concurrency test
@@ -90,7 +99,7 @@ func TestMemoryRepo_RecordBidForItem(t *testing.T) {
 
 		// Initialize repo and seed with an item
 		repo := NewMemoryRepo()
-		repo.items["item1"] = newItem("item1", "Item 1", 50)
+		require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
 
 		var wg sync.WaitGroup
 		concurrentCount := 50
@@ -101,13 +110,13 @@ func TestMemoryRepo_RecordBidForItem(t *testing.T) {
 			go func() {
 				defer wg.Done()
 				b := newBid(fmt.Sprintf("bid-%d", i), "item1", fmt.Sprintf("user-%d", i), float64(100+i), time.Now())
-				require.NoError(t, repo.RecordBidForItem(b))
+				require.NoError(t, repo.RecordBidForItem(context.Background(), b))
 			}()
 		}
 
 		wg.Wait()
 
-		bids, err := repo.GetBidsByItem("item1")
+		bids, err := repo.GetBidsByItem(context.Background(), "item1")
 		require.NoError(t, err)
 		require.Len(t, bids, concurrentCount)
 	})
@@ -119,30 +128,30 @@ func TestMemoryRepo_GetBidsByItem(t *testing.T) {
 
 	// Initialize repo and seed with 4 items
 	repo := NewMemoryRepo()
-	repo.items["item1"] = newItem("item1", "Item 1", 50)
-	repo.items["item2"] = newItem("item2", "Item 2", 75)
-	repo.items["item3"] = newItem("item3", "Item 3", 100) // for large number of bids
-	repo.items["item4"] = newItem("item4", "Item 4", 200) // for extreme bid amounts
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+	require.NoError(t, repo.AddItem(newItem("item2", "Item 2", 75)))
+	require.NoError(t, repo.AddItem(newItem("item3", "Item 3", 100))) // for large number of bids
+	require.NoError(t, repo.AddItem(newItem("item4", "Item 4", 200))) // for extreme bid amounts
 
 	// Seed normal bids and check errors in setup
 	bid1 := newBid("bid1", "item1", "user1", 100, time.Now())
 	bid2 := newBid("bid2", "item1", "user2", 150, time.Now())
-	require.NoError(t, repo.RecordBidForItem(bid1))
-	require.NoError(t, repo.RecordBidForItem(bid2))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid1))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid2))
 
 	// Seed large number of bids for performance/internal slice growth
 	var largeBids []model.Bid
 	for i := 0; i < 1000; i++ {
 		b := newBid(fmt.Sprintf("bid-large-%d", i), "item3", fmt.Sprintf("user-%d", i), float64(100+i), time.Now())
-		require.NoError(t, repo.RecordBidForItem(b))
+		require.NoError(t, repo.RecordBidForItem(context.Background(), b))
 		largeBids = append(largeBids, b)
 	}
 
 	// Seed bids with extreme amounts
 	bidHigh := newBid("bid-high", "item4", "user-high", math.MaxFloat64, time.Now())
 	bidLow := newBid("bid-low", "item4", "user-low", -math.MaxFloat64, time.Now())
-	require.NoError(t, repo.RecordBidForItem(bidHigh))
-	require.NoError(t, repo.RecordBidForItem(bidLow))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidHigh))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidLow))
 
 	// Table-driven test cases
 	tests := []struct {
@@ -164,7 +173,7 @@ func TestMemoryRepo_GetBidsByItem(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Run table test cases in parallel
 
-			bids, err := repo.GetBidsByItem(tc.itemID)
+			bids, err := repo.GetBidsByItem(context.Background(), tc.itemID)
 			if tc.wantError {
 				require.Error(t, err)
 			} else {
@@ -185,7 +194,7 @@ func TestMemoryRepo_GetBidsByItem(t *testing.T) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				bids, err := repo.GetBidsByItem("item1")
+				bids, err := repo.GetBidsByItem(context.Background(), "item1")
 				require.NoError(t, err)
 				require.ElementsMatch(t, bids, []model.Bid{bid1, bid2})
 			}()
@@ -201,37 +210,44 @@ func TestMemoryRepo_GetWinningBid(t *testing.T) {
 
 	// Initialize repo and seed with 4 items
 	repo := NewMemoryRepo()
-	repo.items["item1"] = newItem("item1", "Item 1", 50)
-	repo.items["item2"] = newItem("item2", "Item 2", 75)
-	repo.items["item3"] = newItem("item3", "Item 3", 100) // for large number of bids
-	repo.items["item4"] = newItem("item4", "Item 4", 200) // for extreme bid amounts
-	repo.items["item5"] = newItem("item5", "Item 5", 150) // for tie bids
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+	require.NoError(t, repo.AddItem(newItem("item2", "Item 2", 75)))
+	require.NoError(t, repo.AddItem(newItem("item3", "Item 3", 100))) // for large number of bids
+	require.NoError(t, repo.AddItem(newItem("item4", "Item 4", 200))) // for extreme bid amounts
+	require.NoError(t, repo.AddItem(newItem("item5", "Item 5", 150))) // for tie bids
+	require.NoError(t, repo.AddItem(newItem("item6", "Item 6", 150))) // for a late-recorded earlier tie
 
 	// Seed normal bids
 	bid1 := newBid("bid1", "item1", "user1", 100, time.Now())
 	bid2 := newBid("bid2", "item1", "user2", 150, time.Now())
-	require.NoError(t, repo.RecordBidForItem(bid1))
-	require.NoError(t, repo.RecordBidForItem(bid2))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid1))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid2))
 
 	// Seed large number of bids
 	var largeBids []model.Bid
 	for i := 0; i < 1000; i++ {
 		b := newBid(fmt.Sprintf("bid-large-%d", i), "item3", fmt.Sprintf("user-%d", i), float64(100+i), time.Now())
-		require.NoError(t, repo.RecordBidForItem(b))
+		require.NoError(t, repo.RecordBidForItem(context.Background(), b))
 		largeBids = append(largeBids, b)
 	}
 
 	// Seed bids with extreme amounts
 	bidHigh := newBid("bid-high", "item4", "user-high", math.MaxFloat64, time.Now())
 	bidLow := newBid("bid-low", "item4", "user-low", -math.MaxFloat64, time.Now())
-	require.NoError(t, repo.RecordBidForItem(bidHigh))
-	require.NoError(t, repo.RecordBidForItem(bidLow))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidHigh))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidLow))
 
 	// Tie bids
 	bidTie1 := newBid("bid-tie1", "item5", "userA", 200, time.Now())
 	bidTie2 := newBid("bid-tie2", "item5", "userB", 200, time.Now())
-	require.NoError(t, repo.RecordBidForItem(bidTie1))
-	require.NoError(t, repo.RecordBidForItem(bidTie2))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidTie1))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidTie2))
+
+	// Tie where the earlier bid is recorded second
+	bidLateTie := newBid("bid-late-tie", "item6", "userA", 200, time.Now())
+	bidEarlyTie := newBid("bid-early-tie", "item6", "userB", 200, bidLateTie.CreatedAt.Add(-time.Second))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidLateTie))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bidEarlyTie))
 
 	// Table-driven test cases
 	tests := []struct {
@@ -246,6 +262,7 @@ func TestMemoryRepo_GetWinningBid(t *testing.T) {
 		{name: "item_with_large_number_of_bids", itemID: "item3", wantBid: largeBids[len(largeBids)-1], wantError: false},
 		{name: "item_with_extreme_bid_amounts", itemID: "item4", wantBid: bidHigh, wantError: false},
 		{name: "tie_bids_first_wins", itemID: "item5", wantBid: bidTie1, wantError: false},
+		{name: "tie_bids_earliest_created_wins", itemID: "item6", wantBid: bidEarlyTie, wantError: false},
 		{name: "empty_itemID", itemID: "", wantBid: model.Bid{}, wantError: true},
 	}
 
@@ -254,7 +271,7 @@ func TestMemoryRepo_GetWinningBid(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Run table test cases in parallel
 
-			bid, err := repo.GetWinningBid(tc.itemID)
+			bid, err := repo.GetWinningBid(context.Background(), tc.itemID)
 			if tc.wantError {
 				require.Error(t, err)
 			} else {
@@ -275,7 +292,7 @@ func TestMemoryRepo_GetWinningBid(t *testing.T) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				bid, err := repo.GetWinningBid("item1")
+				bid, err := repo.GetWinningBid(context.Background(), "item1")
 				require.NoError(t, err)
 				require.Equal(t, bid2, bid)
 			}()
@@ -291,11 +308,11 @@ func TestMemoryRepo_GetItemsByUser(t *testing.T) {
 
 	// Initialize repo and seed with 4 items
 	repo := NewMemoryRepo()
-	repo.items["item1"] = newItem("item1", "Item 1", 50)
-	repo.items["item2"] = newItem("item2", "Item 2", 75)
-	repo.items["item3"] = newItem("item3", "Item 3", 100) // for large number of bids
-	repo.items["item4"] = newItem("item4", "Item 4", 200) // for extreme bid amounts
-	repo.items["item5"] = newItem("item5", "Item 5", 250) // for duplicates
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+	require.NoError(t, repo.AddItem(newItem("item2", "Item 2", 75)))
+	require.NoError(t, repo.AddItem(newItem("item3", "Item 3", 100))) // for large number of bids
+	require.NoError(t, repo.AddItem(newItem("item4", "Item 4", 200))) // for extreme bid amounts
+	require.NoError(t, repo.AddItem(newItem("item5", "Item 5", 250))) // for duplicates
 
 	// Seed bids
 	bid1 := newBid("bid1", "item1", "user1", 100, time.Now())
@@ -303,20 +320,20 @@ func TestMemoryRepo_GetItemsByUser(t *testing.T) {
 	bid3 := newBid("bid3", "item3", "user2", 200, time.Now())
 	bid4 := newBid("bid4", "item4", "user3", 250, time.Now())
 	bid5 := newBid("bid5", "item5", "user6", 300, time.Now())
-	require.NoError(t, repo.RecordBidForItem(bid1))
-	require.NoError(t, repo.RecordBidForItem(bid2))
-	require.NoError(t, repo.RecordBidForItem(bid3))
-	require.NoError(t, repo.RecordBidForItem(bid4))
-	require.NoError(t, repo.RecordBidForItem(bid5))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid1))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid2))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid3))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid4))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), bid5))
 
 	// Seed large number of bids for user4
 	for i := 0; i < 1000; i++ {
 		b := newBid(fmt.Sprintf("bid-large-%d", i), "item3", "user4", float64(100+i), time.Now())
-		require.NoError(t, repo.RecordBidForItem(b))
+		require.NoError(t, repo.RecordBidForItem(context.Background(), b))
 	}
 
 	// Duplicate bids for same item for user6
-	require.NoError(t, repo.RecordBidForItem(newBid("bid6", "item5", "user6", 350, time.Now())))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), newBid("bid6", "item5", "user6", 350, time.Now())))
 
 	// Table-driven test cases
 	tests := []struct {
@@ -325,13 +342,13 @@ func TestMemoryRepo_GetItemsByUser(t *testing.T) {
 		wantItems []model.Item
 		wantError bool
 	}{
-		{name: "user_with_multiple_items", userID: "user1", wantItems: []model.Item{repo.items["item1"], repo.items["item2"]}, wantError: false},
-		{name: "user_with_single_item", userID: "user2", wantItems: []model.Item{repo.items["item3"]}, wantError: false},
+		{name: "user_with_multiple_items", userID: "user1", wantItems: []model.Item{itemOf(repo, "item1"), itemOf(repo, "item2")}, wantError: false},
+		{name: "user_with_single_item", userID: "user2", wantItems: []model.Item{itemOf(repo, "item3")}, wantError: false},
 		{name: "user_with_no_items", userID: "userX", wantItems: nil, wantError: true},
-		{name: "user_with_large_number_of_items", userID: "user4", wantItems: []model.Item{repo.items["item3"]}, wantError: false},
-		{name: "user_with_extreme_bid_amounts", userID: "user3", wantItems: []model.Item{repo.items["item4"]}, wantError: false},
+		{name: "user_with_large_number_of_items", userID: "user4", wantItems: []model.Item{itemOf(repo, "item3")}, wantError: false},
+		{name: "user_with_extreme_bid_amounts", userID: "user3", wantItems: []model.Item{itemOf(repo, "item4")}, wantError: false},
 		{name: "empty_userID", userID: "", wantItems: nil, wantError: true},
-		{name: "duplicate_bids_same_item", userID: "user6", wantItems: []model.Item{repo.items["item5"]}, wantError: false},
+		{name: "duplicate_bids_same_item", userID: "user6", wantItems: []model.Item{itemOf(repo, "item5")}, wantError: false},
 	}
 
 	for _, tc := range tests {
@@ -339,7 +356,7 @@ func TestMemoryRepo_GetItemsByUser(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Run table test cases in parallel
 
-			items, err := repo.GetItemsByUser(tc.userID)
+			items, err := repo.GetItemsByUser(context.Background(), tc.userID)
 			if tc.wantError {
 				require.Error(t, err)
 			} else {
@@ -360,12 +377,116 @@ func TestMemoryRepo_GetItemsByUser(t *testing.T) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				items, err := repo.GetItemsByUser("user1")
+				items, err := repo.GetItemsByUser(context.Background(), "user1")
 				require.NoError(t, err)
-				require.ElementsMatch(t, items, []model.Item{repo.items["item1"], repo.items["item2"]})
+				require.ElementsMatch(t, items, []model.Item{itemOf(repo, "item1"), itemOf(repo, "item2")})
 			}()
 		}
 
 		wg.Wait()
 	})
 }
+
+// Test that every operation honours a canceled context
+func TestMemoryRepo_CanceledContext(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	// Initialize repo and seed with an item and a bid
+	repo := NewMemoryRepo()
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), newBid("bid1", "item1", "user1", 100, time.Now())))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Table-driven test cases
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "record_bid", call: func() error {
+			return repo.RecordBidForItem(ctx, newBid("bid2", "item1", "user2", 150, time.Now()))
+		}},
+		{name: "get_bids_by_item", call: func() error {
+			_, err := repo.GetBidsByItem(ctx, "item1")
+			return err
+		}},
+		{name: "get_winning_bid", call: func() error {
+			_, err := repo.GetWinningBid(ctx, "item1")
+			return err
+		}},
+		{name: "get_items_by_user", call: func() error {
+			_, err := repo.GetItemsByUser(ctx, "user1")
+			return err
+		}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			err := tc.call()
+			require.ErrorIs(t, err, context.Canceled)
+		})
+	}
+
+	// The canceled write must not have been recorded
+	bids, err := repo.GetBidsByItem(context.Background(), "item1")
+	require.NoError(t, err)
+	require.Len(t, bids, 1)
+}
+
+// Test that a write holding one item's lock does not block writes or reads on other items
+func TestMemoryRepo_PerItemLocking(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := NewMemoryRepo()
+	require.NoError(t, repo.AddItem(newItem("hot", "Hot Item", 50)))
+	require.NoError(t, repo.AddItem(newItem("cold", "Cold Item", 50)))
+
+	// Simulate a long-running write on the hot item
+	hot := repo.entry("hot")
+	hot.mu.Lock()
+	defer hot.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		if err := repo.RecordBidForItem(context.Background(), newBid("bid1", "cold", "user1", 100, time.Now())); err != nil {
+			done <- err
+			return
+		}
+		_, err := repo.GetItemsByUser(context.Background(), "user1")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("write to cold item blocked by lock on hot item")
+	}
+}
+
+// Test that waiting for a contended item lock is recorded
+func TestMemoryRepo_LockWaitMetric(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := NewMemoryRepo()
+	require.NoError(t, repo.AddItem(newItem("contended", "Contended Item", 50)))
+	countBefore, sumBefore := recordLockWait.Count(), recordLockWait.Sum()
+
+	e := repo.entry("contended")
+	e.mu.Lock()
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.RecordBidForItem(context.Background(), newBid("bid1", "contended", "user1", 100, time.Now()))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	e.mu.Unlock()
+	require.NoError(t, <-done)
+
+	// other tests record concurrently, so only a lower bound holds
+	require.GreaterOrEqual(t, recordLockWait.Count(), countBefore+1)
+	require.GreaterOrEqual(t, recordLockWait.Sum()-sumBefore, 0.02)
+}
@@ -0,0 +1,546 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	"bidding-tracker/internal/metrics"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/retry"
+	"bidding-tracker/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migration is one versioned, forward-only schema change
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// migrations lists every schema change in order. Applied versions are recorded in
+// schema_migrations, so new entries must only ever be appended.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_items_and_bids",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS items (
+				item_id        TEXT PRIMARY KEY,
+				title          TEXT NOT NULL,
+				description    TEXT NOT NULL,
+				starting_price DOUBLE PRECISION NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS bids (
+				item_id       TEXT NOT NULL REFERENCES items(item_id),
+				seq           BIGINT NOT NULL,
+				bid_id        TEXT NOT NULL UNIQUE,
+				user_id       TEXT NOT NULL,
+				amount        DOUBLE PRECISION NOT NULL,
+				created_at_ns BIGINT NOT NULL,
+				PRIMARY KEY (item_id, seq)
+			)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "index_winning_and_user_bids",
+		statements: []string{
+			// serves GetWinningBid: highest amount first, earliest bid wins ties
+			`CREATE INDEX IF NOT EXISTS idx_bids_winning ON bids (item_id, amount DESC, created_at_ns, seq)`,
+			// serves GetItemsByUser
+			`CREATE INDEX IF NOT EXISTS idx_bids_user_item ON bids (user_id, item_id)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "create_outbox",
+		statements: []string{
+			// written in the same transaction as the bid; change_offset is allocated
+			// there too so committed offsets are gap-free and commit in order
+			`CREATE TABLE IF NOT EXISTS outbox (
+				change_offset BIGINT PRIMARY KEY,
+				change_type   TEXT NOT NULL,
+				item_id       TEXT NOT NULL,
+				bid_id        TEXT NOT NULL,
+				user_id       TEXT NOT NULL,
+				amount        DOUBLE PRECISION NOT NULL,
+				created_at_ns BIGINT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add_bid_chain_hashes",
+		statements: []string{
+			// bids recorded before this migration keep empty hashes and are reported as unchained
+			`ALTER TABLE bids ADD COLUMN prev_hash TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE bids ADD COLUMN hash TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE outbox ADD COLUMN prev_hash TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE outbox ADD COLUMN hash TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 5,
+		name:    "add_outbox_bid_seq",
+		statements: []string{
+			`ALTER TABLE outbox ADD COLUMN seq BIGINT NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 6,
+		name:    "add_item_closed_at",
+		statements: []string{
+			// 0 while the auction is open
+			`ALTER TABLE items ADD COLUMN closed_at_ns BIGINT NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 7,
+		name:    "add_item_owner",
+		statements: []string{
+			// '' for items created before owners existed; only admins may close those
+			`ALTER TABLE items ADD COLUMN owner TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+}
+
+// txRetry bounds how often a transaction that lost a serialization conflict is run again
+var txRetry = retry.Policy{MaxAttempts: 5, InitialBackoff: 5 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}
+
+// SQLRepo is an AuctionDB backed by a relational database through database/sql
+type SQLRepo struct {
+	db        *sql.DB
+	dollar    bool // use $1, $2 placeholders instead of ?
+	forUpdate bool // lock the item row while a bid is recorded; SQLite locks the database instead
+}
+
+// NewSQLRepo wraps an open database handle and applies pending schema migrations.
+// driverName selects the placeholder style ("postgres" and "pgx" use $N).
+func NewSQLRepo(ctx context.Context, db *sql.DB, driverName string) (*SQLRepo, error) {
+	postgres := driverName == "postgres" || driverName == "pgx"
+	r := &SQLRepo{
+		db:        db,
+		dollar:    postgres,
+		forUpdate: postgres,
+	}
+	if err := r.migrate(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// migrate applies every migration newer than the recorded schema version, each in its own transaction
+func (r *SQLRepo) migrate(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("sql repo: create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("sql repo: read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := r.applyMigration(ctx, m); err != nil {
+			return err
+		}
+		utils.Info("SQLRepo: migration applied", map[string]any{"version": m.version, "name": m.name})
+	}
+	return nil
+}
+
+// applyMigration runs a single migration and records it atomically
+func (r *SQLRepo) applyMigration(ctx context.Context, m migration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sql repo: begin migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	for _, stmt := range m.statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("sql repo: migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, r.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+		m.version, m.name, time.Now().UTC().UnixNano()); err != nil {
+		return fmt.Errorf("sql repo: record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sql repo: commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
+
+// RecordBidForItem records a user's bid on an item inside a serializable transaction.
+// A bid with a sequence was validated by the service against the leader it read; the
+// leader is read again inside the transaction, so that when several instances share the
+// database the loser of a race gets ErrBidTooLow, or ErrBidConflict when it still beats
+// the new leader and should be placed again.
+func (r *SQLRepo) RecordBidForItem(ctx context.Context, bid model.Bid) error {
+	return r.serializable(ctx, recordTxRetries, "record bid for item "+bid.ItemID, func(tx *sql.Tx) error {
+		return r.recordBid(ctx, tx, bid)
+	})
+}
+
+// recordBid is one attempt at RecordBidForItem's transaction
+func (r *SQLRepo) recordBid(ctx context.Context, tx *sql.Tx, bid model.Bid) error {
+	var closedAt int64
+	err := tx.QueryRowContext(ctx, r.rebind(r.lockItem(`SELECT closed_at_ns FROM items WHERE item_id = ?`)), bid.ItemID).Scan(&closedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, biddingerrors.ErrItemNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, err)
+	}
+	if closedAt != 0 {
+		return fmt.Errorf("record bid for item %s: %w", bid.ItemID, biddingerrors.ErrAuctionClosed)
+	}
+
+	// per-item sequence preserves insertion order; the serializable transaction keeps it gap-free.
+	// A sequence assigned by the service is kept if it follows the item's last recorded bid.
+	var next int64
+	if err := tx.QueryRowContext(ctx, r.rebind(`SELECT COALESCE(MAX(seq), 0) + 1 FROM bids WHERE item_id = ?`), bid.ItemID).Scan(&next); err != nil {
+		return fmt.Errorf("record bid for item %s: next sequence: %w", bid.ItemID, err)
+	}
+	seq := int64(bid.Seq)
+	if seq == 0 {
+		seq = next
+	} else if err := r.checkLeader(ctx, tx, bid, next); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, r.rebind(`INSERT INTO bids (item_id, seq, bid_id, user_id, amount, created_at_ns, prev_hash, hash) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		bid.ItemID, seq, bid.BidID, bid.UserID, bid.Amount, bid.CreatedAt.UnixNano(), bid.PrevHash, bid.Hash); err != nil {
+		return fmt.Errorf("record bid for item %s: insert: %w", bid.ItemID, err)
+	}
+
+	// bids on different items race for the next offset; the loser fails to serialize
+	// and is run again by serializable
+	var offset int64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(change_offset), 0) + 1 FROM outbox`).Scan(&offset); err != nil {
+		return fmt.Errorf("record bid for item %s: next outbox offset: %w", bid.ItemID, err)
+	}
+	if _, err := tx.ExecContext(ctx, r.rebind(`INSERT INTO outbox (change_offset, change_type, item_id, bid_id, user_id, amount, created_at_ns, seq, prev_hash, hash) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		offset, string(ChangeBidRecorded), bid.ItemID, bid.BidID, bid.UserID, bid.Amount, bid.CreatedAt.UnixNano(), seq, bid.PrevHash, bid.Hash); err != nil {
+		return fmt.Errorf("record bid for item %s: insert outbox: %w", bid.ItemID, err)
+	}
+	return nil
+}
+
+// CloseItem ends bidding on an item. The item row is updated in the transaction that
+// checks it, so a bid either commits before the close or sees it.
+func (r *SQLRepo) CloseItem(ctx context.Context, itemID string, closedAt time.Time) (model.Item, error) {
+	closedAt = time.Unix(0, closedAt.UnixNano()).UTC()
+
+	var item model.Item
+	err := r.serializable(ctx, closeTxRetries, "close item "+itemID, func(tx *sql.Tx) error {
+		var err error
+		item, err = scanItem(tx.QueryRowContext(ctx, r.rebind(r.lockItem(`SELECT item_id, title, description, starting_price, owner, closed_at_ns FROM items WHERE item_id = ?`)), itemID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("close item %s: %w", itemID, biddingerrors.ErrItemNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("close item %s: %w", itemID, err)
+		}
+		if item.ClosedAt != nil {
+			return fmt.Errorf("close item %s: %w", itemID, biddingerrors.ErrAuctionClosed)
+		}
+
+		if _, err := tx.ExecContext(ctx, r.rebind(`UPDATE items SET closed_at_ns = ? WHERE item_id = ?`), closedAt.UnixNano(), itemID); err != nil {
+			return fmt.Errorf("close item %s: %w", itemID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Item{}, err
+	}
+	item.ClosedAt = &closedAt
+	return item, nil
+}
+
+// serializable runs fn in a serializable transaction and commits it. A transaction that
+// failed to serialize against a concurrent one is run again from the start, with a short
+// backoff, up to txRetry.MaxAttempts times, counting each retry in retries. op prefixes
+// the errors of begin and commit.
+func (r *SQLRepo) serializable(ctx context.Context, retries *metrics.Counter, op string, fn func(tx *sql.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := r.inTx(ctx, op, fn)
+		if err == nil || !retryableTx(err) || attempt >= txRetry.MaxAttempts {
+			return err
+		}
+		retries.Inc()
+
+		backoff := txRetry.Backoff(attempt + 1)
+		backoff += time.Duration(rand.Int64N(int64(backoff))) // spread out the retrying transactions
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// inTx is one attempt of serializable
+func (r *SQLRepo) inTx(ctx context.Context, op string, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// retryableTx reports whether err means the transaction lost to a concurrent one and
+// can succeed when run again: a PostgreSQL serialization failure (SQLSTATE 40001) or
+// deadlock (40P01), or SQLite's busy database
+func retryableTx(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		code := state.SQLState()
+		return code == "40001" || code == "40P01"
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "could not serialize access") ||
+		strings.Contains(msg, "deadlock detected") ||
+		strings.Contains(msg, "database is locked")
+}
+
+// lockItem appends FOR UPDATE to a query reading an item row where the database supports
+// it, serializing bids and closes on the item across instances
+func (r *SQLRepo) lockItem(query string) string {
+	if r.forUpdate {
+		return query + ` FOR UPDATE`
+	}
+	return query
+}
+
+// checkLeader verifies inside tx that bid beats the item's current leader and follows its
+// last recorded bid, next being the sequence after it
+func (r *SQLRepo) checkLeader(ctx context.Context, tx *sql.Tx, bid model.Bid, next int64) error {
+	var leader float64
+	err := tx.QueryRowContext(ctx, r.rebind(`SELECT amount FROM bids WHERE item_id = ? ORDER BY amount DESC, created_at_ns ASC, seq ASC LIMIT 1`), bid.ItemID).Scan(&leader)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("record bid for item %s: read leader: %w", bid.ItemID, err)
+	case bid.Amount <= leader:
+		return fmt.Errorf("record bid for item %s: %w - current highest bid is %.2f", bid.ItemID, biddingerrors.ErrBidTooLow, leader)
+	}
+	if int64(bid.Seq) != next {
+		return fmt.Errorf("record bid for item %s: sequence %d, expected %d: %w", bid.ItemID, bid.Seq, next, biddingerrors.ErrBidConflict)
+	}
+	return nil
+}
+
+// GetBidsByItem returns all bids for an item in the order they were recorded
+func (r *SQLRepo) GetBidsByItem(ctx context.Context, itemID string) ([]model.Bid, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(`SELECT bid_id, item_id, user_id, amount, created_at_ns, seq, prev_hash, hash FROM bids WHERE item_id = ? ORDER BY seq`), itemID)
+	if err != nil {
+		return nil, fmt.Errorf("get bids for item %s: %w", itemID, err)
+	}
+	defer rows.Close()
+
+	var bids []model.Bid
+	for rows.Next() {
+		bid, err := scanBid(rows)
+		if err != nil {
+			return nil, fmt.Errorf("get bids for item %s: %w", itemID, err)
+		}
+		bids = append(bids, bid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get bids for item %s: %w", itemID, err)
+	}
+
+	if len(bids) == 0 {
+		return nil, fmt.Errorf("get bids for item %s: %w", itemID, biddingerrors.ErrNoBids)
+	}
+	return bids, nil
+}
+
+// GetWinningBid returns the highest bid for an item, resolving ties by the earliest bid
+func (r *SQLRepo) GetWinningBid(ctx context.Context, itemID string) (model.Bid, error) {
+	row := r.db.QueryRowContext(ctx, r.rebind(`SELECT bid_id, item_id, user_id, amount, created_at_ns, seq, prev_hash, hash FROM bids
+		WHERE item_id = ? ORDER BY amount DESC, created_at_ns ASC, seq ASC LIMIT 1`), itemID)
+
+	bid, err := scanBid(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Bid{}, fmt.Errorf("get winning bid for item %s: %w", itemID, biddingerrors.ErrNoBids)
+	}
+	if err != nil {
+		return model.Bid{}, fmt.Errorf("get winning bid for item %s: %w", itemID, err)
+	}
+	return bid, nil
+}
+
+// GetItem returns an item
+func (r *SQLRepo) GetItem(ctx context.Context, itemID string) (model.Item, error) {
+	item, err := scanItem(r.db.QueryRowContext(ctx, r.rebind(`SELECT item_id, title, description, starting_price, owner, closed_at_ns FROM items WHERE item_id = ?`), itemID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Item{}, fmt.Errorf("get item %s: %w", itemID, biddingerrors.ErrItemNotFound)
+	}
+	if err != nil {
+		return model.Item{}, fmt.Errorf("get item %s: %w", itemID, err)
+	}
+	return item, nil
+}
+
+// GetItemsByUser returns all items a user has bid on
+func (r *SQLRepo) GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(`SELECT item_id, title, description, starting_price, owner, closed_at_ns FROM items
+		WHERE item_id IN (SELECT item_id FROM bids WHERE user_id = ?) ORDER BY item_id`), userID)
+	if err != nil {
+		return nil, fmt.Errorf("get items for user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var items []model.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("get items for user %s: %w", userID, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get items for user %s: %w", userID, err)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("get items for user %s: %w", userID, biddingerrors.ErrUserNoBids)
+	}
+	return items, nil
+}
+
+// GetChanges returns up to limit outbox changes after an offset
+func (r *SQLRepo) GetChanges(ctx context.Context, after uint64, limit int) ([]Change, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(`SELECT change_offset, change_type, bid_id, item_id, user_id, amount, created_at_ns, seq, prev_hash, hash FROM outbox
+		WHERE change_offset > ? ORDER BY change_offset LIMIT ?`), int64(after), limit)
+	if err != nil {
+		return nil, fmt.Errorf("get changes after %d: %w", after, err)
+	}
+	defer rows.Close()
+
+	var changes []Change
+	for rows.Next() {
+		var c Change
+		var offset, createdAt, seq int64
+		if err := rows.Scan(&offset, &c.Type, &c.Bid.BidID, &c.Bid.ItemID, &c.Bid.UserID, &c.Bid.Amount, &createdAt, &seq, &c.Bid.PrevHash, &c.Bid.Hash); err != nil {
+			return nil, fmt.Errorf("get changes after %d: %w", after, err)
+		}
+		c.Offset = uint64(offset)
+		c.ItemID = c.Bid.ItemID
+		c.Bid.Seq = uint64(seq)
+		c.Bid.CreatedAt = time.Unix(0, createdAt).UTC()
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get changes after %d: %w", after, err)
+	}
+	return changes, nil
+}
+
+// AddItem adds or replaces an item. Replacing an item keeps a closed auction closed.
+func (r *SQLRepo) AddItem(item model.Item) error {
+	_, err := r.db.Exec(r.rebind(`INSERT INTO items (item_id, title, description, starting_price, owner) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (item_id) DO UPDATE SET title = excluded.title, description = excluded.description, starting_price = excluded.starting_price, owner = excluded.owner`),
+		item.ItemID, item.Title, item.Description, item.StartingPrice, item.Owner)
+	if err != nil {
+		return fmt.Errorf("add item %s: %w", item.ItemID, err)
+	}
+	return nil
+}
+
+// CountOpenItems returns the number of items whose auction is not closed
+func (r *SQLRepo) CountOpenItems(ctx context.Context) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM items WHERE closed_at_ns = 0`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count open items: %w", err)
+	}
+	return n, nil
+}
+
+// Ping checks that the database is reachable
+func (r *SQLRepo) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("sql repo: ping: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying database handle
+func (r *SQLRepo) Close() error {
+	return r.db.Close()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanItem reads an item from a row selected as (item_id, title, description, starting_price, owner, closed_at_ns)
+func scanItem(row rowScanner) (model.Item, error) {
+	var item model.Item
+	var closedAt int64
+	if err := row.Scan(&item.ItemID, &item.Title, &item.Description, &item.StartingPrice, &item.Owner, &closedAt); err != nil {
+		return model.Item{}, err
+	}
+	if closedAt != 0 {
+		t := time.Unix(0, closedAt).UTC()
+		item.ClosedAt = &t
+	}
+	return item, nil
+}
+
+// scanBid reads a bid from a row selected as (bid_id, item_id, user_id, amount, created_at_ns, seq, prev_hash, hash)
+func scanBid(row rowScanner) (model.Bid, error) {
+	var bid model.Bid
+	var createdAt, seq int64
+	if err := row.Scan(&bid.BidID, &bid.ItemID, &bid.UserID, &bid.Amount, &createdAt, &seq, &bid.PrevHash, &bid.Hash); err != nil {
+		return model.Bid{}, err
+	}
+	bid.CreatedAt = time.Unix(0, createdAt).UTC()
+	bid.Seq = uint64(seq)
+	return bid, nil
+}
+
+// rebind rewrites ? placeholders to $N for drivers that require numbered parameters
+func (r *SQLRepo) rebind(query string) string {
+	if !r.dollar {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
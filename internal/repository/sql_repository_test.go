@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	model "bidding-tracker/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper to open an SQLRepo on a fresh embedded SQLite database
+func openSQLRepo(t *testing.T) (*SQLRepo, string) {
+	t.Helper()
+	dsn := "file:" + filepath.Join(t.TempDir(), "auction.db") + "?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on"
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+
+	repo, err := NewSQLRepo(context.Background(), db, "sqlite3")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo, dsn
+}
+
+// Test RecordBidForItem, GetBidsByItem and GetWinningBid against SQLite
+func TestSQLRepo_Bids(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo, _ := openSQLRepo(t)
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+	require.NoError(t, repo.AddItem(newItem("item2", "Item 2", 75)))
+	require.NoError(t, repo.AddItem(newItem("item3", "Item 3", 100)))
+
+	bid1 := newBid("bid1", "item1", "user1", 100, base)
+	bid2 := newBid("bid2", "item1", "user2", 150, base.Add(time.Second))
+	bidTie1 := newBid("bid-tie1", "item3", "userA", 200, base)
+	bidTie2 := newBid("bid-tie2", "item3", "userB", 200, base)
+	for _, b := range []model.Bid{bid1, bid2, bidTie1, bidTie2} {
+		require.NoError(t, repo.RecordBidForItem(ctx, b))
+	}
+	// the repository assigns each item's sequence in recording order
+	bid1.Seq, bid2.Seq, bidTie1.Seq, bidTie2.Seq = 1, 2, 1, 2
+
+	err := repo.RecordBidForItem(ctx, newBid("bid-x", "itemX", "user1", 100, base))
+	require.ErrorIs(t, err, biddingerrors.ErrItemNotFound)
+
+	// Table-driven test cases
+	tests := []struct {
+		name        string
+		itemID      string
+		wantBids    []model.Bid
+		wantWinning model.Bid
+		wantError   error
+	}{
+		{name: "existing_item_with_bids", itemID: "item1", wantBids: []model.Bid{bid1, bid2}, wantWinning: bid2},
+		{name: "existing_item_no_bids", itemID: "item2", wantError: biddingerrors.ErrNoBids},
+		{name: "non_existing_item", itemID: "itemX", wantError: biddingerrors.ErrNoBids},
+		{name: "tie_bids_first_wins", itemID: "item3", wantBids: []model.Bid{bidTie1, bidTie2}, wantWinning: bidTie1},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			bids, err := repo.GetBidsByItem(ctx, tc.itemID)
+			winning, winErr := repo.GetWinningBid(ctx, tc.itemID)
+			if tc.wantError != nil {
+				require.ErrorIs(t, err, tc.wantError)
+				require.ErrorIs(t, winErr, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, winErr)
+			require.Equal(t, tc.wantBids, bids)
+			require.Equal(t, tc.wantWinning, winning)
+		})
+	}
+}
+
+// Test GetItemsByUser against SQLite
+func TestSQLRepo_GetItemsByUser(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo, _ := openSQLRepo(t)
+	ctx := context.Background()
+
+	item1 := newItem("item1", "Item 1", 50)
+	item2 := newItem("item2", "Item 2", 75)
+	require.NoError(t, repo.AddItem(item1))
+	require.NoError(t, repo.AddItem(item2))
+
+	require.NoError(t, repo.RecordBidForItem(ctx, newBid("bid1", "item1", "user1", 100, time.Now())))
+	require.NoError(t, repo.RecordBidForItem(ctx, newBid("bid2", "item2", "user1", 150, time.Now())))
+	require.NoError(t, repo.RecordBidForItem(ctx, newBid("bid3", "item1", "user1", 200, time.Now()))) // duplicate item
+
+	items, err := repo.GetItemsByUser(ctx, "user1")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []model.Item{item1, item2}, items)
+
+	_, err = repo.GetItemsByUser(ctx, "userX")
+	require.ErrorIs(t, err, biddingerrors.ErrUserNoBids)
+
+	// Item edits are visible through the per-user query
+	item1.Title = "Renamed"
+	require.NoError(t, repo.AddItem(item1))
+	items, err = repo.GetItemsByUser(ctx, "user1")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []model.Item{item1, item2}, items)
+}
+
+// Test concurrent transactional bid placement keeps a gap-free per-item sequence
+func TestSQLRepo_ConcurrentBids(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo, _ := openSQLRepo(t)
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+
+	var wg sync.WaitGroup
+	concurrentCount := 50
+
+	for i := 0; i < concurrentCount; i++ {
+		wg.Add(1)
+		i := i
+		go func() {
+			defer wg.Done()
+			b := newBid(fmt.Sprintf("bid-%d", i), "item1", fmt.Sprintf("user-%d", i), float64(100+i), time.Now())
+			require.NoError(t, repo.RecordBidForItem(context.Background(), b))
+		}()
+	}
+
+	wg.Wait()
+
+	bids, err := repo.GetBidsByItem(context.Background(), "item1")
+	require.NoError(t, err)
+	require.Len(t, bids, concurrentCount)
+
+	var maxSeq, count int
+	require.NoError(t, repo.db.QueryRow(`SELECT MAX(seq), COUNT(*) FROM bids WHERE item_id = ?`, "item1").Scan(&maxSeq, &count))
+	require.Equal(t, concurrentCount, maxSeq)
+	require.Equal(t, concurrentCount, count)
+}
+
+// Test that a bid validated against a stale leader is rejected inside the transaction
+func TestSQLRepo_RecheckLeader(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo, _ := openSQLRepo(t)
+	ctx := context.Background()
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+
+	first := newBid("bid1", "item1", "user1", 100, time.Now())
+	first.Seq = 1
+	require.NoError(t, repo.RecordBidForItem(ctx, first))
+
+	// both bids were validated by another instance before bid1 was recorded
+	tests := []struct {
+		name    string
+		amount  float64
+		wantErr error
+	}{
+		{name: "lower_than_new_leader", amount: 90, wantErr: biddingerrors.ErrBidTooLow},
+		{name: "tie_with_new_leader", amount: 100, wantErr: biddingerrors.ErrBidTooLow},
+		{name: "beats_new_leader", amount: 120, wantErr: biddingerrors.ErrBidConflict},
+	}
+	for _, tc := range tests {
+		stale := newBid("stale-"+tc.name, "item1", "user2", tc.amount, time.Now())
+		stale.Seq = 1
+		require.ErrorIs(t, repo.RecordBidForItem(ctx, stale), tc.wantErr, tc.name)
+	}
+
+	next := newBid("bid2", "item1", "user2", 120, time.Now())
+	next.Seq = 2
+	require.NoError(t, repo.RecordBidForItem(ctx, next))
+
+	bids, err := repo.GetBidsByItem(ctx, "item1")
+	require.NoError(t, err)
+	require.Len(t, bids, 2)
+	changes, err := repo.GetChanges(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2, "rejected bids leave no change records")
+}
+
+// sqlStateError is a driver error carrying an SQLSTATE code, like those of PostgreSQL drivers
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+// Test that transactions losing a serialization conflict are run again, a bounded number of times
+func TestSQLRepo_SerializableRetry(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name         string
+		err          error
+		failures     int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "serialization_failure_retried", err: fmt.Errorf("insert outbox: %w", sqlStateError("40001")), failures: 2, wantAttempts: 3},
+		{name: "deadlock_retried", err: sqlStateError("40P01"), failures: 1, wantAttempts: 2},
+		{name: "sqlite_busy_retried", err: errors.New("database is locked"), failures: 1, wantAttempts: 2},
+		{name: "gives_up_after_max_attempts", err: sqlStateError("40001"), failures: 100, wantAttempts: txRetry.MaxAttempts, wantErr: true},
+		{name: "unique_violation_not_retried", err: sqlStateError("23505"), failures: 100, wantAttempts: 1, wantErr: true},
+		{name: "domain_error_not_retried", err: biddingerrors.ErrBidTooLow, failures: 100, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			repo, _ := openSQLRepo(t)
+			attempts := 0
+			err := repo.serializable(context.Background(), recordTxRetries, "test", func(tx *sql.Tx) error {
+				attempts++
+				if attempts <= tc.failures {
+					return tc.err
+				}
+				return nil
+			})
+			require.Equal(t, tc.wantAttempts, attempts)
+			if tc.wantErr {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// Test that migrations are recorded once and reopening an existing database keeps its data
+func TestSQLRepo_Migrations(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo, dsn := openSQLRepo(t)
+	require.NoError(t, repo.AddItem(newItem("item1", "Item 1", 50)))
+	require.NoError(t, repo.RecordBidForItem(context.Background(), newBid("bid1", "item1", "user1", 100, time.Now())))
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	reopened, err := NewSQLRepo(context.Background(), db, "sqlite3")
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	var applied int
+	require.NoError(t, reopened.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied))
+	require.Equal(t, len(migrations), applied)
+
+	bids, err := reopened.GetBidsByItem(context.Background(), "item1")
+	require.NoError(t, err)
+	require.Len(t, bids, 1)
+}
+
+// Test placeholder rewriting for drivers with numbered parameters
+func TestSQLRepo_Rebind(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name   string
+		dollar bool
+		query  string
+		want   string
+	}{
+		{name: "question_marks", dollar: false, query: "SELECT * FROM bids WHERE item_id = ? AND user_id = ?", want: "SELECT * FROM bids WHERE item_id = ? AND user_id = ?"},
+		{name: "dollar_numbers", dollar: true, query: "SELECT * FROM bids WHERE item_id = ? AND user_id = ?", want: "SELECT * FROM bids WHERE item_id = $1 AND user_id = $2"},
+		{name: "no_placeholders", dollar: true, query: "SELECT 1", want: "SELECT 1"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			repo := &SQLRepo{dollar: tc.dollar}
+			require.Equal(t, tc.want, repo.rebind(tc.query))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	model "bidding-tracker/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Store is an AuctionDB with a change feed that can be seeded with items and closed on shutdown
+type Store interface {
+	AuctionDB
+	ChangeFeed
+	AddItem(item model.Item) error
+	CountOpenItems(ctx context.Context) (int, error) // items open for bidding
+	Ping(ctx context.Context) error                  // reports whether the store can serve requests
+	Close() error
+}
+
+// Supported storage backends
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+	BackendSQL    = "sql"
+)
+
+// StoreConfig selects and configures a storage backend
+type StoreConfig struct {
+	Backend string // memory (default), file or sql
+
+	File FileRepoConfig // used by the file backend
+
+	OutboxRetention int // outbox changes kept by the memory and file backends, 0 keeps all
+
+	SQLDriver string // database/sql driver name, e.g. sqlite3 or postgres
+	SQLDSN    string // driver-specific data source name
+}
+
+// Snapshot defaults of the file backend opened by OpenStore, so the WAL is
+// truncated regularly and startup only replays its tail
+const (
+	DefaultSnapshotEvery    = 10000
+	DefaultSnapshotInterval = 5 * time.Minute
+)
+
+// OpenStore creates the storage backend described by cfg. The file backend
+// snapshots with the defaults above unless cfg sets its own triggers.
+func OpenStore(ctx context.Context, cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewMemoryRepo(WithOutboxRetention(cfg.OutboxRetention)), nil
+
+	case BackendFile:
+		fileCfg := cfg.File
+		fileCfg.OutboxRetention = cfg.OutboxRetention
+		if fileCfg.SnapshotEvery == 0 {
+			fileCfg.SnapshotEvery = DefaultSnapshotEvery
+		}
+		if fileCfg.SnapshotInterval == 0 {
+			fileCfg.SnapshotInterval = DefaultSnapshotInterval
+		}
+		return NewFileRepo(fileCfg)
+
+	case BackendSQL:
+		if cfg.SQLDriver == "" || cfg.SQLDSN == "" {
+			return nil, fmt.Errorf("open store: sql backend requires a driver and a DSN")
+		}
+		db, err := sql.Open(cfg.SQLDriver, cfg.SQLDSN)
+		if err != nil {
+			return nil, fmt.Errorf("open store: %w", err)
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := db.PingContext(pingCtx); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("open store: ping %s: %w", cfg.SQLDriver, err)
+		}
+
+		repo, err := NewSQLRepo(ctx, db, cfg.SQLDriver)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return repo, nil
+
+	default:
+		return nil, fmt.Errorf("open store: unknown storage backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"bidding-tracker/internal/biddingerrors"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test backend selection in OpenStore
+func TestOpenStore(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	dir := t.TempDir()
+
+	// Table-driven test cases
+	tests := []struct {
+		name      string
+		cfg       StoreConfig
+		wantType  Store
+		wantError bool
+	}{
+		{name: "default_memory", cfg: StoreConfig{}, wantType: &MemoryRepo{}},
+		{name: "memory", cfg: StoreConfig{Backend: BackendMemory}, wantType: &MemoryRepo{}},
+		{name: "file", cfg: StoreConfig{Backend: BackendFile, File: FileRepoConfig{Dir: filepath.Join(dir, "wal")}}, wantType: &FileRepo{}},
+		{name: "file_without_dir", cfg: StoreConfig{Backend: BackendFile}, wantError: true},
+		{name: "sql", cfg: StoreConfig{Backend: BackendSQL, SQLDriver: "sqlite3", SQLDSN: "file:" + filepath.Join(dir, "store.db")}, wantType: &SQLRepo{}},
+		{name: "sql_without_dsn", cfg: StoreConfig{Backend: BackendSQL, SQLDriver: "sqlite3"}, wantError: true},
+		{name: "sql_unknown_driver", cfg: StoreConfig{Backend: BackendSQL, SQLDriver: "nope", SQLDSN: "x"}, wantError: true},
+		{name: "unknown_backend", cfg: StoreConfig{Backend: "tape"}, wantError: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			store, err := OpenStore(context.Background(), tc.cfg)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer store.Close()
+			require.IsType(t, tc.wantType, store)
+		})
+	}
+}
+
+// Test that the file backend snapshots by default and keeps configured triggers
+func TestOpenStore_FileSnapshots(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name         string
+		file         FileRepoConfig
+		wantEvery    int
+		wantInterval time.Duration
+	}{
+		{name: "defaults", file: FileRepoConfig{}, wantEvery: DefaultSnapshotEvery, wantInterval: DefaultSnapshotInterval},
+		{name: "configured", file: FileRepoConfig{SnapshotEvery: 50, SnapshotInterval: time.Minute}, wantEvery: 50, wantInterval: time.Minute},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			cfg := tc.file
+			cfg.Dir = t.TempDir()
+			store, err := OpenStore(context.Background(), StoreConfig{Backend: BackendFile, File: cfg})
+			require.NoError(t, err)
+			defer store.Close()
+			repo := store.(*FileRepo)
+			require.Equal(t, tc.wantEvery, repo.cfg.SnapshotEvery)
+			require.Equal(t, tc.wantInterval, repo.cfg.SnapshotInterval)
+		})
+	}
+}
+
+// Test that Ping succeeds on an open store and fails once it is closed
+func TestStore_Ping(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name            string
+		open            func(t *testing.T) Store
+		failsAfterClose bool
+	}{
+		{name: "memory", open: func(t *testing.T) Store { return NewMemoryRepo() }},
+		{name: "file", open: func(t *testing.T) Store { return openFileRepo(t, FileRepoConfig{Dir: t.TempDir()}) }, failsAfterClose: true},
+		{name: "sql", open: func(t *testing.T) Store { repo, _ := openSQLRepo(t); return repo }, failsAfterClose: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			store := tc.open(t)
+			require.NoError(t, store.Ping(context.Background()))
+
+			require.NoError(t, store.Close())
+			if tc.failsAfterClose {
+				require.Error(t, store.Ping(context.Background()))
+			} else {
+				require.NoError(t, store.Ping(context.Background()))
+			}
+		})
+	}
+}
+
+// Test CountOpenItems on every backend
+func TestStore_CountOpenItems(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		open func(t *testing.T) Store
+	}{
+		{name: "memory", open: func(t *testing.T) Store { return NewMemoryRepo() }},
+		{name: "file", open: func(t *testing.T) Store { return openFileRepo(t, FileRepoConfig{Dir: t.TempDir()}) }},
+		{name: "sql", open: func(t *testing.T) Store { repo, _ := openSQLRepo(t); return repo }},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			store := tc.open(t)
+			n, err := store.CountOpenItems(context.Background())
+			require.NoError(t, err)
+			require.Zero(t, n)
+
+			require.NoError(t, store.AddItem(newItem("item1", "Item 1", 10)))
+			require.NoError(t, store.AddItem(newItem("item2", "Item 2", 20)))
+			n, err = store.CountOpenItems(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+
+			_, err = store.CloseItem(context.Background(), "item1", time.Now())
+			require.NoError(t, err)
+			n, err = store.CountOpenItems(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, n, "closed auctions are not counted")
+		})
+	}
+}
+
+// Test CloseItem on every backend: bids are rejected once the item is closed and the
+// close time is reported with the item
+func TestStore_CloseItem(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		open func(t *testing.T) Store
+	}{
+		{name: "memory", open: func(t *testing.T) Store { return NewMemoryRepo() }},
+		{name: "file", open: func(t *testing.T) Store { return openFileRepo(t, FileRepoConfig{Dir: t.TempDir()}) }},
+		{name: "sql", open: func(t *testing.T) Store { repo, _ := openSQLRepo(t); return repo }},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			ctx := context.Background()
+			store := tc.open(t)
+			base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			require.NoError(t, store.AddItem(newItem("item1", "Item 1", 10)))
+			require.NoError(t, store.RecordBidForItem(ctx, newBid("bid1", "item1", "user1", 100, base)))
+
+			closedAt := base.Add(time.Hour)
+			item, err := store.CloseItem(ctx, "item1", closedAt)
+			require.NoError(t, err)
+			require.Equal(t, "item1", item.ItemID)
+			require.NotNil(t, item.ClosedAt)
+			require.True(t, closedAt.Equal(*item.ClosedAt))
+
+			err = store.RecordBidForItem(ctx, newBid("bid2", "item1", "user2", 200, closedAt.Add(time.Second)))
+			require.ErrorIs(t, err, biddingerrors.ErrAuctionClosed)
+
+			_, err = store.CloseItem(ctx, "item1", closedAt)
+			require.ErrorIs(t, err, biddingerrors.ErrAuctionClosed)
+			_, err = store.CloseItem(ctx, "missing", closedAt)
+			require.ErrorIs(t, err, biddingerrors.ErrItemNotFound)
+
+			// replacing a closed item keeps it closed
+			require.NoError(t, store.AddItem(newItem("item1", "Item 1 renamed", 10)))
+			err = store.RecordBidForItem(ctx, newBid("bid3", "item1", "user2", 300, closedAt.Add(time.Second)))
+			require.ErrorIs(t, err, biddingerrors.ErrAuctionClosed)
+
+			items, err := store.GetItemsByUser(ctx, "user1")
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			require.NotNil(t, items[0].ClosedAt)
+			require.True(t, closedAt.Equal(*items[0].ClosedAt))
+
+			winning, err := store.GetWinningBid(ctx, "item1")
+			require.NoError(t, err)
+			require.Equal(t, "bid1", winning.BidID)
+		})
+	}
+}
+
+// Test GetItem on every backend: the owner survives the round trip and unknown items
+// are reported as not found
+func TestStore_GetItem(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name string
+		open func(t *testing.T) Store
+	}{
+		{name: "memory", open: func(t *testing.T) Store { return NewMemoryRepo() }},
+		{name: "file", open: func(t *testing.T) Store { return openFileRepo(t, FileRepoConfig{Dir: t.TempDir()}) }},
+		{name: "sql", open: func(t *testing.T) Store { repo, _ := openSQLRepo(t); return repo }},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			ctx := context.Background()
+			store := tc.open(t)
+			item := newItem("item1", "Item 1", 10)
+			item.Owner = "seller1"
+			require.NoError(t, store.AddItem(item))
+
+			got, err := store.GetItem(ctx, "item1")
+			require.NoError(t, err)
+			require.Equal(t, "item1", got.ItemID)
+			require.Equal(t, "seller1", got.Owner)
+
+			_, err = store.GetItem(ctx, "missing")
+			require.ErrorIs(t, err, biddingerrors.ErrItemNotFound)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/tracing"
+)
+
+// tracedDB times every AuctionDB call as a span named after the method
+type tracedDB struct {
+	db AuctionDB
+}
+
+// Traced wraps db so each call is recorded as a span of the request's trace
+func Traced(db AuctionDB) AuctionDB {
+	if _, ok := db.(tracedDB); ok {
+		return db
+	}
+	return tracedDB{db: db}
+}
+
+// RecordBidForItem implements AuctionDB
+func (t tracedDB) RecordBidForItem(ctx context.Context, bid model.Bid) error {
+	ctx, span := tracing.StartSpan(ctx, "repository.RecordBidForItem")
+	span.SetAttribute("item_id", bid.ItemID)
+	defer span.End()
+	err := t.db.RecordBidForItem(ctx, bid)
+	span.SetError(err)
+	return err
+}
+
+// GetBidsByItem implements AuctionDB
+func (t tracedDB) GetBidsByItem(ctx context.Context, itemID string) ([]model.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "repository.GetBidsByItem")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+	bids, err := t.db.GetBidsByItem(ctx, itemID)
+	span.SetError(err)
+	return bids, err
+}
+
+// GetWinningBid implements AuctionDB
+func (t tracedDB) GetWinningBid(ctx context.Context, itemID string) (model.Bid, error) {
+	ctx, span := tracing.StartSpan(ctx, "repository.GetWinningBid")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+	bid, err := t.db.GetWinningBid(ctx, itemID)
+	span.SetError(err)
+	return bid, err
+}
+
+// GetItem implements AuctionDB
+func (t tracedDB) GetItem(ctx context.Context, itemID string) (model.Item, error) {
+	ctx, span := tracing.StartSpan(ctx, "repository.GetItem")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+	item, err := t.db.GetItem(ctx, itemID)
+	span.SetError(err)
+	return item, err
+}
+
+// CloseItem implements AuctionDB
+func (t tracedDB) CloseItem(ctx context.Context, itemID string, closedAt time.Time) (model.Item, error) {
+	ctx, span := tracing.StartSpan(ctx, "repository.CloseItem")
+	span.SetAttribute("item_id", itemID)
+	defer span.End()
+	item, err := t.db.CloseItem(ctx, itemID, closedAt)
+	span.SetError(err)
+	return item, err
+}
+
+// GetItemsByUser implements AuctionDB
+func (t tracedDB) GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error) {
+	ctx, span := tracing.StartSpan(ctx, "repository.GetItemsByUser")
+	span.SetAttribute("user_id", userID)
+	defer span.End()
+	items, err := t.db.GetItemsByUser(ctx, userID)
+	span.SetError(err)
+	return items, err
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	model "bidding-tracker/internal/models"
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// walRecordType identifies the kind of mutation stored in a WAL record
+type walRecordType string
+
+const (
+	walRecordBid   walRecordType = "bid"
+	walRecordItem  walRecordType = "item"
+	walRecordClose walRecordType = "close" // Item holds the item ID and closing time
+)
+
+// walHeaderSize is the size of the per-record header: 4 bytes payload length + 4 bytes CRC32C
+const walHeaderSize = 8
+
+// walMaxRecordSize guards recovery against allocating huge buffers for a garbage length header
+const walMaxRecordSize = 16 << 20
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// errWALCorrupt is returned when a record other than the last one fails validation
+	errWALCorrupt = errors.New("wal: corrupt record")
+)
+
+// walRecord is a single logged mutation
+type walRecord struct {
+	Seq  uint64        `json:"seq"`
+	Type walRecordType `json:"type"`
+	Bid  *model.Bid    `json:"bid,omitempty"`
+	Item *model.Item   `json:"item,omitempty"`
+}
+
+// encodeWALRecord frames a record as [length][crc32c][json payload]
+func encodeWALRecord(rec walRecord) ([]byte, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("wal: encode record %d: %w", rec.Seq, err)
+	}
+
+	buf := make([]byte, walHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[walHeaderSize:], payload)
+	return buf, nil
+}
+
+// readWAL replays every valid record in the log at path through fn.
+// It returns the offset just past the last valid record. A torn or corrupt final
+// record is tolerated (the caller truncates the file to the returned offset);
+// a bad record followed by further data is reported as errWALCorrupt.
+func readWAL(path string, fn func(walRecord) error) (int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("wal: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("wal: stat %s: %w", path, err)
+	}
+	size := info.Size()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	header := make([]byte, walHeaderSize)
+
+	for offset < size {
+		if _, err := io.ReadFull(r, header); err != nil {
+			// partial header at the tail: torn write
+			return offset, nil
+		}
+
+		length := int64(binary.LittleEndian.Uint32(header[0:4]))
+		checksum := binary.LittleEndian.Uint32(header[4:8])
+		end := offset + walHeaderSize + length
+
+		if length > walMaxRecordSize || end > size {
+			// the record claims more bytes than the file holds: torn write
+			return offset, nil
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return offset, nil
+		}
+
+		var rec walRecord
+		if crc32.Checksum(payload, crcTable) != checksum || json.Unmarshal(payload, &rec) != nil {
+			if end == size {
+				// last record in the file was only partially flushed
+				return offset, nil
+			}
+			return offset, fmt.Errorf("%w at offset %d in %s", errWALCorrupt, offset, path)
+		}
+
+		if err := fn(rec); err != nil {
+			return offset, err
+		}
+		offset = end
+	}
+
+	return offset, nil
+}
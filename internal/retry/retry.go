@@ -0,0 +1,25 @@
+package retry
+
+import "time"
+
+// Policy controls redelivery with exponential backoff
+type Policy struct {
+	MaxAttempts    int           // total attempts, including the first
+	InitialBackoff time.Duration // wait before the second attempt
+	MaxBackoff     time.Duration // cap on the wait between attempts
+}
+
+// Default retries up to 5 times, backing off from 1s to 1m
+var Default = Policy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute}
+
+// Backoff returns the wait before the given attempt (attempt 2 is the first retry)
+func (p Policy) Backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 2; i < attempt; i++ {
+		d *= 2
+		if d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,29 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Backoff(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	p := Policy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 2, want: time.Second},
+		{attempt: 3, want: 2 * time.Second},
+		{attempt: 4, want: 4 * time.Second},
+		{attempt: 5, want: 5 * time.Second},
+		{attempt: 9, want: 5 * time.Second},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.want, p.Backoff(tc.attempt), "attempt %d", tc.attempt)
+	}
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware lets handlers append the changes they make during a request to log,
+// attributed to the authenticated actor, the client IP and the request ID set by
+// TracingMiddleware, which must run first. Changes are appended as they are made, so a
+// request whose change cannot be audited fails instead of reporting success.
+func AuditMiddleware(log *audit.Log) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recorder := audit.NewRecorder(log, audit.Entry{
+			SourceIP:  c.ClientIP(),
+			RequestID: tracing.RequestIDFrom(c.Request.Context()),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+		})
+		c.Request = c.Request.WithContext(audit.WithRecorder(c.Request.Context(), recorder))
+		c.Next()
+	}
+}
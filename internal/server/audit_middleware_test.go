@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/auth"
+	bidding "bidding-tracker/internal/biddingService"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/notification"
+	"bidding-tracker/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that mutations are audited with actor, source IP, request ID and before/after values
+func TestAuditMiddleware(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: testSecret})
+	require.NoError(t, err)
+	log := audit.NewMemoryLog()
+	router := SetupRouter(Dependencies{
+		Bidding:     bidding.NewBiddingService(repo),
+		Auth:        verifier,
+		Preferences: notification.NewMemoryPreferences(),
+		Audit:       log,
+	})
+
+	send := func(method, path, body, user, requestID string, roles ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token(t, user, time.Minute, roles...))
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		req.RemoteAddr = "203.0.113.7:4711"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/bids", `{"item_id":"item1","amount":100}`, "user1", "req-1").Code)
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/bids", `{"item_id":"item1","amount":150}`, "user2", "req-2").Code)
+	require.Equal(t, http.StatusConflict, send(http.MethodPost, "/bids", `{"item_id":"item1","amount":120}`, "user1", "").Code, "rejected bids change nothing")
+	w := send(http.MethodPut, "/users/user1/notification-preferences", `{"email":"u1@example.com"}`, "user1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, w.Header().Get(RequestIDHeader), "a request ID is generated when missing")
+
+	entries := log.Query(audit.Filter{})
+	require.Len(t, entries, 3)
+
+	first, second := entries[0], entries[1]
+	require.Equal(t, audit.ActionBidPlaced, first.Action)
+	require.Equal(t, "user1", first.Actor)
+	require.Equal(t, "203.0.113.7", first.SourceIP)
+	require.Equal(t, "req-1", first.RequestID)
+	require.Equal(t, "item1", first.ItemID)
+	require.Nil(t, first.Before, "the first bid had no previous leader")
+
+	var before, after model.Bid
+	require.NoError(t, json.Unmarshal(second.Before, &before))
+	require.NoError(t, json.Unmarshal(second.After, &after))
+	require.Equal(t, "user1", before.UserID)
+	require.Equal(t, 150.0, after.Amount)
+	require.Equal(t, after.BidID, second.Resource)
+
+	prefs := entries[2]
+	require.Equal(t, audit.ActionPreferencesUpdated, prefs.Action)
+	require.Equal(t, w.Header().Get(RequestIDHeader), prefs.RequestID)
+	require.JSONEq(t, `{"user_id":"user1"}`, string(prefs.Before))
+
+	// the query API is admin-only
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/admin/audit?item_id=item1", "", "user1", "").Code)
+	w = send(http.MethodGet, "/admin/audit?item_id=item1&user_id=user2", "", "root", "", auth.RoleAdmin)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			Entries []audit.Entry `json:"entries"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Entries, 1)
+	require.Equal(t, "req-2", resp.Data.Entries[0].RequestID)
+}
+
+// Test that once the audit log failed, mutations are refused before they are made
+func TestAuditMiddleware_LogFailed(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: testSecret})
+	require.NoError(t, err)
+	log, err := audit.OpenLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	require.NoError(t, log.Close()) // every append fails
+	preferences := notification.NewMemoryPreferences()
+	router := SetupRouter(Dependencies{
+		Bidding:     bidding.NewBiddingService(repo),
+		Auth:        verifier,
+		Preferences: preferences,
+		Audit:       log,
+	})
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token(t, "user1", time.Minute))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// the failure is only found by the first append, after its bid was committed:
+	// that bid stands and is reported as accepted
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/bids", `{"item_id":"item1","amount":100}`).Code)
+	require.Error(t, log.Err())
+
+	w := send(http.MethodPost, "/bids", `{"item_id":"item1","amount":200}`)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+	w = send(http.MethodPut, "/users/user1/notification-preferences", `{"email":"u1@example.com"}`)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+
+	bids, err := repo.GetBidsByItem(context.Background(), "item1")
+	require.NoError(t, err)
+	require.Len(t, bids, 1, "refused bids are not recorded")
+	prefs, err := preferences.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	require.Empty(t, prefs.Email, "refused preferences are not stored")
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// errMissingToken is reported when a protected route is called without a bearer token
+	errMissingToken = errors.New("missing bearer token")
+	// errPermissionDenied is reported when the principal lacks a route's permission
+	errPermissionDenied = errors.New("permission denied")
+)
+
+// APIKeyHeader carries an API key for machine clients
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware authenticates the request with an API key (X-API-Key, or a bearer
+// token with the key prefix) or a bearer JWT, and stores the principal in the request
+// context. Either mechanism may be nil to disable it. X-API-Key values are always looked
+// up in the key store, so keys registered without the prefix (ADMIN_API_KEY) work there.
+func AuthMiddleware(verifier *auth.JWTVerifier, keys *auth.APIKeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		isKey := strings.HasPrefix(token, auth.APIKeyPrefix)
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			token, ok, isKey = key, true, true
+		}
+		if !ok {
+			unauthorized(c, errMissingToken, "authentication required")
+			return
+		}
+
+		var (
+			principal auth.Principal
+			err       error
+			message   string
+		)
+		switch {
+		case keys != nil && isKey:
+			principal, err = keys.Authenticate(token)
+			message = apiKeyErrorMessage(err)
+		case verifier != nil:
+			principal, err = verifier.Verify(token)
+			message = "invalid token"
+			if errors.Is(err, auth.ErrTokenExpired) {
+				message = "token expired"
+			}
+		default:
+			err = auth.ErrInvalidToken
+			message = "invalid token"
+		}
+		if err != nil {
+			unauthorized(c, err, message)
+			utils.WarnContext(c.Request.Context(), "AuthMiddleware: credentials rejected", map[string]any{"path": c.Request.URL.Path, "error": err.Error()})
+			return
+		}
+
+		// every later log line of the request names the caller
+		ctx := auth.WithPrincipal(c.Request.Context(), principal)
+		ctx = utils.WithLogger(ctx, utils.LoggerFrom(ctx).With(map[string]any{"actor": principal.UserID}))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// apiKeyErrorMessage maps an API key authentication error to a client message
+func apiKeyErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrAPIKeyExpired):
+		return "api key expired"
+	case errors.Is(err, auth.ErrAPIKeyRevoked):
+		return "api key revoked"
+	default:
+		return "invalid api key"
+	}
+}
+
+// RequirePermission rejects requests whose principal lacks perm with 403 permission_denied.
+// It must run after AuthMiddleware.
+func RequirePermission(perm auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFrom(c.Request.Context())
+		if !ok {
+			unauthorized(c, errMissingToken, "authentication required")
+			return
+		}
+		if !principal.Can(perm) {
+			utils.JSONErrorCode(c, http.StatusForbidden, auth.CodePermissionDenied, fmt.Errorf("%w: requires %s", errPermissionDenied, perm), "permission denied")
+			utils.WarnContext(c.Request.Context(), "RequirePermission: access denied", map[string]any{"path": c.Request.URL.Path, "user_id": principal.UserID, "permission": string(perm)})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// unauthorized aborts the request with a 401 and a bearer challenge
+func unauthorized(c *gin.Context, err error, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="bidding-tracker"`)
+	utils.JSONErrorCode(c, http.StatusUnauthorized, auth.CodeUnauthenticated, err, message)
+	c.Abort()
+}
@@ -0,0 +1,405 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/auth"
+	bidding "bidding-tracker/internal/biddingService"
+	"bidding-tracker/internal/config"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/notification"
+	"bidding-tracker/internal/repository"
+	"bidding-tracker/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("router-test-secret")
+
+// newAuthRouter builds the full router with JWT authentication enabled
+func newAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	keys, err := auth.NewAPIKeyStore("")
+	require.NoError(t, err)
+	return newAuthRouterWithKeys(t, keys)
+}
+
+// newAuthRouterWithKeys builds the full router accepting JWTs and keys from the store
+func newAuthRouterWithKeys(t *testing.T, keys *auth.APIKeyStore) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10, Owner: "user1"}))
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item2", Title: "Item 2", StartingPrice: 10, Owner: "seller2"}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: testSecret})
+	require.NoError(t, err)
+	webhooks := webhook.NewService(webhook.Config{})
+	t.Cleanup(webhooks.Close)
+
+	return SetupRouter(Dependencies{
+		Bidding:     bidding.NewBiddingService(repo),
+		Auth:        verifier,
+		APIKeys:     keys,
+		Preferences: notification.NewMemoryPreferences(),
+		Webhooks:    webhooks,
+		Changes:     repo,
+		Config:      config.Default(),
+	})
+}
+
+// token signs an HS256 token for a user with optional roles
+func token(t *testing.T, userID string, ttl time.Duration, roles ...string) string {
+	t.Helper()
+	now := time.Now()
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{Roles: roles, RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   userID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}}).SignedString(testSecret)
+	require.NoError(t, err)
+	return s
+}
+
+// Test that bids and user item lists use the authenticated identity
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		authHeader     string
+		expectedStatus int
+		expectedMsg    string
+		wantUserID     string
+	}{
+		{
+			name:           "bid_as_token_user",
+			method:         http.MethodPost,
+			path:           "/bids",
+			body:           `{"item_id":"item1","amount":100}`,
+			authHeader:     "Bearer " + token(t, "user1", time.Minute),
+			expectedStatus: http.StatusCreated,
+			expectedMsg:    "bid recorded successfully",
+			wantUserID:     "user1",
+		},
+		{
+			name:           "matching_body_user_allowed",
+			method:         http.MethodPost,
+			path:           "/bids",
+			body:           `{"item_id":"item1","user_id":"user1","amount":100}`,
+			authHeader:     "Bearer " + token(t, "user1", time.Minute),
+			expectedStatus: http.StatusCreated,
+			expectedMsg:    "bid recorded successfully",
+			wantUserID:     "user1",
+		},
+		{
+			name:           "bid_as_other_user_forbidden",
+			method:         http.MethodPost,
+			path:           "/bids",
+			body:           `{"item_id":"item1","user_id":"user2","amount":100}`,
+			authHeader:     "Bearer " + token(t, "user1", time.Minute),
+			expectedStatus: http.StatusForbidden,
+			expectedMsg:    "cannot bid on behalf of another user",
+		},
+		{
+			name:           "probes_need_no_token",
+			method:         http.MethodGet,
+			path:           "/readyz",
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "ready",
+		},
+		{
+			name:           "missing_token",
+			method:         http.MethodPost,
+			path:           "/bids",
+			body:           `{"item_id":"item1","user_id":"user1","amount":100}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedMsg:    "authentication required",
+		},
+		{
+			name:           "expired_token",
+			method:         http.MethodPost,
+			path:           "/bids",
+			body:           `{"item_id":"item1","amount":100}`,
+			authHeader:     "Bearer " + token(t, "user1", -time.Minute),
+			expectedStatus: http.StatusUnauthorized,
+			expectedMsg:    "token expired",
+		},
+		{
+			name:           "bad_signature",
+			method:         http.MethodPost,
+			path:           "/bids",
+			body:           `{"item_id":"item1","amount":100}`,
+			authHeader:     "Bearer " + token(t, "user1", time.Minute) + "x",
+			expectedStatus: http.StatusUnauthorized,
+			expectedMsg:    "invalid token",
+		},
+		{
+			name:           "own_items",
+			method:         http.MethodGet,
+			path:           "/users/user1/items",
+			authHeader:     "Bearer " + token(t, "user1", time.Minute),
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "items retrieved successfully",
+		},
+		{
+			name:           "other_users_items_forbidden",
+			method:         http.MethodGet,
+			path:           "/users/user2/items",
+			authHeader:     "Bearer " + token(t, "user1", time.Minute),
+			expectedStatus: http.StatusForbidden,
+			expectedMsg:    "cannot read another user's items",
+		},
+		{
+			name:           "item_reads_need_a_token",
+			method:         http.MethodGet,
+			path:           "/items/item1/bids",
+			expectedStatus: http.StatusUnauthorized,
+			expectedMsg:    "authentication required",
+		},
+		{
+			name:           "item_reads_with_token",
+			method:         http.MethodGet,
+			path:           "/items/item1/bids",
+			authHeader:     "Bearer " + token(t, "user1", time.Minute),
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "bids retrieved successfully",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			router := newAuthRouter(t)
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedMsg, resp["message"])
+			if tc.expectedStatus == http.StatusUnauthorized {
+				require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+			if tc.wantUserID != "" {
+				require.Equal(t, tc.wantUserID, resp["data"].(map[string]any)["user_id"])
+			}
+		})
+	}
+}
+
+// Test per-route permissions and ownership checks for each role
+func TestAuthorization(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		roles          []string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "bidder_places_bid", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":100}`, roles: []string{auth.RoleBidder}, expectedStatus: http.StatusCreated},
+		{name: "no_roles_is_bidder", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":100}`, expectedStatus: http.StatusCreated},
+		{name: "seller_cannot_bid", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":100}`, roles: []string{auth.RoleSeller}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodePermissionDenied},
+		{name: "bidder_reads_own_history", method: http.MethodGet, path: "/users/user1/items", roles: []string{auth.RoleBidder}, expectedStatus: http.StatusOK},
+		{name: "bidder_cannot_read_other_history", method: http.MethodGet, path: "/users/user2/items", roles: []string{auth.RoleBidder}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodeNotOwner},
+		{name: "admin_reads_other_history", method: http.MethodGet, path: "/users/user2/items", roles: []string{auth.RoleAdmin}, expectedStatus: http.StatusOK},
+		{name: "bidder_cannot_read_other_preferences", method: http.MethodGet, path: "/users/user2/notification-preferences", roles: []string{auth.RoleBidder}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodeNotOwner},
+		{name: "bidder_updates_own_preferences", method: http.MethodPut, path: "/users/user1/notification-preferences", body: `{}`, roles: []string{auth.RoleBidder}, expectedStatus: http.StatusOK},
+		{name: "bidder_cannot_read_changes", method: http.MethodGet, path: "/changes", roles: []string{auth.RoleBidder}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodePermissionDenied},
+		{name: "admin_reads_changes", method: http.MethodGet, path: "/changes", roles: []string{auth.RoleAdmin}, expectedStatus: http.StatusOK},
+		{name: "seller_cannot_manage_webhooks", method: http.MethodGet, path: "/webhooks", roles: []string{auth.RoleSeller}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodePermissionDenied},
+		{name: "admin_manages_webhooks", method: http.MethodGet, path: "/webhooks", roles: []string{auth.RoleAdmin}, expectedStatus: http.StatusOK},
+		{name: "seller_reads_bids", method: http.MethodGet, path: "/items/item1/bids", roles: []string{auth.RoleSeller}, expectedStatus: http.StatusOK},
+		{name: "bidder_cannot_close_auction", method: http.MethodPost, path: "/items/item1/close", roles: []string{auth.RoleBidder}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodePermissionDenied},
+		{name: "seller_closes_auction", method: http.MethodPost, path: "/items/item1/close", roles: []string{auth.RoleSeller}, expectedStatus: http.StatusOK},
+		{name: "seller_cannot_close_other_sellers_auction", method: http.MethodPost, path: "/items/item2/close", roles: []string{auth.RoleSeller}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodeNotOwner},
+		{name: "admin_closes_any_auction", method: http.MethodPost, path: "/items/item2/close", roles: []string{auth.RoleAdmin}, expectedStatus: http.StatusOK},
+		{name: "close_unknown_item", method: http.MethodPost, path: "/items/nope/close", roles: []string{auth.RoleSeller}, expectedStatus: http.StatusNotFound},
+		{name: "bidder_cannot_read_config", method: http.MethodGet, path: "/debug/config", roles: []string{auth.RoleBidder}, expectedStatus: http.StatusForbidden, expectedCode: auth.CodePermissionDenied},
+		{name: "admin_reads_config", method: http.MethodGet, path: "/debug/config", roles: []string{auth.RoleAdmin}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			router := newAuthRouter(t)
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token(t, "user1", time.Minute, tc.roles...))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code, w.Body.String())
+			if tc.expectedCode != "" {
+				var resp map[string]any
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, tc.expectedCode, resp["code"])
+			}
+		})
+	}
+}
+
+// Test API key authentication next to JWTs, scopes and the admin key endpoint
+func TestAPIKeyAuthentication(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	keys, err := auth.NewAPIKeyStore("")
+	require.NoError(t, err)
+	_, bidKey, err := keys.Issue(auth.KeyRequest{OwnerID: "partner1", Scopes: []auth.Permission{auth.PermBidsWrite}})
+	require.NoError(t, err)
+	_, readKey, err := keys.Issue(auth.KeyRequest{OwnerID: "partner2", Scopes: []auth.Permission{auth.PermItemsRead}})
+	require.NoError(t, err)
+	_, adminKey, err := keys.Issue(auth.KeyRequest{OwnerID: "ops", Scopes: []auth.Permission{auth.PermAdmin}})
+	require.NoError(t, err)
+	revoked, revokedKey, err := keys.Issue(auth.KeyRequest{OwnerID: "partner3", Scopes: []auth.Permission{auth.PermBidsWrite}})
+	require.NoError(t, err)
+	_, err = keys.Revoke(revoked.ID)
+	require.NoError(t, err)
+	router := newAuthRouterWithKeys(t, keys)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		header         string
+		value          string
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{name: "bid_with_key_header", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":100}`, header: APIKeyHeader, value: bidKey, expectedStatus: http.StatusCreated, expectedMsg: "bid recorded successfully"},
+		{name: "bid_with_bearer_key", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":200}`, header: "Authorization", value: "Bearer " + bidKey, expectedStatus: http.StatusCreated, expectedMsg: "bid recorded successfully"},
+		{name: "jwt_still_accepted", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":300}`, header: "Authorization", value: "Bearer " + token(t, "user1", time.Minute), expectedStatus: http.StatusCreated, expectedMsg: "bid recorded successfully"},
+		{name: "scope_missing", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":400}`, header: APIKeyHeader, value: readKey, expectedStatus: http.StatusForbidden, expectedMsg: "permission denied"},
+		{name: "revoked_key", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":400}`, header: APIKeyHeader, value: revokedKey, expectedStatus: http.StatusUnauthorized, expectedMsg: "api key revoked"},
+		{name: "unknown_key", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","amount":400}`, header: APIKeyHeader, value: auth.APIKeyPrefix + "unknown", expectedStatus: http.StatusUnauthorized, expectedMsg: "invalid api key"},
+		{name: "read_with_read_key", method: http.MethodGet, path: "/items/item1/winning", header: APIKeyHeader, value: readKey, expectedStatus: http.StatusOK, expectedMsg: "winning bid retrieved successfully"},
+		{name: "read_scope_missing", method: http.MethodGet, path: "/items/item1/bids", header: APIKeyHeader, value: bidKey, expectedStatus: http.StatusForbidden, expectedMsg: "permission denied"},
+		{name: "non_admin_cannot_list_keys", method: http.MethodGet, path: "/admin/api-keys", header: APIKeyHeader, value: bidKey, expectedStatus: http.StatusForbidden, expectedMsg: "permission denied"},
+		{name: "admin_lists_keys", method: http.MethodGet, path: "/admin/api-keys", header: APIKeyHeader, value: adminKey, expectedStatus: http.StatusOK, expectedMsg: "api keys retrieved successfully"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { // sequential: the cases share one router
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(tc.header, tc.value)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedMsg, resp["message"])
+		})
+	}
+
+	for _, key := range keys.List() {
+		if key.OwnerID == "partner1" {
+			require.Equal(t, uint64(4), key.RequestCount) // authenticated requests count even when a scope is missing
+		}
+	}
+}
+
+// Test that a bootstrap admin key without the key prefix is accepted in X-API-Key
+func TestAPIKeyAuthentication_BootstrapKey(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	const bootstrap = "operator-chosen-admin-secret-0123456789"
+	keys, err := auth.NewAPIKeyStore("")
+	require.NoError(t, err)
+	_, err = keys.Register(bootstrap, auth.KeyRequest{Name: "bootstrap", OwnerID: "admin", Scopes: []auth.Permission{auth.PermAdmin}})
+	require.NoError(t, err)
+	router := newAuthRouterWithKeys(t, keys)
+
+	tests := []struct {
+		name           string
+		header         string
+		value          string
+		expectedStatus int
+	}{
+		{name: "key_header", header: APIKeyHeader, value: bootstrap, expectedStatus: http.StatusOK},
+		{name: "bearer_is_a_jwt", header: "Authorization", value: "Bearer " + bootstrap, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { // sequential: the cases share one router
+			req := httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil)
+			req.Header.Set(tc.header, tc.value)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+// Test that without authentication the bidding routes are open but admin routes are not served
+func TestSetupRouter_AuthDisabled(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	webhooks := webhook.NewService(webhook.Config{})
+	t.Cleanup(webhooks.Close)
+	router := SetupRouter(Dependencies{
+		Bidding:     bidding.NewBiddingService(repo),
+		Audit:       audit.NewMemoryLog(),
+		Webhooks:    webhooks,
+		Changes:     repo,
+		Config:      config.Default(),
+		Preferences: notification.NewMemoryPreferences(),
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "bid", method: http.MethodPost, path: "/bids", body: `{"item_id":"item1","user_id":"user1","amount":100}`, expectedStatus: http.StatusCreated},
+		{name: "read_bids", method: http.MethodGet, path: "/items/item1/bids", expectedStatus: http.StatusOK},
+		{name: "close_auction", method: http.MethodPost, path: "/items/item1/close", expectedStatus: http.StatusNotFound},
+		{name: "changes", method: http.MethodGet, path: "/changes", expectedStatus: http.StatusNotFound},
+		{name: "create_webhook", method: http.MethodPost, path: "/webhooks", body: `{"url":"http://169.254.169.254/"}`, expectedStatus: http.StatusNotFound},
+		{name: "audit", method: http.MethodGet, path: "/admin/audit", expectedStatus: http.StatusNotFound},
+		{name: "config", method: http.MethodGet, path: "/debug/config", expectedStatus: http.StatusNotFound},
+		{name: "read_preferences", method: http.MethodGet, path: "/users/user1/notification-preferences", expectedStatus: http.StatusNotFound},
+		{name: "update_preferences", method: http.MethodPut, path: "/users/user1/notification-preferences", body: `{"email":"attacker@example.com"}`, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { // sequential: the cases share one router
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedStatus, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"bidding-tracker/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	httpRequests = metrics.Default.NewCounterVec("http_requests_total",
+		"HTTP requests served, by method, route and status.", "method", "route", "status")
+	httpDuration = metrics.Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency, by method, route and status.", metrics.DefBuckets, "method", "route", "status")
+)
+
+// MetricsMiddleware counts requests and observes their latency. Routes are labelled by
+// their pattern (e.g. /items/:item_id/bids) so item and user IDs do not multiply series.
+func MetricsMiddleware(c *gin.Context) {
+	start := time.Now()
+
+	c.Next() // process request
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := strconv.Itoa(c.Writer.Status())
+	httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+	httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bidding "bidding-tracker/internal/biddingService"
+	"bidding-tracker/internal/metrics"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that requests are counted by route pattern and served at /metrics
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	router := SetupRouter(Dependencies{Bidding: bidding.NewBiddingService(repo)})
+
+	postBids := httpRequests.WithLabelValues(http.MethodPost, "/bids", "201")
+	unmatched := httpRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	latency := httpDuration.WithLabelValues(http.MethodPost, "/bids", "201")
+	bidsBefore, unmatchedBefore, latencyBefore := postBids.Value(), unmatched.Value(), latency.Count()
+
+	for _, body := range []string{`{"item_id":"item1","user_id":"user1","amount":100}`, `{"item_id":"item1","user_id":"user2","amount":200}`} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bids", strings.NewReader(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/no/such/route", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// other tests share the default registry, so only a lower bound holds
+	require.GreaterOrEqual(t, postBids.Value()-bidsBefore, 2.0)
+	require.GreaterOrEqual(t, unmatched.Value()-unmatchedBefore, 1.0)
+	require.GreaterOrEqual(t, latency.Count()-latencyBefore, uint64(2))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	require.Contains(t, body, "# TYPE http_requests_total counter")
+	require.Contains(t, body, `http_requests_total{method="POST",route="/bids",status="201"}`)
+	require.Contains(t, body, `http_request_duration_seconds_bucket{method="POST",route="/bids",status="201",le="+Inf"}`)
+	require.Contains(t, body, `bids_total{result="accepted",reason=""}`)
+	require.Contains(t, body, `repository_lock_wait_seconds_count{op="record_bid"}`)
+}
@@ -7,15 +7,31 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// RequestLoggerMiddleware logs incoming requests with timing
+// quietPaths are polled by the orchestrator and Prometheus every few seconds and not
+// worth logging; failing readiness checks are logged by the handler
+var quietPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// RequestLoggerMiddleware gives the request its own logger, so every line logged while
+// serving it names the method and path, and logs the request with timing when done
 func RequestLoggerMiddleware(c *gin.Context) {
 	start := time.Now()
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(utils.WithLogger(ctx, utils.LoggerFrom(ctx).With(map[string]any{
+		"method": c.Request.Method,
+		"path":   c.Request.URL.Path,
+	})))
 
 	c.Next() // process request
 
-	utils.Info("HTTP Request", map[string]any{
-		"method":  c.Request.Method,
-		"path":    c.Request.URL.Path,
+	if quietPaths[c.Request.URL.Path] {
+		return
+	}
+
+	utils.InfoContext(c.Request.Context(), "HTTP Request", map[string]any{
 		"status":  c.Writer.Status(),
 		"latency": time.Since(start).String(),
 	})
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/internal/metrics"
+	"bidding-tracker/internal/ratelimit"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CodeRateLimited is the error code of requests rejected for exceeding their rate limit
+const CodeRateLimited = "rate_limited"
+
+// errRateLimited is reported when a client has no tokens left in its bucket
+var errRateLimited = errors.New("rate limit exceeded")
+
+var rateLimited = metrics.Default.NewCounterVec("http_rate_limited_total",
+	"Requests rejected by a rate limit, by route group.", "group")
+
+// RateLimits are the per-client limits of each route group. A zero Limit leaves the
+// group unlimited.
+type RateLimits struct {
+	Bids  ratelimit.Limit // POST /bids
+	Reads ratelimit.Limit // /items, /users and /stream
+}
+
+// RateLimitMiddleware takes a token from the client's bucket in limiter and rejects the
+// request with 429 when there is none. Clients are keyed by authenticated user, so it must
+// run after AuthMiddleware, and by client IP on open routes. group labels the metrics.
+func RateLimitMiddleware(group string, limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if p, ok := auth.PrincipalFrom(c.Request.Context()); ok && p.UserID != "" {
+			key = "user:" + p.UserID
+		}
+
+		d := limiter.Allow(key)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		c.Header("X-RateLimit-Reset", seconds(d.Reset))
+		if !d.Allowed {
+			c.Header("Retry-After", seconds(d.RetryAfter))
+			utils.JSONErrorCode(c, http.StatusTooManyRequests, CodeRateLimited, errRateLimited, "too many requests")
+			c.Abort()
+			rateLimited.WithLabelValues(group).Inc()
+			utils.InfoSampledContext(c.Request.Context(), "RateLimitMiddleware: request rejected", map[string]any{"group": group, "client": key})
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds formats d as whole seconds, rounded up so clients never retry too early
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/auth"
+	bidding "bidding-tracker/internal/biddingService"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/ratelimit"
+	"bidding-tracker/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that bid writes and reads have separate limits, keyed by user or client IP
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: testSecret})
+	require.NoError(t, err)
+	// slow enough that no token refills during the test
+	router := SetupRouter(Dependencies{
+		Bidding: bidding.NewBiddingService(repo),
+		Auth:    verifier,
+		RateLimits: RateLimits{
+			Bids:  ratelimit.Limit{PerSecond: 0.01, Burst: 2},
+			Reads: ratelimit.Limit{PerSecond: 0.01, Burst: 3},
+		},
+	})
+
+	serve := func(method, path, userID, ip string, amount int) *httptest.ResponseRecorder {
+		var body io.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(fmt.Sprintf(`{"item_id":"item1","amount":%d}`, amount))
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.RemoteAddr = ip + ":1234"
+		if userID != "" {
+			req.Header.Set("Authorization", "Bearer "+token(t, userID, time.Hour))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	rejected := rateLimited.WithLabelValues("bids")
+	rejectedBefore := rejected.Value()
+
+	// user1 spends the bid burst from two different IPs
+	w := serve(http.MethodPost, "/bids", "user1", "10.0.0.1", 100)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	require.Equal(t, "100", w.Header().Get("X-RateLimit-Reset"))
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/bids", "user1", "10.0.0.2", 110).Code)
+
+	w = serve(http.MethodPost, "/bids", "user1", "10.0.0.3", 120)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "100", w.Header().Get("Retry-After"))
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	require.Contains(t, w.Body.String(), `"code":"rate_limited"`)
+	require.GreaterOrEqual(t, rejected.Value()-rejectedBefore, 1.0, "rejections are counted")
+
+	// another user behind the same IP has a bucket of its own
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/bids", "user2", "10.0.0.1", 130).Code)
+
+	// reads are limited separately, per user
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, serve(http.MethodGet, "/items/item1/bids", "user1", "10.0.0.1", 0).Code)
+	}
+	w = serve(http.MethodGet, "/items/item1/winning", "user1", "10.0.0.2", 0)
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "read routes share one bucket")
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/items/item1/bids", "user2", "10.0.0.1", 0).Code)
+
+	// probes are never limited
+	for i := 0; i < 5; i++ {
+		w = serve(http.MethodGet, "/healthz", "", "10.0.0.1", 0)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+// Test that without limits no rate limit headers are sent
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	router := SetupRouter(Dependencies{Bidding: bidding.NewBiddingService(repo)})
+
+	for i := 0; i < 20; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/item1/bids", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+// Test that X-Forwarded-For only picks the client's bucket behind a trusted proxy
+func TestRateLimitMiddleware_ForwardedFor(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	router := SetupRouter(Dependencies{
+		Bidding:        bidding.NewBiddingService(repo),
+		RateLimits:     RateLimits{Reads: ratelimit.Limit{PerSecond: 0.01, Burst: 1}},
+		TrustedProxies: []string{"10.0.0.100"},
+	})
+
+	tests := []struct {
+		name           string
+		remoteIP       string
+		forwardedFor   string
+		expectedStatus int
+	}{
+		{name: "direct_client", remoteIP: "203.0.113.9", forwardedFor: "198.51.100.1", expectedStatus: http.StatusOK},
+		{name: "spoofed_header_same_bucket", remoteIP: "203.0.113.9", forwardedFor: "198.51.100.2", expectedStatus: http.StatusTooManyRequests},
+		{name: "client_behind_proxy", remoteIP: "10.0.0.100", forwardedFor: "198.51.100.1", expectedStatus: http.StatusOK},
+		{name: "other_client_behind_proxy", remoteIP: "10.0.0.100", forwardedFor: "198.51.100.2", expectedStatus: http.StatusOK},
+		{name: "same_client_behind_proxy", remoteIP: "10.0.0.100", forwardedFor: "198.51.100.1", expectedStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { // sequential: the cases share one router and its buckets
+			req := httptest.NewRequest(http.MethodGet, "/items/item1/bids", nil)
+			req.RemoteAddr = tc.remoteIP + ":1234"
+			req.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
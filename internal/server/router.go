@@ -1,36 +1,200 @@
 package server
 
 import (
+	"bidding-tracker/client"
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/auth"
 	bidding "bidding-tracker/internal/biddingService"
+	"bidding-tracker/internal/config"
+	"bidding-tracker/internal/health"
+	"bidding-tracker/internal/metrics"
+	"bidding-tracker/internal/notification"
+	"bidding-tracker/internal/ratelimit"
+	"bidding-tracker/internal/receipt"
+	"bidding-tracker/internal/repository"
+	"bidding-tracker/internal/stream"
+	"bidding-tracker/internal/tracing"
+	"bidding-tracker/internal/webhook"
+	apikeyhandler "bidding-tracker/services/apikey/handler"
+	audithandler "bidding-tracker/services/audit/handler"
 	handler "bidding-tracker/services/bidding/handler"
+	changeshandler "bidding-tracker/services/changes/handler"
+	confighandler "bidding-tracker/services/config/handler"
+	healthhandler "bidding-tracker/services/health/handler"
+	notificationhandler "bidding-tracker/services/notification/handler"
+	webhookhandler "bidding-tracker/services/webhook/handler"
+	"bidding-tracker/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Dependencies are the services the router exposes over HTTP
+type Dependencies struct {
+	Bidding        *bidding.BiddingService
+	Health         *health.Checker              // optional; readiness checks behind GET /readyz
+	Auth           *auth.JWTVerifier            // optional; when set, routes declare the permissions they require
+	APIKeys        *auth.APIKeyStore            // optional; accepts API keys and enables /admin/api-keys
+	Audit          *audit.Log                   // optional; records mutations and enables GET /admin/audit
+	Tracer         *tracing.Tracer              // optional; exports request, service and repository spans
+	Config         *config.Config               // optional; enables GET /debug/config
+	Receipts       *receipt.Signer              // optional; signs accepted bids and publishes the key
+	Stream         *stream.Hub                  // optional; enables GET /stream and GET /items/:item_id/events
+	Preferences    notification.PreferenceStore // optional; enables /users/:user_id/notification-preferences when auth is enabled
+	Webhooks       *webhook.Service             // optional; enables /webhooks
+	Changes        repository.ChangeFeed        // optional; enables GET /changes
+	RateLimits     RateLimits                   // optional; per-user or per-IP limits of bid writes and reads
+	TrustedProxies []string                     // optional; proxy IPs or CIDRs whose X-Forwarded-For gives the client IP; none by default
+}
+
 // SetupRouter configures all Gin routes for the application
-func SetupRouter(biddingService *bidding.BiddingService) *gin.Engine {
+func SetupRouter(deps Dependencies) *gin.Engine {
 	router := gin.New() // New router without default middleware for full control over middleware and logging
+	// the client IP keys rate limits and audit entries, so forwarded headers are only
+	// believed from configured proxies; Gin's default trusts every peer
+	if err := router.SetTrustedProxies(deps.TrustedProxies); err != nil {
+		utils.Error("Router: invalid trusted proxies, trusting none", map[string]any{"error": err.Error()})
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	router.Use(gin.Recovery())                 // recover from panics
+	router.Use(TracingMiddleware(deps.Tracer)) // request ID and trace context for logs, spans and audit
+	router.Use(RequestLoggerMiddleware)        // custom request logging
+	router.Use(MetricsMiddleware)              // request counts and latency for /metrics
+	if deps.Audit != nil {
+		router.Use(AuditMiddleware(deps.Audit)) // record mutations with actor, source IP and request ID
+	}
 
-	router.Use(gin.Recovery())          // recover from panics
-	router.Use(RequestLoggerMiddleware) // custom request logging
+	// probes are always served and never authenticated
+	checker := deps.Health
+	if checker == nil {
+		checker = health.NewChecker(0)
+	}
+	healthHandler := healthhandler.NewHealthHandler(checker)
+	router.GET("/healthz", healthHandler.LivenessHandler)
+	router.GET("/readyz", healthHandler.ReadinessHandler)
+	router.GET("/metrics", gin.WrapH(metrics.Default.Handler()))
 
-	biddingHandler := handler.NewBiddingHandler(biddingService)
+	var biddingOpts []handler.Option
+	if deps.Receipts != nil {
+		biddingOpts = append(biddingOpts, handler.WithReceipts(deps.Receipts))
+		receiptKeysHandler := handler.NewReceiptKeysHandler(deps.Receipts.KeySet())
+		router.GET(client.ReceiptKeysPath, receiptKeysHandler.GetReceiptKeysHandler)
+	}
+	biddingHandler := handler.NewBiddingHandler(deps.Bidding, biddingOpts...)
 
-	bids := router.Group("/bids")
+	// secured returns the middleware for a route group: authentication, plus perm when set.
+	// Handlers of authenticated routes take the user from the token and check ownership.
+	// Without a verifier or key store the bidding routes are open.
+	authEnabled := deps.Auth != nil || deps.APIKeys != nil
+	secured := func(perm auth.Permission) []gin.HandlerFunc {
+		if !authEnabled {
+			return nil
+		}
+		chain := []gin.HandlerFunc{AuthMiddleware(deps.Auth, deps.APIKeys)}
+		if perm != "" {
+			chain = append(chain, RequirePermission(perm))
+		}
+		return chain
+	}
+
+	// privileged returns the middleware of a route only some principals may call, and false
+	// when authentication is disabled: such routes are then not served rather than left open
+	privileged := func(perm auth.Permission) ([]gin.HandlerFunc, bool) {
+		if !authEnabled {
+			return nil, false
+		}
+		return secured(perm), true
+	}
+
+	// limited returns the rate limit middleware for a route group, or none when the limit
+	// is zero. It follows secured so authenticated clients are limited per user.
+	limited := func(group string, limit ratelimit.Limit) []gin.HandlerFunc {
+		if limit.Unlimited() {
+			return nil
+		}
+		return []gin.HandlerFunc{RateLimitMiddleware(group, ratelimit.NewLimiter(ratelimit.Config{Limit: limit}))}
+	}
+	// read routes share one bucket per client
+	reads := limited("reads", deps.RateLimits.Reads)
+
+	bids := router.Group("/bids", append(secured(auth.PermBidsWrite), limited("bids", deps.RateLimits.Bids)...)...)
 	{
 		bids.POST("", biddingHandler.RecordBidHandler)
 	}
 
-	items := router.Group("/items")
+	items := router.Group("/items", append(secured(auth.PermItemsRead), reads...)...)
 	{
 		items.GET("/:item_id/bids", biddingHandler.GetBidsByItemHandler)
 		items.GET("/:item_id/winning", biddingHandler.GetWinningBidHandler)
+		items.GET("/:item_id/chain/verify", biddingHandler.VerifyChainHandler)
+	}
+	if chain, ok := privileged(auth.PermItemsWrite); ok {
+		items.POST("/:item_id/close", append(chain, biddingHandler.CloseAuctionHandler)...)
 	}
 
-	users := router.Group("/users")
+	users := router.Group("/users", append(secured(""), reads...)...) // owner-scoped: the user, or a principal with users:read
 	{
 		users.GET("/:user_id/items", biddingHandler.GetItemsByUserHandler)
 	}
 
+	// preferences hold contact details: like the admin routes they are not served when
+	// authentication is disabled, since nothing would tie a request to its user
+	if deps.Preferences != nil && authEnabled {
+		preferencesHandler := notificationhandler.NewPreferencesHandler(deps.Preferences)
+		users.GET("/:user_id/notification-preferences", preferencesHandler.GetPreferencesHandler)
+		users.PUT("/:user_id/notification-preferences", preferencesHandler.PutPreferencesHandler)
+	}
+
+	if deps.Stream != nil {
+		streamHandler := handler.NewStreamHandler(deps.Stream, 0)
+		router.GET("/stream", append(append(secured(auth.PermItemsRead), reads...), streamHandler.StreamHandler)...)
+		items.GET("/:item_id/events", streamHandler.ItemEventsHandler)
+	}
+
+	// admin routes are only served when authentication is enabled
+	admin, ok := privileged(auth.PermAdmin)
+	if !ok {
+		return router
+	}
+
+	if deps.Changes != nil {
+		changesHandler := changeshandler.NewChangesHandler(deps.Changes)
+		router.GET("/changes", append(admin, changesHandler.GetChangesHandler)...)
+	}
+
+	if deps.Webhooks != nil {
+		webhookHandler := webhookhandler.NewWebhookHandler(deps.Webhooks)
+		webhooks := router.Group("/webhooks", admin...)
+		{
+			webhooks.POST("", webhookHandler.CreateWebhookHandler)
+			webhooks.GET("", webhookHandler.ListWebhooksHandler)
+			webhooks.GET("/:id", webhookHandler.GetWebhookHandler)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhookHandler)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveriesHandler)
+			webhooks.GET("/:id/dead-letters", webhookHandler.GetDeadLettersHandler)
+		}
+	}
+
+	if deps.APIKeys != nil {
+		apiKeyHandler := apikeyhandler.NewAPIKeyHandler(deps.APIKeys)
+		apiKeys := router.Group("/admin/api-keys", admin...)
+		{
+			apiKeys.POST("", apiKeyHandler.CreateAPIKeyHandler)
+			apiKeys.GET("", apiKeyHandler.ListAPIKeysHandler)
+			apiKeys.GET("/:key_id", apiKeyHandler.GetAPIKeyHandler)
+			apiKeys.DELETE("/:key_id", apiKeyHandler.RevokeAPIKeyHandler)
+		}
+	}
+
+	if deps.Audit != nil {
+		auditHandler := audithandler.NewAuditHandler(deps.Audit)
+		router.GET("/admin/audit", append(admin, auditHandler.GetAuditLogHandler)...)
+	}
+
+	if deps.Config != nil {
+		configHandler := confighandler.NewConfigHandler(deps.Config)
+		router.GET("/debug/config", append(admin, configHandler.GetConfigHandler)...)
+	}
+
 	return router
 }
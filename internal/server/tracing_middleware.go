@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"bidding-tracker/internal/tracing"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader carries the caller's request ID; one is generated when it is missing
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader carries the W3C trace context
+	TraceparentHeader = "traceparent"
+)
+
+// maxRequestIDLength bounds caller-supplied request IDs, which end up in every log line
+const maxRequestIDLength = 128
+
+// TracingMiddleware accepts or generates the request ID and trace context, stores them in
+// the request context for logs and spans, and echoes both in the response headers. The
+// request is timed as a server span, a child of the caller's span when a valid traceparent
+// was sent. Spans are exported through tracer, which may be nil to only propagate IDs.
+func TracingMiddleware(tracer *tracing.Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = utils.GenerateID()
+		}
+
+		ctx := tracing.WithRequestID(c.Request.Context(), requestID)
+		if tracer != nil {
+			ctx = tracing.WithTracer(ctx, tracer)
+		}
+		if parent, ok := tracing.ParseTraceparent(c.GetHeader(TraceparentHeader)); ok {
+			ctx = tracing.WithSpanContext(ctx, parent)
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracing.StartSpan(ctx, c.Request.Method+" "+route)
+		sc, _ := tracing.SpanContextFrom(ctx)
+
+		c.Header(RequestIDHeader, requestID)
+		c.Header(TraceparentHeader, sc.Traceparent())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.method", c.Request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.status_code", strconv.Itoa(status))
+		if status >= 500 {
+			span.SetError(fmt.Errorf("server error: status %d", status))
+		}
+		span.End()
+	}
+}
+
+// validRequestID accepts printable ASCII IDs of bounded length, so a caller cannot
+// inject log or header content through them
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
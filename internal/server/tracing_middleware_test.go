@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bidding "bidding-tracker/internal/biddingService"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/repository"
+	"bidding-tracker/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that request IDs and trace context are accepted or generated and echoed back
+func TestTracingMiddleware_Headers(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name          string
+		requestID     string
+		traceparent   string
+		wantRequestID string // empty when one must be generated
+		wantTraceID   string // empty when a new trace must be started
+	}{
+		{name: "generated"},
+		{name: "echoed", requestID: "req-42", wantRequestID: "req-42"},
+		{name: "invalid_replaced", requestID: "bad id\nwith newline"},
+		{name: "too_long_replaced", requestID: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "trace_continued", traceparent: parent, wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "malformed_traceparent", traceparent: "00-nope-01"},
+	}
+
+	router := SetupRouter(Dependencies{Bidding: bidding.NewBiddingService(repository.NewMemoryRepo())})
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			req := httptest.NewRequest(http.MethodGet, "/items/item1/bids", nil)
+			if tc.requestID != "" {
+				req.Header.Set(RequestIDHeader, tc.requestID)
+			}
+			if tc.traceparent != "" {
+				req.Header.Set(TraceparentHeader, tc.traceparent)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			require.NotEmpty(t, requestID)
+			if tc.wantRequestID != "" {
+				require.Equal(t, tc.wantRequestID, requestID)
+			} else {
+				require.NotEqual(t, tc.requestID, requestID)
+			}
+
+			sc, ok := tracing.ParseTraceparent(w.Header().Get(TraceparentHeader))
+			require.True(t, ok)
+			if tc.wantTraceID != "" {
+				require.Equal(t, tc.wantTraceID, sc.TraceID)
+				require.NotEqual(t, "00f067aa0ba902b7", sc.SpanID, "the server span is a child of the caller's")
+			}
+		})
+	}
+}
+
+// Test that a bid produces server, service and repository spans in one trace
+func TestTracingMiddleware_Spans(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1", Title: "Item 1", StartingPrice: 10}))
+	exp := tracing.NewMemoryExporter()
+	router := SetupRouter(Dependencies{Bidding: bidding.NewBiddingService(repo), Tracer: tracing.NewTracer(exp)})
+
+	req := httptest.NewRequest(http.MethodPost, "/bids", strings.NewReader(`{"item_id":"item1","user_id":"user1","amount":100}`))
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	sc, ok := tracing.ParseTraceparent(w.Header().Get(TraceparentHeader))
+	require.True(t, ok)
+	spans := exp.Trace(sc.TraceID)
+	require.NotEmpty(t, spans)
+
+	byName := make(map[string]tracing.SpanData, len(spans))
+	for _, s := range spans {
+		require.Equal(t, "req-1", s.RequestID)
+		byName[s.Name] = s
+	}
+	server, ok := byName["POST /bids"]
+	require.True(t, ok)
+	require.Equal(t, sc.SpanID, server.SpanID)
+	require.Equal(t, "201", server.Attributes["http.status_code"])
+
+	service, ok := byName["BiddingService.PlaceBid"]
+	require.True(t, ok)
+	require.Equal(t, server.SpanID, service.ParentSpanID)
+
+	record, ok := byName["repository.RecordBidForItem"]
+	require.True(t, ok)
+	require.Equal(t, service.SpanID, record.ParentSpanID)
+	require.Equal(t, "item1", record.Attributes["item_id"])
+}
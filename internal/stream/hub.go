@@ -0,0 +1,227 @@
+package stream
+
+import (
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/models"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Event types pushed to stream subscribers
+const (
+	EventBidPlaced     = "bid_placed"
+	EventLeaderChanged = "leader_changed"
+	EventAuctionClosed = "auction_closed"
+)
+
+// ErrSlowConsumer is reported by a subscription that was dropped because its queue was full
+var ErrSlowConsumer = errors.New("stream: subscriber too slow, dropped")
+
+// ErrHubClosed is reported by subscriptions ended because the server is shutting down
+var ErrHubClosed = errors.New("stream: hub closed")
+
+// Event is a single change to an item, numbered by a hub-wide sequence
+type Event struct {
+	Seq      uint64      `json:"seq"`
+	Type     string      `json:"type"`
+	ItemID   string      `json:"item_id"`
+	Bid      *models.Bid `json:"bid,omitempty"`
+	Previous *models.Bid `json:"previous,omitempty"` // prior leader, set on leader_changed
+	Time     time.Time   `json:"time"`
+}
+
+// HubConfig controls replay and per-subscriber buffering
+type HubConfig struct {
+	ReplaySize int // number of recent events kept for resuming subscribers
+	QueueSize  int // events buffered per subscriber before it is dropped
+}
+
+const (
+	defaultReplaySize = 1024
+	defaultQueueSize  = 64
+)
+
+// Hub fans published events out to subscribers without ever blocking the publisher.
+// A subscriber whose queue is full is dropped rather than slowing PlaceBid down.
+type Hub struct {
+	mu        sync.Mutex
+	seq       uint64
+	ring      []Event // circular buffer of the most recent events
+	start     int     // index of the oldest event in ring
+	n         int     // number of events in ring
+	subs      map[*Subscription]struct{}
+	queueSize int
+	closed    bool
+}
+
+// NewHub creates a hub, applying defaults for unset config values
+func NewHub(cfg HubConfig) *Hub {
+	if cfg.ReplaySize <= 0 {
+		cfg.ReplaySize = defaultReplaySize
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	return &Hub{
+		ring:      make([]Event, cfg.ReplaySize),
+		subs:      make(map[*Subscription]struct{}),
+		queueSize: cfg.QueueSize,
+	}
+}
+
+// Publish assigns the next sequence number to an event, buffers it for replay
+// and delivers it to every matching subscriber
+func (h *Hub) Publish(ev Event) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	ev.Seq = h.seq
+
+	if h.n < len(h.ring) {
+		h.ring[(h.start+h.n)%len(h.ring)] = ev
+		h.n++
+	} else {
+		h.ring[h.start] = ev
+		h.start = (h.start + 1) % len(h.ring)
+	}
+
+	for sub := range h.subs {
+		if !sub.matches(ev.ItemID) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			h.drop(sub, ErrSlowConsumer)
+		}
+	}
+	return ev
+}
+
+// HandleEvent converts a domain event into a stream event and publishes it,
+// so the hub can be registered as an events.Bus subscriber
+func (h *Hub) HandleEvent(_ context.Context, ev events.Event) error {
+	switch e := ev.(type) {
+	case events.BidPlaced:
+		h.Publish(Event{Type: EventBidPlaced, ItemID: e.ItemID(), Bid: &e.Bid, Time: e.OccurredAt()})
+	case events.LeaderChanged:
+		h.Publish(Event{Type: EventLeaderChanged, ItemID: e.ItemID(), Bid: &e.Leader, Previous: e.Previous, Time: e.OccurredAt()})
+	case events.AuctionClosed:
+		h.Publish(Event{Type: EventAuctionClosed, ItemID: e.ItemID(), Bid: e.Winner, Time: e.OccurredAt()})
+	}
+	return nil
+}
+
+// Subscribe registers interest in a set of items (all items when itemIDs is empty).
+// When since is non-zero, buffered events after that sequence are queued first so a
+// reconnecting client misses nothing; truncated reports that some of them had already
+// been evicted from the replay buffer, or that since is from before a restart, and the
+// client should refetch state.
+func (h *Hub) Subscribe(itemIDs []string, since uint64) (sub *Subscription, truncated bool) {
+	sub = &Subscription{hub: h}
+	if len(itemIDs) > 0 {
+		sub.items = make(map[string]struct{}, len(itemIDs))
+		for _, id := range itemIDs {
+			sub.items[id] = struct{}{}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []Event
+	if since > 0 {
+		for i := 0; i < h.n; i++ {
+			ev := h.ring[(h.start+i)%len(h.ring)]
+			if ev.Seq > since && sub.matches(ev.ItemID) {
+				replay = append(replay, ev)
+			}
+		}
+		// a client ahead of the hub saw events from before a restart, which numbers events
+		// from zero again, so it cannot know what it missed either
+		truncated = since > h.seq || h.n > 0 && h.ring[h.start].Seq > since+1
+	}
+
+	if h.closed {
+		sub.ch, sub.err = make(chan Event), ErrHubClosed
+		close(sub.ch)
+		return sub, false
+	}
+
+	// replay and registration happen under the same lock, so no event falls between them
+	sub.ch = make(chan Event, h.queueSize+len(replay))
+	for _, ev := range replay {
+		sub.ch <- ev
+	}
+	h.subs[sub] = struct{}{}
+	return sub, truncated
+}
+
+// LastSeq returns the sequence number of the most recently published event
+func (h *Hub) LastSeq() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq
+}
+
+// Close ends every subscription with ErrHubClosed, so streaming handlers return while
+// the server drains, and makes later subscriptions end immediately. Publishing still
+// works and fills the replay buffer. It is safe to call more than once.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub, ErrHubClosed)
+	}
+}
+
+// drop removes a subscriber and closes its channel. The caller must hold h.mu.
+func (h *Hub) drop(sub *Subscription, reason error) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	sub.err = reason
+	close(sub.ch)
+}
+
+// Subscription is one subscriber's view of the hub
+type Subscription struct {
+	hub   *Hub
+	items map[string]struct{} // nil means every item
+	ch    chan Event
+	err   error // guarded by hub.mu
+}
+
+// Events returns the subscriber's queue. It is closed when the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Err reports why the subscription ended: ErrSlowConsumer if it was dropped, ErrHubClosed
+// on shutdown, nil otherwise
+func (s *Subscription) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+// Close unregisters the subscription; it is safe to call more than once
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s, nil)
+}
+
+// matches reports whether the subscription covers an item
+func (s *Subscription) matches(itemID string) bool {
+	if s.items == nil {
+		return true
+	}
+	_, ok := s.items[itemID]
+	return ok
+}
@@ -0,0 +1,194 @@
+package stream
+
+import (
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/models"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// drain reads every event currently queued on a subscription
+func drain(sub *Subscription) []Event {
+	var evs []Event
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return evs
+			}
+			evs = append(evs, ev)
+		default:
+			return evs
+		}
+	}
+}
+
+// seqs returns the sequence numbers of a list of events
+func seqs(evs []Event) []uint64 {
+	out := make([]uint64, 0, len(evs))
+	for _, ev := range evs {
+		out = append(out, ev.Seq)
+	}
+	return out
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name          string
+		items         []string
+		since         uint64
+		wantSeqs      []uint64
+		wantTruncated bool
+	}{
+		{name: "live_only_single_item", items: []string{"item1"}, since: 0, wantSeqs: []uint64{6}},
+		{name: "live_only_all_items", items: nil, since: 0, wantSeqs: []uint64{6, 7}},
+		{name: "resume_within_buffer", items: []string{"item1"}, since: 3, wantSeqs: []uint64{4, 6}},
+		{name: "resume_multiple_items", items: []string{"item1", "item2"}, since: 4, wantSeqs: []uint64{5, 6, 7}},
+		{name: "resume_evicted", items: []string{"item1"}, since: 1, wantSeqs: []uint64{4, 6}, wantTruncated: true},
+		{name: "resume_up_to_date", items: []string{"item2"}, since: 5, wantSeqs: []uint64{7}},
+		{name: "resume_after_restart", items: []string{"item1"}, since: 42, wantSeqs: []uint64{6}, wantTruncated: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			// replay buffer holds 3 events, so seqs 1 and 2 are evicted once 5 are published
+			hub := NewHub(HubConfig{ReplaySize: 3})
+			for i := 0; i < 5; i++ {
+				item := "item1"
+				if i%2 == 0 {
+					item = "item2"
+				}
+				hub.Publish(Event{Type: EventBidPlaced, ItemID: item})
+			}
+
+			sub, truncated := hub.Subscribe(tc.items, tc.since)
+			defer sub.Close()
+			hub.Publish(Event{Type: EventBidPlaced, ItemID: "item1"})
+			hub.Publish(Event{Type: EventBidPlaced, ItemID: "item2"})
+
+			require.Equal(t, tc.wantTruncated, truncated)
+			require.Equal(t, tc.wantSeqs, seqs(drain(sub)))
+		})
+	}
+}
+
+// Test that a client resuming from before a restart is told to resync even when the
+// restarted hub has nothing buffered yet
+func TestHub_SubscribeAfterRestart(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := NewHub(HubConfig{})
+	sub, truncated := hub.Subscribe([]string{"item1"}, 17)
+	defer sub.Close()
+	require.True(t, truncated)
+	require.Empty(t, drain(sub))
+
+	hub.Publish(Event{Type: EventBidPlaced, ItemID: "item1"})
+	require.Equal(t, []uint64{1}, seqs(drain(sub)), "live events still follow")
+}
+
+func TestHub_SlowConsumerDropped(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := NewHub(HubConfig{QueueSize: 2})
+	slow, _ := hub.Subscribe([]string{"item1"}, 0)
+	fast, _ := hub.Subscribe([]string{"item1"}, 0)
+	defer fast.Close()
+
+	// the third publish overflows both queues; neither call may block
+	for i := 0; i < 2; i++ {
+		hub.Publish(Event{Type: EventBidPlaced, ItemID: "item1"})
+	}
+	require.Len(t, drain(fast), 2)
+	hub.Publish(Event{Type: EventBidPlaced, ItemID: "item1"})
+
+	require.Equal(t, []uint64{1, 2}, seqs(drain(slow)))
+	_, open := <-slow.Events()
+	require.False(t, open)
+	require.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+
+	require.Equal(t, []uint64{3}, seqs(drain(fast)))
+	require.NoError(t, fast.Err())
+}
+
+func TestHub_Close(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := NewHub(HubConfig{})
+	sub, _ := hub.Subscribe([]string{"item1"}, 0)
+	sub.Close()
+	sub.Close() // second close is a no-op
+
+	hub.Publish(Event{Type: EventBidPlaced, ItemID: "item1"})
+	_, open := <-sub.Events()
+	require.False(t, open)
+	require.NoError(t, sub.Err())
+	require.Equal(t, uint64(1), hub.LastSeq())
+}
+
+func TestHub_CloseEndsSubscriptions(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := NewHub(HubConfig{})
+	sub, _ := hub.Subscribe(nil, 0)
+	hub.Close()
+	hub.Close() // second close is a no-op
+
+	_, open := <-sub.Events()
+	require.False(t, open)
+	require.ErrorIs(t, sub.Err(), ErrHubClosed)
+
+	late, truncated := hub.Subscribe([]string{"item1"}, 0)
+	require.False(t, truncated)
+	_, open = <-late.Events()
+	require.False(t, open, "subscriptions after close end immediately")
+	require.ErrorIs(t, late.Err(), ErrHubClosed)
+	late.Close()
+
+	hub.Publish(Event{Type: EventBidPlaced, ItemID: "item1"})
+	require.Equal(t, uint64(1), hub.LastSeq())
+}
+
+func TestHub_HandleEvent(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	previous := models.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 100}
+	bid := models.Bid{BidID: "bid2", ItemID: "item1", UserID: "user2", Amount: 150}
+
+	tests := []struct {
+		name     string
+		ev       events.Event
+		wantType string
+		wantBid  *models.Bid
+		wantPrev *models.Bid
+	}{
+		{name: "bid_placed", ev: events.BidPlaced{Bid: bid}, wantType: EventBidPlaced, wantBid: &bid},
+		{name: "leader_changed", ev: events.LeaderChanged{Leader: bid, Previous: &previous}, wantType: EventLeaderChanged, wantBid: &bid, wantPrev: &previous},
+		{name: "auction_closed", ev: events.AuctionClosed{Item: "item1", Winner: &bid}, wantType: EventAuctionClosed, wantBid: &bid},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			hub := NewHub(HubConfig{})
+			sub, _ := hub.Subscribe([]string{"item1"}, 0)
+			defer sub.Close()
+
+			require.NoError(t, hub.HandleEvent(context.Background(), tc.ev))
+			got := drain(sub)
+			require.Len(t, got, 1)
+			require.Equal(t, tc.wantType, got[0].Type)
+			require.Equal(t, tc.wantBid, got[0].Bid)
+			require.Equal(t, tc.wantPrev, got[0].Previous)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Package tracing carries a request ID and a W3C trace context through a request and
+// times spans for the handler, service and repository calls it makes. Finished spans
+// go to the Exporter of the Tracer stored in the context; without one spans are free.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"bidding-tracker/utils"
+)
+
+func init() {
+	// every utils.*Context log line carries the request and trace it belongs to
+	utils.RegisterContextFields(logFields)
+}
+
+// SpanContext identifies a span within a trace, as carried by a traceparent header
+type SpanContext struct {
+	TraceID string // 32 lowercase hex digits
+	SpanID  string // 16 lowercase hex digits
+	Sampled bool
+}
+
+// ParseTraceparent reads a W3C traceparent header (version 00). Future versions are
+// read by their version-00 prefix as the specification requires.
+func ParseTraceparent(header string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) ||
+		!isHex(spanID, 16) || spanID == strings.Repeat("0", 16) || !isHex(flags, 2) {
+		return SpanContext{}, false
+	}
+	b, _ := hex.DecodeString(flags)
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: b[0]&1 == 1}, true
+}
+
+// Traceparent formats the span context as a version-00 traceparent header
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// isHex reports whether s is n lowercase hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewTraceID returns a random trace ID
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+// NewSpanID returns a random span ID
+func NewSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b) // crypto/rand.Read never fails
+	return hex.EncodeToString(b)
+}
+
+// SpanData is a finished span as handed to an Exporter
+type SpanData struct {
+	TraceID      string            `json:"trace_id"`
+	SpanID       string            `json:"span_id"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	Name         string            `json:"name"`
+	RequestID    string            `json:"request_id,omitempty"`
+	Start        time.Time         `json:"start"`
+	Duration     time.Duration     `json:"duration_ns"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// Exporter receives finished spans. ExportSpan is called on the request's goroutine
+// and must not block.
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+// Tracer starts spans that are exported to its Exporter
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer creates a tracer exporting to exp
+func NewTracer(exp Exporter) *Tracer {
+	return &Tracer{exporter: exp}
+}
+
+type (
+	tracerKey    struct{}
+	spanKey      struct{}
+	requestIDKey struct{}
+)
+
+// WithTracer stores the tracer that spans started from ctx are exported through
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// WithRequestID stores the request ID in the context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the request ID stored in the context, or ""
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithSpanContext makes sc the current span, e.g. a remote parent from a traceparent header
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanKey{}, sc)
+}
+
+// SpanContextFrom returns the current span, if any
+func SpanContextFrom(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanKey{}).(SpanContext)
+	return sc, ok
+}
+
+// Span is an operation being timed. A nil *Span is valid and does nothing.
+type Span struct {
+	tracer *Tracer
+	data   SpanData
+}
+
+// StartSpan starts a child of the current span, or a new trace when there is none.
+// The span is only recorded when the context carries a tracer; call End when done.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent, hasParent := SpanContextFrom(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: NewSpanID(), Sampled: true}
+	if hasParent {
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = NewTraceID()
+	}
+	ctx = WithSpanContext(ctx, sc)
+
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	if t == nil || t.exporter == nil || !sc.Sampled {
+		return ctx, nil
+	}
+	return ctx, &Span{tracer: t, data: SpanData{
+		TraceID:      sc.TraceID,
+		SpanID:       sc.SpanID,
+		ParentSpanID: parent.SpanID,
+		Name:         name,
+		RequestID:    RequestIDFrom(ctx),
+		Start:        time.Now(),
+	}}
+}
+
+// SetAttribute records a key/value on the span
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = value
+}
+
+// SetError marks the span failed; a nil error is ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and exports it
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.data.Duration = time.Since(s.data.Start)
+	s.tracer.exporter.ExportSpan(s.data)
+}
+
+// logFields returns the correlation fields for log lines written under ctx
+func logFields(ctx context.Context) map[string]any {
+	fields := make(map[string]any, 3)
+	if id := RequestIDFrom(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if sc, ok := SpanContextFrom(ctx); ok {
+		fields["trace_id"] = sc.TraceID
+		fields["span_id"] = sc.SpanID
+	}
+	return fields
+}
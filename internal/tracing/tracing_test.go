@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test traceparent header parsing and validation
+func TestParseTraceparent(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name   string
+		header string
+		want   SpanContext
+		wantOK bool
+	}{
+		{name: "sampled", header: "00-" + traceID + "-" + spanID + "-01", want: SpanContext{TraceID: traceID, SpanID: spanID, Sampled: true}, wantOK: true},
+		{name: "not_sampled", header: "00-" + traceID + "-" + spanID + "-00", want: SpanContext{TraceID: traceID, SpanID: spanID}, wantOK: true},
+		{name: "future_version_extra_fields", header: "01-" + traceID + "-" + spanID + "-01-extra", want: SpanContext{TraceID: traceID, SpanID: spanID, Sampled: true}, wantOK: true},
+		{name: "empty", header: ""},
+		{name: "version_ff", header: "ff-" + traceID + "-" + spanID + "-01"},
+		{name: "version_00_extra_fields", header: "00-" + traceID + "-" + spanID + "-01-extra"},
+		{name: "zero_trace_id", header: "00-00000000000000000000000000000000-" + spanID + "-01"},
+		{name: "zero_span_id", header: "00-" + traceID + "-0000000000000000-01"},
+		{name: "uppercase", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01"},
+		{name: "short_trace_id", header: "00-4bf92f35-" + spanID + "-01"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			got, ok := ParseTraceparent(tc.header)
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, got)
+			if ok {
+				round, ok := ParseTraceparent(got.Traceparent())
+				require.True(t, ok)
+				require.Equal(t, got, round)
+			}
+		})
+	}
+}
+
+// Test that nested spans share the trace, link to their parent and are exported
+func TestStartSpan(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	exp := NewMemoryExporter()
+	ctx := WithRequestID(WithTracer(context.Background(), NewTracer(exp)), "req-1")
+
+	ctx, root := StartSpan(ctx, "root")
+	rootSC, ok := SpanContextFrom(ctx)
+	require.True(t, ok)
+	childCtx, child := StartSpan(ctx, "child")
+	child.SetAttribute("item_id", "item1")
+	child.SetError(errors.New("boom"))
+	child.End()
+	root.End()
+
+	childSC, ok := SpanContextFrom(childCtx)
+	require.True(t, ok)
+	require.Equal(t, rootSC.TraceID, childSC.TraceID)
+	require.NotEqual(t, rootSC.SpanID, childSC.SpanID)
+
+	spans := exp.Trace(rootSC.TraceID)
+	require.Len(t, spans, 2)
+	require.Equal(t, "child", spans[0].Name)
+	require.Equal(t, rootSC.SpanID, spans[0].ParentSpanID)
+	require.Equal(t, map[string]string{"item_id": "item1"}, spans[0].Attributes)
+	require.Equal(t, "boom", spans[0].Error)
+	require.Equal(t, "req-1", spans[0].RequestID)
+	require.Equal(t, "root", spans[1].Name)
+	require.Empty(t, spans[1].ParentSpanID)
+	require.Empty(t, spans[1].Error)
+
+	require.Equal(t, map[string]any{"request_id": "req-1", "trace_id": childSC.TraceID, "span_id": childSC.SpanID}, logFields(childCtx))
+}
+
+// Test that spans still propagate IDs but record nothing without a tracer or when unsampled
+func TestStartSpan_NotRecorded(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	ctx, span := StartSpan(context.Background(), "untraced")
+	require.Nil(t, span)
+	span.SetAttribute("k", "v")
+	span.SetError(errors.New("ignored"))
+	span.End()
+	sc, ok := SpanContextFrom(ctx)
+	require.True(t, ok)
+	require.Len(t, sc.TraceID, 32)
+
+	exp := NewMemoryExporter()
+	parent := SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID()}
+	ctx = WithSpanContext(WithTracer(context.Background(), NewTracer(exp)), parent)
+	ctx, span = StartSpan(ctx, "unsampled")
+	require.Nil(t, span)
+	sc, _ = SpanContextFrom(ctx)
+	require.Equal(t, parent.TraceID, sc.TraceID)
+	require.Empty(t, exp.Spans())
+
+	require.Empty(t, logFields(context.Background()))
+}
@@ -0,0 +1,407 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/retry"
+	"bidding-tracker/utils"
+)
+
+var (
+	// ErrSubscriptionNotFound is returned for an unknown subscription ID
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	// ErrInvalidSubscription is returned when a subscription request fails validation
+	ErrInvalidSubscription = errors.New("invalid webhook subscription")
+)
+
+// EventTypes lists the event types a subscription can filter on
+var EventTypes = []string{events.NameBidPlaced, events.NameLeaderChanged, events.NameAuctionClosed}
+
+// Subscription is a partner endpoint that receives events
+type Subscription struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"` // empty means every type
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	ID         string    `json:"id"` // delivery ID, stable across retries for deduplication
+	Type       string    `json:"type"`
+	ItemID     string    `json:"item_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Attempt records one HTTP delivery attempt
+type Attempt struct {
+	DeliveryID     string    `json:"delivery_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventType      string    `json:"event_type"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Duration       string    `json:"duration"`
+	At             time.Time `json:"at"`
+}
+
+// DeadLetter is a delivery that failed permanently or exhausted its retries
+type DeadLetter struct {
+	DeliveryID     string    `json:"delivery_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	Payload        Payload   `json:"payload"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"last_error"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// Config configures webhook delivery
+type Config struct {
+	Client      *http.Client // nil uses a client with a 10s timeout
+	Retry       retry.Policy // zero value uses retry.Default
+	MaxAttempts int          // attempts kept per subscription for debugging; 0 uses 100
+}
+
+const defaultAttemptLog = 100
+
+// Service manages webhook subscriptions and delivers domain events to them.
+// It is registered as an asynchronous events.Bus subscriber.
+type Service struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	subs     map[string]Subscription
+	attempts map[string][]Attempt    // by subscription, most recent last
+	dead     map[string][]DeadLetter // by subscription
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewService creates a webhook service with no subscriptions
+func NewService(cfg Config) *Service {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry = retry.Default
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultAttemptLog
+	}
+	return &Service{
+		cfg:      cfg,
+		subs:     make(map[string]Subscription),
+		attempts: make(map[string][]Attempt),
+		dead:     make(map[string][]DeadLetter),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Subscribe validates and registers an endpoint. A secret is generated when none is given;
+// the returned subscription is the only place it is disclosed.
+func (s *Service) Subscribe(rawURL string, eventTypes []string, secret string) (Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidSubscription)
+	}
+	for _, t := range eventTypes {
+		if !knownEventType(t) {
+			return Subscription{}, fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, t)
+		}
+	}
+	if secret == "" {
+		secret = randomHex(32)
+	}
+
+	sub := Subscription{
+		ID:         utils.GenerateID(),
+		URL:        u.String(),
+		EventTypes: append([]string{}, eventTypes...),
+		Secret:     secret,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	s.subs[sub.ID] = sub
+	s.mu.Unlock()
+
+	utils.Info("Webhook: subscription created", map[string]any{"subscription_id": sub.ID, "url": sub.URL, "event_types": sub.EventTypes})
+	return sub, nil
+}
+
+// List returns every subscription, oldest first, without secrets
+func (s *Service) List() []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		sub.Secret = ""
+		out = append(out, sub)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Get returns one subscription without its secret
+func (s *Service) Get(id string) (Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return Subscription{}, fmt.Errorf("get webhook %s: %w", id, ErrSubscriptionNotFound)
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// Delete removes a subscription and its delivery history. In-flight retries stop at their next attempt.
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[id]; !ok {
+		return fmt.Errorf("delete webhook %s: %w", id, ErrSubscriptionNotFound)
+	}
+	delete(s.subs, id)
+	delete(s.attempts, id)
+	delete(s.dead, id)
+	return nil
+}
+
+// Attempts returns the recent delivery attempts for a subscription, oldest first
+func (s *Service) Attempts(id string) ([]Attempt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.subs[id]; !ok {
+		return nil, fmt.Errorf("get webhook %s deliveries: %w", id, ErrSubscriptionNotFound)
+	}
+	return append([]Attempt{}, s.attempts[id]...), nil
+}
+
+// DeadLetters returns the deliveries to a subscription that were given up on
+func (s *Service) DeadLetters(id string) ([]DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.subs[id]; !ok {
+		return nil, fmt.Errorf("get webhook %s dead letters: %w", id, ErrSubscriptionNotFound)
+	}
+	return append([]DeadLetter{}, s.dead[id]...), nil
+}
+
+// HandleEvent starts a delivery to every subscription that accepts the event's type
+func (s *Service) HandleEvent(_ context.Context, ev events.Event) error {
+	s.mu.RLock()
+	var targets []Subscription
+	for _, sub := range s.subs {
+		if sub.accepts(ev.EventName()) {
+			targets = append(targets, sub)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, sub := range targets {
+		payload := Payload{
+			ID:         utils.GenerateID(),
+			Type:       ev.EventName(),
+			ItemID:     ev.ItemID(),
+			OccurredAt: ev.OccurredAt().UTC(),
+			Data:       payloadData(ev),
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("webhook: encode %s: %w", ev.EventName(), err)
+		}
+
+		s.wg.Add(1)
+		go s.deliver(sub, payload, body)
+	}
+	return nil
+}
+
+// Close abandons pending retries and waits for in-flight requests
+func (s *Service) Close() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// deliver posts a payload until it is accepted, fails permanently or runs out of attempts
+func (s *Service) deliver(sub Subscription, payload Payload, body []byte) {
+	defer s.wg.Done()
+
+	for attempt := 1; ; attempt++ {
+		if !s.active(sub.ID) {
+			return // unsubscribed while retrying
+		}
+
+		start := time.Now()
+		status, err := s.post(sub, payload, body)
+		s.recordAttempt(sub.ID, payload, attempt, status, time.Since(start), err)
+		if err == nil {
+			return
+		}
+
+		permanent := status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests
+		if permanent || attempt >= s.cfg.Retry.MaxAttempts {
+			s.deadLetter(sub.ID, payload, attempt, err)
+			return
+		}
+
+		t := time.NewTimer(s.cfg.Retry.Backoff(attempt + 1))
+		select {
+		case <-t.C:
+		case <-s.stop:
+			t.Stop()
+			return
+		}
+	}
+}
+
+// post sends one signed request. Any non-2xx response is an error.
+func (s *Service) post(sub Subscription, payload Payload, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bidding-tracker-webhooks/1")
+	req.Header.Set(HeaderDeliveryID, payload.ID)
+	req.Header.Set(HeaderEvent, payload.Type)
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(ts))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, body))
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // let the connection be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook: endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// active reports whether a subscription still exists
+func (s *Service) active(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.subs[id]
+	return ok
+}
+
+// recordAttempt appends to a subscription's bounded attempt log
+func (s *Service) recordAttempt(subID string, payload Payload, attempt, status int, took time.Duration, err error) {
+	a := Attempt{
+		DeliveryID:     payload.ID,
+		SubscriptionID: subID,
+		EventType:      payload.Type,
+		Attempt:        attempt,
+		StatusCode:     status,
+		Duration:       took.String(),
+		At:             time.Now().UTC(),
+	}
+	fields := map[string]any{"subscription_id": subID, "delivery_id": payload.ID, "attempt": attempt, "status": status}
+	if err != nil {
+		a.Error = err.Error()
+		fields["error"] = err.Error()
+		utils.Warn("Webhook: delivery attempt failed", fields)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[subID]; !ok {
+		return
+	}
+	log := append(s.attempts[subID], a)
+	if len(log) > s.cfg.MaxAttempts {
+		log = log[len(log)-s.cfg.MaxAttempts:]
+	}
+	s.attempts[subID] = log
+}
+
+// deadLetter records a delivery that will not be retried
+func (s *Service) deadLetter(subID string, payload Payload, attempts int, err error) {
+	utils.Error("Webhook: delivery dead-lettered", map[string]any{"subscription_id": subID, "delivery_id": payload.ID, "attempts": attempts, "error": err.Error()})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[subID]; !ok {
+		return
+	}
+	s.dead[subID] = append(s.dead[subID], DeadLetter{
+		DeliveryID:     payload.ID,
+		SubscriptionID: subID,
+		Payload:        payload,
+		Attempts:       attempts,
+		LastError:      err.Error(),
+		FailedAt:       time.Now().UTC(),
+	})
+}
+
+// accepts reports whether the subscription wants an event type
+func (sub Subscription) accepts(eventType string) bool {
+	if len(sub.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range sub.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// payloadData is the type-specific part of a delivery
+func payloadData(ev events.Event) any {
+	switch e := ev.(type) {
+	case events.BidPlaced:
+		return map[string]any{"bid": e.Bid}
+	case events.LeaderChanged:
+		return map[string]any{"leader": e.Leader, "previous": e.Previous}
+	case events.AuctionClosed:
+		return map[string]any{"winner": e.Winner}
+	default:
+		return nil
+	}
+}
+
+// knownEventType reports whether t is a valid event type filter
+func knownEventType(t string) bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("webhook: crypto/rand failed: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
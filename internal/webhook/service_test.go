@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/models"
+	"bidding-tracker/internal/retry"
+
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetry = retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// receiver is an httptest endpoint that answers with a scripted sequence of status codes
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int // returned in order; the last one repeats
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		n := len(r.requests)
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		status := r.statuses[min(n, len(r.statuses)-1)]
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() ([]*http.Request, [][]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*http.Request(nil), r.requests...), append([][]byte(nil), r.bodies...)
+}
+
+func TestService_Subscribe(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		wantError  error
+	}{
+		{name: "valid_all_types", url: "https://partner.example.com/hooks"},
+		{name: "valid_filtered", url: "http://localhost:9000/hooks", eventTypes: []string{events.NameLeaderChanged}},
+		{name: "relative_url", url: "/hooks", wantError: ErrInvalidSubscription},
+		{name: "unsupported_scheme", url: "ftp://partner.example.com", wantError: ErrInvalidSubscription},
+		{name: "unknown_event_type", url: "https://partner.example.com", eventTypes: []string{"bid_deleted"}, wantError: ErrInvalidSubscription},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			svc := NewService(Config{Retry: fastRetry})
+			defer svc.Close()
+
+			sub, err := svc.Subscribe(tc.url, tc.eventTypes, "")
+			if tc.wantError != nil {
+				require.ErrorIs(t, err, tc.wantError)
+				require.Empty(t, svc.List())
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, sub.Secret)
+
+			got, err := svc.Get(sub.ID)
+			require.NoError(t, err)
+			require.Empty(t, got.Secret, "secret is only disclosed on create")
+			require.Equal(t, tc.url, got.URL)
+
+			require.NoError(t, svc.Delete(sub.ID))
+			_, err = svc.Get(sub.ID)
+			require.ErrorIs(t, err, ErrSubscriptionNotFound)
+		})
+	}
+}
+
+func TestService_Deliver(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	bid := models.Bid{ItemID: "item1", UserID: "user1", Amount: 100}
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		eventTypes   []string
+		wantRequests int
+		wantDead     bool
+	}{
+		{name: "delivered_first_time", statuses: []int{http.StatusOK}, wantRequests: 1},
+		{name: "retried_after_server_error", statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent}, wantRequests: 3},
+		{name: "dead_letter_after_max_attempts", statuses: []int{http.StatusServiceUnavailable}, wantRequests: 3, wantDead: true},
+		{name: "client_error_not_retried", statuses: []int{http.StatusGone}, wantRequests: 1, wantDead: true},
+		{name: "filtered_out", statuses: []int{http.StatusOK}, eventTypes: []string{events.NameAuctionClosed}, wantRequests: 0},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			recv := newReceiver(t, tc.statuses...)
+			svc := NewService(Config{Retry: fastRetry})
+			sub, err := svc.Subscribe(recv.URL, tc.eventTypes, "s3cret")
+			require.NoError(t, err)
+
+			require.NoError(t, svc.HandleEvent(context.Background(), events.BidPlaced{Bid: bid}))
+			svc.wg.Wait() // deliveries finish on their own once they succeed or give up
+
+			reqs, bodies := recv.received()
+			require.Len(t, reqs, tc.wantRequests)
+
+			attempts, err := svc.Attempts(sub.ID)
+			require.NoError(t, err)
+			require.Len(t, attempts, tc.wantRequests)
+
+			dead, err := svc.DeadLetters(sub.ID)
+			require.NoError(t, err)
+			if tc.wantDead {
+				require.Len(t, dead, 1)
+				require.Equal(t, tc.wantRequests, dead[0].Attempts)
+			} else {
+				require.Empty(t, dead)
+			}
+
+			for i, req := range reqs {
+				require.Equal(t, events.NameBidPlaced, req.Header.Get(HeaderEvent))
+				require.NoError(t, Verify("s3cret", req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderSignature), bodies[i], time.Minute, time.Now()))
+
+				var payload Payload
+				require.NoError(t, json.Unmarshal(bodies[i], &payload))
+				require.Equal(t, req.Header.Get(HeaderDeliveryID), payload.ID)
+				require.Equal(t, reqs[0].Header.Get(HeaderDeliveryID), payload.ID, "retries reuse the delivery ID")
+				require.Equal(t, "item1", payload.ItemID)
+			}
+			svc.Close()
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":"d1"}`)
+	valid := Sign("secret", now.Unix(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		wantError bool
+	}{
+		{name: "valid", secret: "secret", timestamp: "1700000000", signature: valid, body: body},
+		{name: "wrong_secret", secret: "other", timestamp: "1700000000", signature: valid, body: body, wantError: true},
+		{name: "tampered_body", secret: "secret", timestamp: "1700000000", signature: valid, body: []byte(`{"id":"d2"}`), wantError: true},
+		{name: "timestamp_changed", secret: "secret", timestamp: "1700000001", signature: valid, body: body, wantError: true},
+		{name: "too_old", secret: "secret", timestamp: "1699999000", signature: Sign("secret", 1699999000, body), body: body, wantError: true},
+		{name: "malformed_timestamp", secret: "secret", timestamp: "yesterday", signature: valid, body: body, wantError: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			err := Verify(tc.secret, tc.timestamp, tc.signature, tc.body, 5*time.Minute, now)
+			if tc.wantError {
+				require.ErrorIs(t, err, ErrInvalidSignature)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
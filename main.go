@@ -1,49 +1,442 @@
 package main
 
 import (
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/auth"
 	bidding "bidding-tracker/internal/biddingService"
+	"bidding-tracker/internal/config"
+	"bidding-tracker/internal/events"
+	"bidding-tracker/internal/health"
+	"bidding-tracker/internal/lifecycle"
+	"bidding-tracker/internal/metrics"
 	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/notification"
+	"bidding-tracker/internal/outbox"
+	"bidding-tracker/internal/ratelimit"
+	"bidding-tracker/internal/receipt"
 	"bidding-tracker/internal/repository"
 	"bidding-tracker/internal/server"
+	"bidding-tracker/internal/stream"
+	"bidding-tracker/internal/tracing"
+	"bidding-tracker/internal/webhook"
+	"bidding-tracker/utils"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 database/sql driver
 )
 
 func main() {
 
-	repo := repository.NewMemoryRepo()
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(2)
+	}
+
+	logOutput, err := configureLogging(cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure logging: %v\n", err)
+		os.Exit(1)
+	}
+	if logOutput != nil {
+		defer logOutput.Close()
+	}
+
+	// components are stopped by hooks, newest first, once requests have drained
+	lc := lifecycle.New(cfg.Server.ShutdownTimeout)
 
-	prepopulateItems(repo)
+	repo, err := repository.OpenStore(context.Background(), getStoreConfig(cfg.Storage))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open storage: %v\n", err)
+		os.Exit(1)
+	}
+	lc.OnStop("storage", repo.Close)
 
-	biddingSvc := bidding.NewBiddingService(repo)
+	// readiness: storage reachable (a file store has finished WAL recovery once opened)
+	// and startup complete
+	checker := health.NewChecker(0)
+	checker.Register("storage", repo.Ping)
+	startup := health.NewFlag("starting up")
+	checker.Register("startup", startup.Check)
 
-	router := server.SetupRouter(biddingSvc)
+	if cfg.Auction.SeedItems {
+		if err := prepopulateItems(repo); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to seed items: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	port := getPort()
-	fmt.Printf("Starting auction server on %s...\n", port)
-	if err := router.Run(port); err != nil {
+	bus := events.NewBus(events.BusConfig{QueueSize: cfg.Events.QueueSize})
+
+	// the hub never blocks, so it is fed synchronously and keeps per-item event order
+	hub := stream.NewHub(stream.HubConfig{})
+	bus.Subscribe("stream", hub, events.Sync)
+
+	preferences := notification.NewMemoryPreferences()
+	notifiers, err := getNotifiers(cfg.Notifications)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up notifications: %v\n", err)
+		os.Exit(1)
+	}
+	notifications := notification.NewService(notification.Config{
+		Notifiers:   notifiers,
+		Preferences: preferences,
+		Workers:     cfg.Notifications.Workers,
+		MaxPending:  cfg.Notifications.MaxPending,
+	}, repo)
+	lc.OnStop("notifications", stopFunc(notifications.Close))
+	webhooks := webhook.NewService(webhook.Config{})
+	lc.OnStop("webhooks", stopFunc(webhooks.Close))
+	lc.OnStop("events", stopFunc(bus.Close)) // drains async subscribers before they stop
+	bus.Subscribe("notifications", notifications, events.Async)
+	bus.Subscribe("webhooks", webhooks, events.Async)
+
+	sink, err := getOutboxSink(cfg.Outbox)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open outbox sink: %v\n", err)
+		os.Exit(1)
+	}
+	if closer, ok := sink.(io.Closer); ok {
+		lc.OnStop("outbox sink", closer.Close)
+	}
+	if sink != nil {
+		publisher := outbox.NewPublisher(outbox.Config{Feed: repo, Sink: sink})
+		lc.OnStop("outbox", stopFunc(publisher.Close)) // forwards the last changes before storage closes
+		bus.Subscribe("outbox", publisher, events.Sync)
+	}
+
+	biddingSvc := bidding.NewBiddingService(repo, bidding.WithPublisher(bus), bidding.WithMinIncrement(cfg.Auction.MinIncrement))
+	registerGauges(repo, bus)
+
+	verifier, err := getJWTVerifier(cfg.Auth)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure authentication: %v\n", err)
+		os.Exit(1)
+	}
+	apiKeys, err := getAPIKeyStore(cfg.Auth, verifier != nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure API keys: %v\n", err)
+		os.Exit(1)
+	}
+	if apiKeys != nil {
+		apiKeys.FlushEvery(cfg.Auth.APIKeysFlushInterval)
+		lc.OnStop("api keys", apiKeys.Close) // persist the last usage records
+	}
+	if verifier == nil && apiKeys == nil {
+		utils.Warn("Authentication disabled: admin routes, closing auctions and notification preferences are not served; set JWT_HMAC_SECRET, JWT_ED25519_PUBLIC_KEY_FILE or ADMIN_API_KEY to require credentials", nil)
+	}
+
+	auditLog, err := audit.OpenLog(cfg.Audit.LogFile, audit.WithRetention(cfg.Audit.MaxEntries))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open audit log: %v\n", err)
+		os.Exit(1)
+	}
+	lc.OnStop("audit log", auditLog.Close)
+
+	receipts, err := getReceiptSigner(cfg.Receipts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load receipt signing key: %v\n", err)
+		os.Exit(1)
+	}
+
+	tracer, err := getTracer(cfg.Tracing)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure tracing: %v\n", err)
+		os.Exit(1)
+	}
+
+	router := server.SetupRouter(server.Dependencies{Bidding: biddingSvc, Health: checker, Auth: verifier, APIKeys: apiKeys, Audit: auditLog, Receipts: receipts, Tracer: tracer, Config: cfg, Stream: hub, Preferences: preferences, Webhooks: webhooks, Changes: repo, RateLimits: getRateLimits(cfg.RateLimit), TrustedProxies: cfg.Server.TrustedProxies})
+
+	// on shutdown, fail readiness so load balancers stop routing here, and end streams and
+	// long polls, which would otherwise hold the drain open until its deadline
+	lc.OnDrain(func() { startup.SetNotReady("shutting down") })
+	lc.OnDrain(hub.Close)
+	lc.OnDrain(biddingSvc.ReleaseWaiters)
+
+	srv := &http.Server{
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	port := fmt.Sprintf(":%d", cfg.Server.Port)
+	ln, err := net.Listen("tcp", port)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start server: %v\n", err)
 		os.Exit(1)
 	}
+
+	// the first SIGINT or SIGTERM starts a graceful shutdown; a second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	startup.SetReady()
+	fmt.Printf("Starting auction server on %s...\n", port)
+	if err := lc.Serve(ctx, srv, ln); err != nil {
+		utils.Error("Shutdown incomplete", map[string]any{"error": err.Error()})
+		if logOutput != nil {
+			logOutput.Close()
+		}
+		os.Exit(1)
+	}
+	utils.Info("Shutdown complete", nil)
+}
+
+// stopFunc adapts a Close method that cannot fail to a shutdown hook
+func stopFunc(close func()) func() error {
+	return func() error {
+		close()
+		return nil
+	}
 }
 
-// prepopulateItems adds sample items to the in-memory repo
-func prepopulateItems(repo *repository.MemoryRepo) {
+// prepopulateItems adds sample items to the repository
+func prepopulateItems(repo repository.Store) error {
 	items := []model.Item{
-		{ItemID: "item1", Title: "title1", Description: "description1", StartingPrice: 100},
-		{ItemID: "item2", Title: "title2", Description: "Description2", StartingPrice: 200},
-		{ItemID: "item3", Title: "title3", Description: "Description3", StartingPrice: 150},
+		{ItemID: "item1", Title: "title1", Description: "description1", StartingPrice: 100, Owner: "seller1"},
+		{ItemID: "item2", Title: "title2", Description: "Description2", StartingPrice: 200, Owner: "seller1"},
+		{ItemID: "item3", Title: "title3", Description: "Description3", StartingPrice: 150, Owner: "seller2"},
 	}
 
 	for _, item := range items {
-		repo.AddItem(item)
+		if err := repo.AddItem(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureLogging sets up the default logger. LOG_OUTPUT is stdout, stderr or a file to
+// append to; it returns the log file to close, if any.
+func configureLogging(cfg config.Logging) (*os.File, error) {
+	logCfg := utils.LogConfig{
+		Level:           cfg.Level,
+		Format:          cfg.Format,
+		SuccessSampling: cfg.SuccessSampling,
+		Redact:          cfg.RedactFields,
+	}
+
+	var file *os.File
+	switch cfg.Output {
+	case "stdout":
+		logCfg.Output = os.Stdout
+	case "stderr":
+		logCfg.Output = os.Stderr
+	default:
+		f, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		logCfg.Output, file = f, f
+	}
+
+	if err := utils.Configure(logCfg); err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// getStoreConfig returns the storage backend settings
+func getStoreConfig(cfg config.Storage) repository.StoreConfig {
+	return repository.StoreConfig{
+		Backend: cfg.Backend,
+		File: repository.FileRepoConfig{
+			Dir:              cfg.Dir,
+			Fsync:            repository.FsyncPolicy(cfg.Fsync),
+			FsyncInterval:    cfg.FsyncInterval,
+			SnapshotEvery:    cfg.SnapshotEvery,
+			SnapshotInterval: cfg.SnapshotInterval,
+		},
+		SQLDriver:       cfg.SQLDriver,
+		SQLDSN:          cfg.SQLDSN,
+		OutboxRetention: cfg.OutboxRetention,
+	}
+}
+
+// getNotifiers builds the notification channels: a JSON-lines log written to
+// NOTIFY_LOG_FILE (stdout when unset) and, when SMTP_ADDR is set, email
+func getNotifiers(cfg config.Notifications) (map[string]notification.Notifier, error) {
+	notifiers := make(map[string]notification.Notifier)
+
+	if path := cfg.LogFile; path != "" {
+		// the file stays open for the life of the process
+		logNotifier, _, err := notification.OpenFileNotifier(path)
+		if err != nil {
+			return nil, err
+		}
+		notifiers["log"] = logNotifier
+	} else {
+		notifiers["log"] = notification.NewLogNotifier(os.Stdout)
+	}
+
+	if cfg.SMTPAddr != "" {
+		notifiers["email"] = notification.NewSMTPNotifier(notification.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+		})
+	}
+	return notifiers, nil
+}
+
+// getOutboxSink returns where the outbox publisher forwards changes, selected by OUTBOX_SINK:
+// "stdout", "file" (JSON lines appended to OUTBOX_FILE) or unset to disable forwarding
+func getOutboxSink(cfg config.Outbox) (outbox.Sink, error) {
+	switch cfg.Sink {
+	case "":
+		return nil, nil
+	case "stdout":
+		return outbox.NewWriterSink(os.Stdout), nil
+	case "file":
+		// the file stays open for the life of the process
+		return outbox.OpenFileSink(cfg.File)
+	default:
+		return nil, fmt.Errorf("unknown OUTBOX_SINK %q", cfg.Sink)
 	}
 }
 
-// getPort returns the server port from env or defaults to ":8080"
-func getPort() string {
-	if p := os.Getenv("PORT"); p != "" {
-		return fmt.Sprintf(":%s", p)
+// getJWTVerifier builds the bearer token verifier. It returns nil when neither
+// JWT_HMAC_SECRET nor JWT_ED25519_PUBLIC_KEY_FILE is set, leaving authentication disabled.
+func getJWTVerifier(authCfg config.Auth) (*auth.JWTVerifier, error) {
+	cfg := auth.JWTConfig{
+		HMACSecret: []byte(authCfg.JWTHMACSecret),
+		Issuer:     authCfg.JWTIssuer,
+		Audience:   authCfg.JWTAudience,
+		Leeway:     authCfg.JWTLeeway,
+		MaxTTL:     authCfg.JWTMaxTTL,
 	}
-	return ":8080"
+	if path := authCfg.JWTEd25519PublicKeyFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Ed25519PublicKey, err = auth.ParseEd25519PublicKey(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.HMACSecret) == 0 && len(cfg.Ed25519PublicKey) == 0 {
+		return nil, nil
+	}
+	return auth.NewJWTVerifier(cfg)
+}
+
+// getAPIKeyStore opens the API key store at API_KEYS_FILE when authentication is enabled,
+// either by a JWT key or by ADMIN_API_KEY. ADMIN_API_KEY is registered as an admin-scoped
+// key so the first partner keys can be issued without a JWT issuer.
+func getAPIKeyStore(cfg config.Auth, jwtEnabled bool) (*auth.APIKeyStore, error) {
+	bootstrap := cfg.AdminAPIKey
+	if !jwtEnabled && bootstrap == "" {
+		return nil, nil
+	}
+
+	keys, err := auth.NewAPIKeyStore(cfg.APIKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	if bootstrap != "" {
+		_, err := keys.Register(bootstrap, auth.KeyRequest{Name: "bootstrap", OwnerID: "admin", Scopes: []auth.Permission{auth.PermAdmin}})
+		if err != nil && !errors.Is(err, auth.ErrAPIKeyExists) {
+			return nil, fmt.Errorf("ADMIN_API_KEY: %w", err)
+		}
+	}
+	return keys, nil
+}
+
+// getReceiptSigner loads the bid receipt signing key from RECEIPT_SIGNING_KEY_FILE. Without
+// it a key is generated at startup, so receipts only verify until the server restarts.
+func getReceiptSigner(cfg config.Receipts) (*receipt.Signer, error) {
+	path := cfg.SigningKeyFile
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := receipt.NewSigner(key)
+		if err != nil {
+			return nil, err
+		}
+		utils.Warn("Receipt signing key is ephemeral: set RECEIPT_SIGNING_KEY_FILE so receipts stay verifiable across restarts", map[string]any{"key_id": signer.KeyID()})
+		return signer, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := receipt.ParsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return receipt.NewSigner(key)
+}
+
+// getTracer selects the span exporter from TRACE_EXPORTER: unset exports nothing, while
+// "stdout" writes each finished span as a JSON line. Request IDs and trace context are
+// propagated either way.
+func getTracer(cfg config.Tracing) (*tracing.Tracer, error) {
+	switch cfg.Exporter {
+	case "":
+		return nil, nil
+	case "stdout":
+		return tracing.NewTracer(tracing.NewWriterExporter(os.Stdout)), nil
+	default:
+		return nil, fmt.Errorf("unknown TRACE_EXPORTER %q", cfg.Exporter)
+	}
+}
+
+// getRateLimits returns the per-client limits of bid writes and reads, or no limits unless
+// RATE_LIMIT_ENABLED is set
+func getRateLimits(cfg config.RateLimit) server.RateLimits {
+	if !cfg.Enabled {
+		return server.RateLimits{}
+	}
+	return server.RateLimits{
+		Bids:  ratelimit.Limit{PerSecond: cfg.BidsPerSecond, Burst: cfg.BidsBurst},
+		Reads: ratelimit.Limit{PerSecond: cfg.ReadsPerSecond, Burst: cfg.ReadsBurst},
+	}
+}
+
+// registerGauges exposes state read at scrape time: open auctions and async subscriber backlogs
+func registerGauges(repo repository.Store, bus *events.Bus) {
+	metrics.Default.NewGaugeFunc("auctions_active", "Items open for bidding.", func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		n, err := repo.CountOpenItems(ctx)
+		if err != nil {
+			utils.Warn("Metrics: failed to count open items", map[string]any{"error": err.Error()})
+			return math.NaN()
+		}
+		return float64(n)
+	})
+	metrics.Default.NewGaugeVecFunc("events_subscriber_queue_depth", "Events waiting in each async subscriber's queue.", "subscriber", func() map[string]float64 {
+		depth := make(map[string]float64)
+		for name, n := range bus.Lag() {
+			depth[name] = float64(n)
+		}
+		return depth
+	})
 }
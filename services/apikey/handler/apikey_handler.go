@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/services/bidding/helpers"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler exposes API key issuance, usage and revocation to admins
+type APIKeyHandler struct {
+	keys *auth.APIKeyStore
+}
+
+func NewAPIKeyHandler(keys *auth.APIKeyStore) *APIKeyHandler {
+	return &APIKeyHandler{keys: keys}
+}
+
+// createKeyRequest is the body of POST /admin/api-keys
+type createKeyRequest struct {
+	Name    string            `json:"name" binding:"required"`
+	OwnerID string            `json:"owner_id" binding:"required"`
+	Scopes  []auth.Permission `json:"scopes" binding:"required"`
+	TTL     string            `json:"ttl"` // Go duration, e.g. "720h"; empty means no expiry
+}
+
+// createKeyResponse carries the new key and its secret
+type createKeyResponse struct {
+	Key    auth.APIKey `json:"key"`
+	Secret string      `json:"secret"`
+}
+
+// CreateAPIKeyHandler handles POST /admin/api-keys. The response is the only place the secret is returned.
+func (h *APIKeyHandler) CreateAPIKeyHandler(c *gin.Context) {
+	var req createKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helpers.HandleBindError(c, "CreateAPIKeyHandler", err)
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil {
+			utils.JSONError(c, http.StatusBadRequest, fmt.Errorf("%w: ttl: %v", auth.ErrInvalidKeyRequest, err), "invalid api key request")
+			return
+		}
+	}
+
+	if err := audit.Ready(c.Request.Context()); err != nil {
+		utils.JSONError(c, http.StatusServiceUnavailable, err, "audit log unavailable")
+		return
+	}
+	key, secret, err := h.keys.Issue(auth.KeyRequest{Name: req.Name, OwnerID: req.OwnerID, Scopes: req.Scopes, TTL: ttl})
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidKeyRequest) {
+			utils.JSONError(c, http.StatusBadRequest, err, "invalid api key request")
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err, "failed to create api key")
+		return
+	}
+
+	audit.Record(c.Request.Context(), audit.Change{Action: audit.ActionAPIKeyIssued, UserID: key.OwnerID, Resource: key.ID, After: key})
+	utils.JSONResponse(c, http.StatusCreated, createKeyResponse{Key: key, Secret: secret}, "api key created successfully")
+	helpers.LogSuccess(c, "CreateAPIKeyHandler", "api key created successfully", map[string]any{"key_id": key.ID, "owner_id": key.OwnerID})
+}
+
+// ListAPIKeysHandler handles GET /admin/api-keys
+func (h *APIKeyHandler) ListAPIKeysHandler(c *gin.Context) {
+	utils.JSONResponse(c, http.StatusOK, h.keys.List(), "api keys retrieved successfully")
+}
+
+// GetAPIKeyHandler handles GET /admin/api-keys/:key_id, including the key's usage record
+func (h *APIKeyHandler) GetAPIKeyHandler(c *gin.Context) {
+	key, err := h.keys.Get(c.Param("key_id"))
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, key, "api key retrieved successfully")
+}
+
+// RevokeAPIKeyHandler handles DELETE /admin/api-keys/:key_id. The key stays listed with revoked_at set.
+func (h *APIKeyHandler) RevokeAPIKeyHandler(c *gin.Context) {
+	before, err := h.keys.Get(c.Param("key_id"))
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	if err := audit.Ready(c.Request.Context()); err != nil {
+		utils.JSONError(c, http.StatusServiceUnavailable, err, "audit log unavailable")
+		return
+	}
+	key, err := h.keys.Revoke(before.ID)
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	audit.Record(c.Request.Context(), audit.Change{Action: audit.ActionAPIKeyRevoked, UserID: key.OwnerID, Resource: key.ID, Before: before, After: key})
+	utils.JSONResponse(c, http.StatusOK, key, "api key revoked successfully")
+	helpers.LogSuccess(c, "RevokeAPIKeyHandler", "api key revoked successfully", map[string]any{"key_id": key.ID})
+}
+
+// writeAPIKeyError maps key store errors to HTTP responses
+func writeAPIKeyError(c *gin.Context, err error) {
+	if errors.Is(err, auth.ErrAPIKeyNotFound) {
+		utils.JSONError(c, http.StatusNotFound, err, "api key not found")
+		return
+	}
+	utils.JSONError(c, http.StatusInternalServerError, err, "internal server error")
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bidding-tracker/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(keys *auth.APIKeyStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAPIKeyHandler(keys)
+	router.POST("/admin/api-keys", h.CreateAPIKeyHandler)
+	router.GET("/admin/api-keys", h.ListAPIKeysHandler)
+	router.GET("/admin/api-keys/:key_id", h.GetAPIKeyHandler)
+	router.DELETE("/admin/api-keys/:key_id", h.RevokeAPIKeyHandler)
+	return router
+}
+
+// Test API key issuance validation
+func TestCreateAPIKeyHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{
+			name:           "created",
+			body:           `{"name":"partner","owner_id":"partner1","scopes":["bids:write","items:read"],"ttl":"720h"}`,
+			expectedStatus: http.StatusCreated,
+			expectedMsg:    "api key created successfully",
+		},
+		{
+			name:           "unknown_scope",
+			body:           `{"name":"partner","owner_id":"partner1","scopes":["items:delete"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid api key request",
+		},
+		{
+			name:           "invalid_ttl",
+			body:           `{"name":"partner","owner_id":"partner1","scopes":["items:read"],"ttl":"soon"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid api key request",
+		},
+		{
+			name:           "missing_owner",
+			body:           `{"name":"partner","scopes":["items:read"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid request payload",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			keys, err := auth.NewAPIKeyStore("")
+			require.NoError(t, err)
+			router := newTestRouter(keys)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedMsg, resp["message"])
+		})
+	}
+}
+
+// Test that usage is reported and revocation is visible without exposing secrets or hashes
+func TestAPIKeyHandler_UsageAndRevoke(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	keys, err := auth.NewAPIKeyStore("")
+	require.NoError(t, err)
+	key, secret, err := keys.Issue(auth.KeyRequest{Name: "partner", OwnerID: "partner1", Scopes: []auth.Permission{auth.PermItemsRead}})
+	require.NoError(t, err)
+	_, err = keys.Authenticate(secret)
+	require.NoError(t, err)
+	router := newTestRouter(keys)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/api-keys/"+key.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), secret)
+	require.NotContains(t, w.Body.String(), "hash")
+	var resp struct {
+		Data auth.APIKey `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, uint64(1), resp.Data.RequestCount)
+	require.NotNil(t, resp.Data.LastUsedAt)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+key.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	_, err = keys.Authenticate(secret)
+	require.ErrorIs(t, err, auth.ErrAPIKeyRevoked)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/api-keys/missing", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
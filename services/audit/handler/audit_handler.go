@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler exposes the audit log for dispute investigation
+type AuditHandler struct {
+	log *audit.Log
+}
+
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// auditPage is one page of matching entries. NextAfter is the after value for the next request.
+type auditPage struct {
+	Entries   []audit.Entry `json:"entries"`
+	NextAfter uint64        `json:"next_after"`
+}
+
+// GetAuditLogHandler handles GET /admin/audit?item_id=&user_id=&action=&from=&to=&after=&limit=
+func (h *AuditHandler) GetAuditLogHandler(c *gin.Context) {
+	filter, err := parseAuditQuery(c)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	entries := h.log.Query(filter)
+	page := auditPage{Entries: entries, NextAfter: filter.After}
+	if n := len(entries); n > 0 {
+		page.NextAfter = entries[n-1].Seq
+	}
+	utils.JSONResponse(c, http.StatusOK, page, "audit entries retrieved successfully")
+}
+
+// parseAuditQuery reads the filters; from and to are RFC 3339 timestamps
+func parseAuditQuery(c *gin.Context) (audit.Filter, error) {
+	f := audit.Filter{
+		ItemID: c.Query("item_id"),
+		UserID: c.Query("user_id"),
+		Action: c.Query("action"),
+		Limit:  defaultAuditLimit,
+	}
+
+	var err error
+	if f.From, err = parseTime(c.Query("from")); err != nil {
+		return audit.Filter{}, fmt.Errorf("from: %w", err)
+	}
+	if f.To, err = parseTime(c.Query("to")); err != nil {
+		return audit.Filter{}, fmt.Errorf("to: %w", err)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return audit.Filter{}, errors.New("from must be before to")
+	}
+
+	if raw := c.Query("after"); raw != "" {
+		if f.After, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return audit.Filter{}, fmt.Errorf("after must be a non-negative integer: %w", err)
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return audit.Filter{}, errors.New("limit must be a positive integer")
+		}
+		f.Limit = min(v, maxAuditLimit)
+	}
+	return f, nil
+}
+
+// parseTime parses an optional RFC 3339 timestamp
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
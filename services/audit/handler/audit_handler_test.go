@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test filtering and paging through the audit log
+func TestGetAuditLogHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	log := audit.NewMemoryLog()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, item := range []string{"item1", "item2", "item1"} {
+		_, err := log.Append(audit.Entry{At: base.Add(time.Duration(i) * time.Hour), Action: audit.ActionBidPlaced, Actor: "user1", ItemID: item})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		wantSeqs       []uint64
+		wantNextAfter  uint64
+	}{
+		{name: "all", query: "", expectedStatus: http.StatusOK, wantSeqs: []uint64{1, 2, 3}, wantNextAfter: 3},
+		{name: "by_item", query: "?item_id=item1", expectedStatus: http.StatusOK, wantSeqs: []uint64{1, 3}, wantNextAfter: 3},
+		{name: "time_range", query: "?from=2024-01-01T12:30:00Z&to=2024-01-01T14:00:00Z", expectedStatus: http.StatusOK, wantSeqs: []uint64{2}, wantNextAfter: 2},
+		{name: "paging", query: "?after=1&limit=1", expectedStatus: http.StatusOK, wantSeqs: []uint64{2}, wantNextAfter: 2},
+		{name: "no_match_keeps_cursor", query: "?after=3", expectedStatus: http.StatusOK, wantSeqs: []uint64{}, wantNextAfter: 3},
+		{name: "invalid_from", query: "?from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "inverted_range", query: "?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z", expectedStatus: http.StatusBadRequest},
+		{name: "invalid_limit", query: "?limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/audit", NewAuditHandler(log).GetAuditLogHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit"+tc.query, nil))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data auditPage `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			seqs := []uint64{}
+			for _, e := range resp.Data.Entries {
+				seqs = append(seqs, e.Seq)
+			}
+			require.Equal(t, tc.wantSeqs, seqs)
+			require.Equal(t, tc.wantNextAfter, resp.Data.NextAfter)
+		})
+	}
+}
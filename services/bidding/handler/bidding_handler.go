@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"bidding-tracker/client"
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/internal/bidchain"
 	"bidding-tracker/internal/biddingerrors"
 	model "bidding-tracker/internal/models"
 	"bidding-tracker/services/bidding/helpers"
@@ -14,19 +19,50 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	errMissingUserID   = errors.New("user_id is required")
+	errBidAsOtherUser  = errors.New("user_id does not match the authenticated user")
+	errOtherUsersItems = errors.New("items of another user are not accessible")
+	errNotItemOwner    = errors.New("item belongs to another seller")
+)
+
 type BiddingServiceInterface interface {
-	PlaceBid(itemID, userID string, amount float64) (model.Bid, error)
-	GetBidsForItem(itemID string) ([]model.Bid, error)
-	GetWinningBid(itemID string) (model.Bid, error)
-	GetItemsByUser(userID string) ([]model.Item, error)
+	PlaceBid(ctx context.Context, itemID, userID string, amount float64) (model.Bid, error)
+	GetBidsForItem(ctx context.Context, itemID string) ([]model.Bid, error)
+	GetWinningBid(ctx context.Context, itemID string) (model.Bid, error)
+	WaitForWinningBid(ctx context.Context, itemID, afterBidID string, timeout time.Duration) (model.Bid, error)
+	GetItemsByUser(ctx context.Context, userID string) ([]model.Item, error)
+	GetItem(ctx context.Context, itemID string) (model.Item, error)
+	VerifyChain(ctx context.Context, itemID string) (bidchain.Result, error)
+	CloseAuction(ctx context.Context, itemID string) (model.Item, *model.Bid, error)
+}
+
+// ReceiptSigner signs the receipt returned for an accepted bid
+type ReceiptSigner interface {
+	Sign(bid model.Bid) client.Receipt
 }
 
 type BiddingHandler struct {
-	service BiddingServiceInterface
+	service  BiddingServiceInterface
+	receipts ReceiptSigner
+}
+
+// Option configures optional BiddingHandler dependencies
+type Option func(*BiddingHandler)
+
+// WithReceipts makes POST /bids return a signed receipt for every accepted bid
+func WithReceipts(signer ReceiptSigner) Option {
+	return func(h *BiddingHandler) {
+		h.receipts = signer
+	}
 }
 
-func NewBiddingHandler(service BiddingServiceInterface) *BiddingHandler {
-	return &BiddingHandler{service: service}
+func NewBiddingHandler(service BiddingServiceInterface, opts ...Option) *BiddingHandler {
+	h := &BiddingHandler{service: service}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // RecordBidHandler handles POST /bids
@@ -37,11 +73,24 @@ func (h *BiddingHandler) RecordBidHandler(c *gin.Context) {
 		return
 	}
 
-	bid, err := h.service.PlaceBid(req.ItemID, req.UserID, req.Amount)
+	// an authenticated caller always bids as themselves
+	if principal, ok := auth.PrincipalFrom(c.Request.Context()); ok {
+		if req.UserID != "" && req.UserID != principal.UserID {
+			utils.JSONErrorCode(c, http.StatusForbidden, auth.CodeNotOwner, errBidAsOtherUser, "cannot bid on behalf of another user")
+			utils.WarnContext(c.Request.Context(), "RecordBidHandler: user_id does not match token", map[string]any{"user_id": req.UserID, "principal": principal.UserID})
+			return
+		}
+		req.UserID = principal.UserID
+	} else if req.UserID == "" {
+		helpers.HandleBindError(c, "RecordBidHandler", errMissingUserID)
+		return
+	}
+
+	bid, err := h.service.PlaceBid(c.Request.Context(), req.ItemID, req.UserID, req.Amount)
 	if err != nil {
 		status, message := helpers.MapErrorToHTTP(err)
 		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
-		utils.Error("RecordBidHandler: failed to record bid", map[string]any{
+		utils.ErrorContext(c.Request.Context(), "RecordBidHandler: failed to record bid", map[string]any{
 			"handler": "RecordBidHandler",
 			"item_id": req.ItemID,
 			"user_id": req.UserID,
@@ -50,16 +99,14 @@ func (h *BiddingHandler) RecordBidHandler(c *gin.Context) {
 		return
 	}
 
-	resp := helpers.BidResponse{
-		BidID:     bid.BidID,
-		ItemID:    bid.ItemID,
-		UserID:    bid.UserID,
-		Amount:    bid.Amount,
-		CreatedAt: bid.CreatedAt.UTC().Format(time.RFC3339),
+	resp := bidResponse(bid)
+	if h.receipts != nil {
+		receipt := h.receipts.Sign(bid)
+		resp.Receipt = &receipt
 	}
 
 	utils.JSONResponse(c, http.StatusCreated, resp, "bid recorded successfully")
-	helpers.LogSuccess("RecordBidHandler", "bid recorded successfully", map[string]any{
+	helpers.LogSuccess(c, "RecordBidHandler", "bid recorded successfully", map[string]any{
 		"bid_id":  bid.BidID,
 		"item_id": bid.ItemID,
 		"user_id": req.UserID,
@@ -67,14 +114,52 @@ func (h *BiddingHandler) RecordBidHandler(c *gin.Context) {
 	})
 }
 
+// CloseAuctionHandler handles POST /items/:item_id/close
+func (h *BiddingHandler) CloseAuctionHandler(c *gin.Context) {
+	itemID := c.Param("item_id")
+	if principal, ok := auth.PrincipalFrom(c.Request.Context()); ok {
+		item, err := h.service.GetItem(c.Request.Context(), itemID)
+		if err != nil {
+			status, message := helpers.MapErrorToHTTP(err)
+			utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+			utils.WarnContext(c.Request.Context(), "CloseAuctionHandler: failed to get item", map[string]any{"item_id": itemID, "error": err.Error()})
+			return
+		}
+		if !principal.CanAccessOwned(item.Owner, auth.PermAdmin) {
+			utils.JSONErrorCode(c, http.StatusForbidden, auth.CodeNotOwner, errNotItemOwner, "cannot close another seller's auction")
+			return
+		}
+	}
+
+	item, winner, err := h.service.CloseAuction(c.Request.Context(), itemID)
+	if err != nil {
+		status, message := helpers.MapErrorToHTTP(err)
+		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+		utils.WarnContext(c.Request.Context(), "CloseAuctionHandler: failed to close auction", map[string]any{"item_id": itemID, "error": err.Error()})
+		return
+	}
+
+	resp := helpers.CloseAuctionResponse{Item: item}
+	fields := map[string]any{"item_id": itemID}
+	if winner != nil {
+		bid := bidResponse(*winner)
+		resp.Winner = &bid
+		fields["winner"] = winner.UserID
+		fields["amount"] = winner.Amount
+	}
+
+	utils.JSONResponse(c, http.StatusOK, resp, "auction closed")
+	helpers.LogSuccess(c, "CloseAuctionHandler", "auction closed", fields)
+}
+
 // GetBidsByItemHandler handles GET /items/:item_id/bids
 func (h *BiddingHandler) GetBidsByItemHandler(c *gin.Context) {
 	itemID := c.Param("item_id")
-	bids, err := h.service.GetBidsForItem(itemID)
+	bids, err := h.service.GetBidsForItem(c.Request.Context(), itemID)
 	if err != nil && !errors.Is(err, biddingerrors.ErrNoBids) {
 		status, message := helpers.MapErrorToHTTP(err)
 		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
-		utils.Warn("GetBidsByItemHandler: error retrieving bids", map[string]any{"item_id": itemID, "error": err.Error()})
+		utils.WarnContext(c.Request.Context(), "GetBidsByItemHandler: error retrieving bids", map[string]any{"item_id": itemID, "error": err.Error()})
 		return
 	}
 
@@ -83,39 +168,58 @@ func (h *BiddingHandler) GetBidsByItemHandler(c *gin.Context) {
 	}
 
 	utils.JSONResponse(c, http.StatusOK, bids, "bids retrieved successfully")
-	helpers.LogSuccess("GetBidsByItemHandler", "bids retrieved successfully", map[string]any{
+	helpers.LogSuccess(c, "GetBidsByItemHandler", "bids retrieved successfully", map[string]any{
 		"item_id": itemID,
 		"count":   len(bids),
 	})
 }
 
-// GetWinningBidHandler handles GET /items/:item_id/winning
+// maxLongPollWait caps the wait parameter of GET /items/:item_id/winning
+const maxLongPollWait = 60 * time.Second
+
+// GetWinningBidHandler handles GET /items/:item_id/winning. With ?wait=30s (and optionally
+// after_bid_id=<the leader the client already has>) it long-polls until the leader changes.
 func (h *BiddingHandler) GetWinningBidHandler(c *gin.Context) {
 	itemID := c.Param("item_id")
-	bid, err := h.service.GetWinningBid(itemID)
+	afterBidID := c.Query("after_bid_id")
+	wait, err := parseWait(c.Query("wait"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err, "invalid wait parameter")
+		return
+	}
+
+	var bid model.Bid
+	if wait > 0 {
+		// the wait may outlast the server's write timeout; a recorder cannot set deadlines
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + streamWriteWait))
+		bid, err = h.service.WaitForWinningBid(c.Request.Context(), itemID, afterBidID, wait)
+	} else {
+		bid, err = h.service.GetWinningBid(c.Request.Context(), itemID)
+	}
 	if err != nil {
 		status, message := helpers.MapErrorToHTTP(err)
 		// For auction, winning bid not found -> 404
 		if errors.Is(err, biddingerrors.ErrNoBids) {
 			utils.JSONError(c, http.StatusNotFound, err, "no winning bid found")
-			utils.Info("GetWinningBidHandler: no winning bid found", map[string]any{"item_id": itemID})
+			utils.InfoContext(c.Request.Context(), "GetWinningBidHandler: no winning bid found", map[string]any{"item_id": itemID})
 			return
 		}
 		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
-		utils.Warn("GetWinningBidHandler: winning bid error", map[string]any{"item_id": itemID, "error": err.Error()})
+		utils.WarnContext(c.Request.Context(), "GetWinningBidHandler: winning bid error", map[string]any{"item_id": itemID, "error": err.Error()})
 		return
 	}
 
-	resp := helpers.BidResponse{
-		BidID:     bid.BidID,
-		ItemID:    bid.ItemID,
-		UserID:    bid.UserID,
-		Amount:    bid.Amount,
-		CreatedAt: bid.CreatedAt.UTC().Format(time.RFC3339),
+	resp := bidResponse(bid)
+	resp.ChainHead = bid.Hash // the winner is always the item's latest bid
+
+	if wait > 0 && afterBidID != "" && bid.BidID == afterBidID {
+		// timed out without a new leader
+		utils.JSONResponse(c, http.StatusOK, resp, "winning bid unchanged")
+		return
 	}
 
 	utils.JSONResponse(c, http.StatusOK, resp, "winning bid retrieved successfully")
-	helpers.LogSuccess("GetWinningBidHandler", "winning bid retrieved successfully", map[string]any{
+	helpers.LogSuccess(c, "GetWinningBidHandler", "winning bid retrieved successfully", map[string]any{
 		"bid_id":  bid.BidID,
 		"item_id": bid.ItemID,
 		"user_id": bid.UserID,
@@ -123,14 +227,79 @@ func (h *BiddingHandler) GetWinningBidHandler(c *gin.Context) {
 	})
 }
 
+// bidResponse converts a bid to its API representation
+func bidResponse(bid model.Bid) helpers.BidResponse {
+	return helpers.BidResponse{
+		BidID:     bid.BidID,
+		ItemID:    bid.ItemID,
+		UserID:    bid.UserID,
+		Amount:    bid.Amount,
+		CreatedAt: bid.CreatedAt.UTC().Format(time.RFC3339),
+		Seq:       bid.Seq,
+		PrevHash:  bid.PrevHash,
+		Hash:      bid.Hash,
+	}
+}
+
+// parseWait reads a long-poll duration such as "30s" or "30" (seconds), capped at maxLongPollWait.
+// An empty value means no waiting.
+func parseWait(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		secs, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return 0, fmt.Errorf("wait must be a duration such as 30s: %w", err)
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d < 0 {
+		return 0, errors.New("wait must not be negative")
+	}
+	return min(d, maxLongPollWait), nil
+}
+
+// VerifyChainHandler handles GET /items/:item_id/chain/verify. A broken chain is
+// a successful verification with valid=false and the location of the break.
+func (h *BiddingHandler) VerifyChainHandler(c *gin.Context) {
+	itemID := c.Param("item_id")
+	res, err := h.service.VerifyChain(c.Request.Context(), itemID)
+	if err != nil && !errors.Is(err, bidchain.ErrBroken) {
+		status, message := helpers.MapErrorToHTTP(err)
+		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+		utils.WarnContext(c.Request.Context(), "VerifyChainHandler: failed to verify chain", map[string]any{"item_id": itemID, "error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		utils.JSONResponse(c, http.StatusOK, helpers.ChainVerificationResponse{ItemID: itemID, Error: err.Error()}, "bid chain broken")
+		utils.ErrorContext(c.Request.Context(), "VerifyChainHandler: bid chain broken", map[string]any{"item_id": itemID, "error": err.Error()})
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, helpers.ChainVerificationResponse{
+		ItemID:    itemID,
+		Valid:     true,
+		Bids:      res.Bids,
+		Unchained: res.Unchained,
+		Head:      res.Head,
+	}, "bid chain verified")
+}
+
 // GetItemsByUserHandler handles GET /users/:user_id/items
 func (h *BiddingHandler) GetItemsByUserHandler(c *gin.Context) {
 	userID := c.Param("user_id")
-	items, err := h.service.GetItemsByUser(userID)
+	if principal, ok := auth.PrincipalFrom(c.Request.Context()); ok && !principal.CanAccessOwned(userID, auth.PermUsersRead) {
+		utils.JSONErrorCode(c, http.StatusForbidden, auth.CodeNotOwner, errOtherUsersItems, "cannot read another user's items")
+		return
+	}
+	items, err := h.service.GetItemsByUser(c.Request.Context(), userID)
 	if err != nil && !errors.Is(err, biddingerrors.ErrUserNoBids) {
 		status, message := helpers.MapErrorToHTTP(err)
 		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
-		utils.Warn("GetItemsByUserHandler: error retrieving items", map[string]any{"user_id": userID, "error": err.Error()})
+		utils.WarnContext(c.Request.Context(), "GetItemsByUserHandler: error retrieving items", map[string]any{"user_id": userID, "error": err.Error()})
 		return
 	}
 
@@ -139,7 +308,7 @@ func (h *BiddingHandler) GetItemsByUserHandler(c *gin.Context) {
 	}
 
 	utils.JSONResponse(c, http.StatusOK, items, "items retrieved successfully")
-	helpers.LogSuccess("GetItemsByUserHandler", "items retrieved successfully", map[string]any{
+	helpers.LogSuccess(c, "GetItemsByUserHandler", "items retrieved successfully", map[string]any{
 		"user_id":     userID,
 		"items_count": len(items),
 	})
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"bidding-tracker/internal/bidchain"
 	"bidding-tracker/internal/biddingerrors"
 	model "bidding-tracker/internal/models"
 	"bidding-tracker/services/bidding/helpers"
@@ -52,7 +54,7 @@ func TestRecordBidHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockService.EXPECT().
-					PlaceBid("item1", "user1", 100.0).
+					PlaceBid(gomock.Any(), "item1", "user1", 100.0).
 					Return(model.Bid{
 						BidID:     uuid.NewString(),
 						ItemID:    "item1",
@@ -133,7 +135,7 @@ func TestRecordBidHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockService.EXPECT().
-					PlaceBid("item1", "user1", 50.0).
+					PlaceBid(gomock.Any(), "item1", "user1", 50.0).
 					Return(model.Bid{}, biddingerrors.ErrBidTooLow)
 			},
 			expectedStatus: http.StatusConflict,
@@ -148,7 +150,7 @@ func TestRecordBidHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockService.EXPECT().
-					PlaceBid("item1", "user1", 1.0).
+					PlaceBid(gomock.Any(), "item1", "user1", 1.0).
 					Return(model.Bid{}, biddingerrors.ErrInvalidBid)
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -163,12 +165,27 @@ func TestRecordBidHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockService.EXPECT().
-					PlaceBid("item1", "user1", 100.0).
+					PlaceBid(gomock.Any(), "item1", "user1", 100.0).
 					Return(model.Bid{}, errors.New("database failure"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedMsg:    "internal server error",
 		},
+		{
+			name: "service_deadline_exceeded",
+			requestBody: helpers.PlaceBidRequest{
+				ItemID: "item1",
+				UserID: "user1",
+				Amount: 100,
+			},
+			mockSetup: func() {
+				mockService.EXPECT().
+					PlaceBid(gomock.Any(), "item1", "user1", 100.0).
+					Return(model.Bid{}, context.DeadlineExceeded)
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedMsg:    "request timed out",
+		},
 		{
 			name: "extremely_large_amount",
 			requestBody: helpers.PlaceBidRequest{
@@ -178,7 +195,7 @@ func TestRecordBidHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				mockService.EXPECT().
-					PlaceBid("item1", "user1", 1e18).
+					PlaceBid(gomock.Any(), "item1", "user1", 1e18).
 					Return(model.Bid{
 						BidID:     uuid.NewString(),
 						ItemID:    "item1",
@@ -268,7 +285,7 @@ func TestGetBidsByItemHandler(t *testing.T) {
 			itemID: "item1",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetBidsForItem("item1").
+					GetBidsForItem(gomock.Any(), "item1").
 					Return([]model.Bid{
 						{BidID: uuid.NewString(), ItemID: "item1", UserID: "user1", Amount: 100, CreatedAt: now},
 						{BidID: uuid.NewString(), ItemID: "item1", UserID: "user2", Amount: 150, CreatedAt: now},
@@ -287,7 +304,7 @@ func TestGetBidsByItemHandler(t *testing.T) {
 			itemID: "item2",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetBidsForItem("item2").
+					GetBidsForItem(gomock.Any(), "item2").
 					Return([]model.Bid{}, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -301,7 +318,7 @@ func TestGetBidsByItemHandler(t *testing.T) {
 			itemID: "item3",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetBidsForItem("item3").
+					GetBidsForItem(gomock.Any(), "item3").
 					Return(nil, biddingerrors.ErrNoBids)
 			},
 			expectedStatus: http.StatusOK,
@@ -315,7 +332,7 @@ func TestGetBidsByItemHandler(t *testing.T) {
 			itemID: "item4",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetBidsForItem("item4").
+					GetBidsForItem(gomock.Any(), "item4").
 					Return(nil, errors.New("database failure"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -327,7 +344,7 @@ func TestGetBidsByItemHandler(t *testing.T) {
 			itemID: "item5",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetBidsForItem("item5").
+					GetBidsForItem(gomock.Any(), "item5").
 					Return(nil, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -350,7 +367,7 @@ func TestGetBidsByItemHandler(t *testing.T) {
 						CreatedAt: now,
 					}
 				}
-				mockService.EXPECT().GetBidsForItem("item6").Return(bids, nil)
+				mockService.EXPECT().GetBidsForItem(gomock.Any(), "item6").Return(bids, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedMsg:    "bids retrieved successfully",
@@ -420,13 +437,15 @@ func TestGetWinningBidHandler(t *testing.T) {
 			itemID: "item1",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetWinningBid("item1").
+					GetWinningBid(gomock.Any(), "item1").
 					Return(model.Bid{
 						BidID:     uuid.NewString(),
 						ItemID:    "item1",
 						UserID:    "user1",
 						Amount:    150.0,
 						CreatedAt: now,
+						PrevHash:  "prev",
+						Hash:      "head",
 					}, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -439,6 +458,8 @@ func TestGetWinningBidHandler(t *testing.T) {
 				require.Equal(t, "item1", data["item_id"])
 				require.Equal(t, "user1", data["user_id"])
 				require.Equal(t, 150.0, data["amount"])
+				require.Equal(t, "prev", data["prev_hash"])
+				require.Equal(t, "head", data["chain_head"])
 			},
 		},
 		{
@@ -446,7 +467,7 @@ func TestGetWinningBidHandler(t *testing.T) {
 			itemID: "item2",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetWinningBid("item2").
+					GetWinningBid(gomock.Any(), "item2").
 					Return(model.Bid{}, biddingerrors.ErrNoBids)
 			},
 			expectedStatus: http.StatusNotFound,
@@ -457,7 +478,7 @@ func TestGetWinningBidHandler(t *testing.T) {
 			itemID: "item3",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetWinningBid("item3").
+					GetWinningBid(gomock.Any(), "item3").
 					Return(model.Bid{}, errors.New("DB connection failed"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -468,7 +489,7 @@ func TestGetWinningBidHandler(t *testing.T) {
 			itemID: "item4",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetWinningBid("item4").
+					GetWinningBid(gomock.Any(), "item4").
 					Return(model.Bid{
 						BidID:     uuid.NewString(),
 						ItemID:    "item4",
@@ -488,7 +509,7 @@ func TestGetWinningBidHandler(t *testing.T) {
 			itemID: "item1",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetWinningBid("item1").
+					GetWinningBid(gomock.Any(), "item1").
 					Return(model.Bid{
 						BidID:     uuid.NewString(),
 						ItemID:    "item1",
@@ -510,7 +531,7 @@ func TestGetWinningBidHandler(t *testing.T) {
 			itemID: "",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetWinningBid("").
+					GetWinningBid(gomock.Any(), "").
 					Return(model.Bid{}, fmt.Errorf("invalid item_id"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -545,6 +566,261 @@ func TestGetWinningBidHandler(t *testing.T) {
 	}
 }
 
+// Test VerifyChainHandler
+func TestVerifyChainHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockBiddingServiceInterface(ctrl)
+	handler := NewBiddingHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items/:item_id/chain/verify", handler.VerifyChainHandler)
+
+	tests := []struct {
+		name           string
+		itemID         string
+		mockSetup      func()
+		expectedStatus int
+		expectedMsg    string
+		wantValid      bool
+	}{
+		{
+			name:   "intact",
+			itemID: "item1",
+			mockSetup: func() {
+				mockService.EXPECT().VerifyChain(gomock.Any(), "item1").Return(bidchain.Result{Bids: 2, Head: "head"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "bid chain verified",
+			wantValid:      true,
+		},
+		{
+			name:   "broken",
+			itemID: "item2",
+			mockSetup: func() {
+				mockService.EXPECT().VerifyChain(gomock.Any(), "item2").
+					Return(bidchain.Result{}, fmt.Errorf("service: %w", &bidchain.BrokenError{Index: 1, BidID: "bid2", Reason: "hash mismatch"}))
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "bid chain broken",
+		},
+		{
+			name:   "storage_error",
+			itemID: "item3",
+			mockSetup: func() {
+				mockService.EXPECT().VerifyChain(gomock.Any(), "item3").Return(bidchain.Result{}, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedMsg:    "internal server error",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/items/"+tc.itemID+"/chain/verify", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedMsg, resp["message"])
+			if w.Code == http.StatusOK {
+				data := resp["data"].(map[string]any)
+				require.Equal(t, tc.wantValid, data["valid"])
+				if !tc.wantValid {
+					require.Contains(t, data["error"], "bid2")
+				}
+			}
+		})
+	}
+}
+
+// Test CloseAuctionHandler
+func TestCloseAuctionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockBiddingServiceInterface(ctrl)
+	handler := NewBiddingHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/items/:item_id/close", handler.CloseAuctionHandler)
+
+	closedAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	winner := model.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 150, CreatedAt: closedAt.Add(-time.Hour)}
+
+	tests := []struct {
+		name           string
+		itemID         string
+		mockSetup      func()
+		expectedStatus int
+		expectedMsg    string
+		wantWinner     string
+	}{
+		{
+			name:   "with_winner",
+			itemID: "item1",
+			mockSetup: func() {
+				mockService.EXPECT().CloseAuction(gomock.Any(), "item1").Return(model.Item{ItemID: "item1", ClosedAt: &closedAt}, &winner, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "auction closed",
+			wantWinner:     "user1",
+		},
+		{
+			name:   "without_bids",
+			itemID: "item2",
+			mockSetup: func() {
+				mockService.EXPECT().CloseAuction(gomock.Any(), "item2").Return(model.Item{ItemID: "item2", ClosedAt: &closedAt}, nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "auction closed",
+		},
+		{
+			name:   "already_closed",
+			itemID: "item3",
+			mockSetup: func() {
+				mockService.EXPECT().CloseAuction(gomock.Any(), "item3").Return(model.Item{}, nil, fmt.Errorf("service: %w", biddingerrors.ErrAuctionClosed))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedMsg:    "auction is closed",
+		},
+		{
+			name:   "item_not_found",
+			itemID: "item4",
+			mockSetup: func() {
+				mockService.EXPECT().CloseAuction(gomock.Any(), "item4").Return(model.Item{}, nil, fmt.Errorf("service: %w", biddingerrors.ErrItemNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedMsg:    "item not found",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/items/"+tc.itemID+"/close", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+
+			var resp struct {
+				Message string                       `json:"message"`
+				Data    helpers.CloseAuctionResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedMsg, resp.Message)
+			if w.Code != http.StatusOK {
+				return
+			}
+			require.Equal(t, tc.itemID, resp.Data.Item.ItemID)
+			require.NotNil(t, resp.Data.Item.ClosedAt)
+			if tc.wantWinner == "" {
+				require.Nil(t, resp.Data.Winner)
+			} else {
+				require.NotNil(t, resp.Data.Winner)
+				require.Equal(t, tc.wantWinner, resp.Data.Winner.UserID)
+			}
+		})
+	}
+}
+
+// Test the long-poll parameters of GetWinningBidHandler
+func TestGetWinningBidHandler_LongPoll(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	leader := model.Bid{BidID: "bid2", ItemID: "item1", UserID: "user2", Amount: 150, CreatedAt: time.Now().UTC()}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(m *MockBiddingServiceInterface)
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{
+			name:  "new_leader",
+			query: "?after_bid_id=bid1&wait=30s",
+			mockSetup: func(m *MockBiddingServiceInterface) {
+				m.EXPECT().WaitForWinningBid(gomock.Any(), "item1", "bid1", 30*time.Second).Return(leader, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "winning bid retrieved successfully",
+		},
+		{
+			name:  "timeout_unchanged",
+			query: "?after_bid_id=bid2&wait=10",
+			mockSetup: func(m *MockBiddingServiceInterface) {
+				m.EXPECT().WaitForWinningBid(gomock.Any(), "item1", "bid2", 10*time.Second).Return(leader, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "winning bid unchanged",
+		},
+		{
+			name:  "wait_capped",
+			query: "?wait=10m",
+			mockSetup: func(m *MockBiddingServiceInterface) {
+				m.EXPECT().WaitForWinningBid(gomock.Any(), "item1", "", maxLongPollWait).Return(model.Bid{}, biddingerrors.ErrNoBids)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedMsg:    "no winning bid found",
+		},
+		{
+			name:           "invalid_wait",
+			query:          "?wait=soon",
+			mockSetup:      func(m *MockBiddingServiceInterface) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid wait parameter",
+		},
+		{
+			name:           "negative_wait",
+			query:          "?wait=-5s",
+			mockSetup:      func(m *MockBiddingServiceInterface) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid wait parameter",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			ctrl := gomock.NewController(t)
+			mockService := NewMockBiddingServiceInterface(ctrl)
+			tc.mockSetup(mockService)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/items/:item_id/winning", NewBiddingHandler(mockService).GetWinningBidHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/items/item1/winning"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedMsg, resp["message"])
+		})
+	}
+}
+
 // Test GetItemsByUserHandler
 func TestGetItemsByUserHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -571,7 +847,7 @@ func TestGetItemsByUserHandler(t *testing.T) {
 			userID: "user1",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetItemsByUser("user1").
+					GetItemsByUser(gomock.Any(), "user1").
 					Return([]model.Item{
 						{ItemID: "item1", Title: "title1", Description: "description1", StartingPrice: 50.0},
 						{ItemID: "item2", Title: "title2", Description: "description2", StartingPrice: 100.0},
@@ -597,7 +873,7 @@ func TestGetItemsByUserHandler(t *testing.T) {
 			userID: "user2",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetItemsByUser("user2").
+					GetItemsByUser(gomock.Any(), "user2").
 					Return([]model.Item{}, biddingerrors.ErrUserNoBids)
 			},
 			expectedStatus: http.StatusOK,
@@ -611,7 +887,7 @@ func TestGetItemsByUserHandler(t *testing.T) {
 			userID: "user3",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetItemsByUser("user3").
+					GetItemsByUser(gomock.Any(), "user3").
 					Return(nil, errors.New("DB connection failed"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -622,7 +898,7 @@ func TestGetItemsByUserHandler(t *testing.T) {
 			userID: "",
 			mockSetup: func() {
 				mockService.EXPECT().
-					GetItemsByUser("").
+					GetItemsByUser(gomock.Any(), "").
 					Return(nil, fmt.Errorf("invalid user_id"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -642,7 +918,7 @@ func TestGetItemsByUserHandler(t *testing.T) {
 					}
 				}
 				mockService.EXPECT().
-					GetItemsByUser("user_large").
+					GetItemsByUser(gomock.Any(), "user_large").
 					Return(items, nil)
 			},
 			expectedStatus: http.StatusOK,
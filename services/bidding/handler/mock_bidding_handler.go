@@ -5,8 +5,12 @@
 package handler
 
 import (
+	client "bidding-tracker/client"
+	bidchain "bidding-tracker/internal/bidchain"
 	models "bidding-tracker/internal/models"
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,62 +38,160 @@ func (m *MockBiddingServiceInterface) EXPECT() *MockBiddingServiceInterfaceMockR
 	return m.recorder
 }
 
+// CloseAuction mocks base method.
+func (m *MockBiddingServiceInterface) CloseAuction(ctx context.Context, itemID string) (models.Item, *models.Bid, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAuction", ctx, itemID)
+	ret0, _ := ret[0].(models.Item)
+	ret1, _ := ret[1].(*models.Bid)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CloseAuction indicates an expected call of CloseAuction.
+func (mr *MockBiddingServiceInterfaceMockRecorder) CloseAuction(ctx, itemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAuction", reflect.TypeOf((*MockBiddingServiceInterface)(nil).CloseAuction), ctx, itemID)
+}
+
 // GetBidsForItem mocks base method.
-func (m *MockBiddingServiceInterface) GetBidsForItem(itemID string) ([]models.Bid, error) {
+func (m *MockBiddingServiceInterface) GetBidsForItem(ctx context.Context, itemID string) ([]models.Bid, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBidsForItem", itemID)
+	ret := m.ctrl.Call(m, "GetBidsForItem", ctx, itemID)
 	ret0, _ := ret[0].([]models.Bid)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBidsForItem indicates an expected call of GetBidsForItem.
-func (mr *MockBiddingServiceInterfaceMockRecorder) GetBidsForItem(itemID interface{}) *gomock.Call {
+func (mr *MockBiddingServiceInterfaceMockRecorder) GetBidsForItem(ctx, itemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBidsForItem", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetBidsForItem), itemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBidsForItem", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetBidsForItem), ctx, itemID)
+}
+
+// GetItem mocks base method.
+func (m *MockBiddingServiceInterface) GetItem(ctx context.Context, itemID string) (models.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, itemID)
+	ret0, _ := ret[0].(models.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockBiddingServiceInterfaceMockRecorder) GetItem(ctx, itemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetItem), ctx, itemID)
 }
 
 // GetItemsByUser mocks base method.
-func (m *MockBiddingServiceInterface) GetItemsByUser(userID string) ([]models.Item, error) {
+func (m *MockBiddingServiceInterface) GetItemsByUser(ctx context.Context, userID string) ([]models.Item, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetItemsByUser", userID)
+	ret := m.ctrl.Call(m, "GetItemsByUser", ctx, userID)
 	ret0, _ := ret[0].([]models.Item)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetItemsByUser indicates an expected call of GetItemsByUser.
-func (mr *MockBiddingServiceInterfaceMockRecorder) GetItemsByUser(userID interface{}) *gomock.Call {
+func (mr *MockBiddingServiceInterfaceMockRecorder) GetItemsByUser(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByUser", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetItemsByUser), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByUser", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetItemsByUser), ctx, userID)
 }
 
 // GetWinningBid mocks base method.
-func (m *MockBiddingServiceInterface) GetWinningBid(itemID string) (models.Bid, error) {
+func (m *MockBiddingServiceInterface) GetWinningBid(ctx context.Context, itemID string) (models.Bid, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWinningBid", itemID)
+	ret := m.ctrl.Call(m, "GetWinningBid", ctx, itemID)
 	ret0, _ := ret[0].(models.Bid)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWinningBid indicates an expected call of GetWinningBid.
-func (mr *MockBiddingServiceInterfaceMockRecorder) GetWinningBid(itemID interface{}) *gomock.Call {
+func (mr *MockBiddingServiceInterfaceMockRecorder) GetWinningBid(ctx, itemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWinningBid", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetWinningBid), itemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWinningBid", reflect.TypeOf((*MockBiddingServiceInterface)(nil).GetWinningBid), ctx, itemID)
 }
 
 // PlaceBid mocks base method.
-func (m *MockBiddingServiceInterface) PlaceBid(itemID, userID string, amount float64) (models.Bid, error) {
+func (m *MockBiddingServiceInterface) PlaceBid(ctx context.Context, itemID, userID string, amount float64) (models.Bid, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PlaceBid", itemID, userID, amount)
+	ret := m.ctrl.Call(m, "PlaceBid", ctx, itemID, userID, amount)
 	ret0, _ := ret[0].(models.Bid)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PlaceBid indicates an expected call of PlaceBid.
-func (mr *MockBiddingServiceInterfaceMockRecorder) PlaceBid(itemID, userID, amount interface{}) *gomock.Call {
+func (mr *MockBiddingServiceInterfaceMockRecorder) PlaceBid(ctx, itemID, userID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceBid", reflect.TypeOf((*MockBiddingServiceInterface)(nil).PlaceBid), ctx, itemID, userID, amount)
+}
+
+// VerifyChain mocks base method.
+func (m *MockBiddingServiceInterface) VerifyChain(ctx context.Context, itemID string) (bidchain.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChain", ctx, itemID)
+	ret0, _ := ret[0].(bidchain.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChain indicates an expected call of VerifyChain.
+func (mr *MockBiddingServiceInterfaceMockRecorder) VerifyChain(ctx, itemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChain", reflect.TypeOf((*MockBiddingServiceInterface)(nil).VerifyChain), ctx, itemID)
+}
+
+// WaitForWinningBid mocks base method.
+func (m *MockBiddingServiceInterface) WaitForWinningBid(ctx context.Context, itemID, afterBidID string, timeout time.Duration) (models.Bid, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForWinningBid", ctx, itemID, afterBidID, timeout)
+	ret0, _ := ret[0].(models.Bid)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForWinningBid indicates an expected call of WaitForWinningBid.
+func (mr *MockBiddingServiceInterfaceMockRecorder) WaitForWinningBid(ctx, itemID, afterBidID, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForWinningBid", reflect.TypeOf((*MockBiddingServiceInterface)(nil).WaitForWinningBid), ctx, itemID, afterBidID, timeout)
+}
+
+// MockReceiptSigner is a mock of ReceiptSigner interface.
+type MockReceiptSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockReceiptSignerMockRecorder
+}
+
+// MockReceiptSignerMockRecorder is the mock recorder for MockReceiptSigner.
+type MockReceiptSignerMockRecorder struct {
+	mock *MockReceiptSigner
+}
+
+// NewMockReceiptSigner creates a new mock instance.
+func NewMockReceiptSigner(ctrl *gomock.Controller) *MockReceiptSigner {
+	mock := &MockReceiptSigner{ctrl: ctrl}
+	mock.recorder = &MockReceiptSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReceiptSigner) EXPECT() *MockReceiptSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method.
+func (m *MockReceiptSigner) Sign(bid models.Bid) client.Receipt {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", bid)
+	ret0, _ := ret[0].(client.Receipt)
+	return ret0
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockReceiptSignerMockRecorder) Sign(bid interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceBid", reflect.TypeOf((*MockBiddingServiceInterface)(nil).PlaceBid), itemID, userID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockReceiptSigner)(nil).Sign), bid)
 }
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"bidding-tracker/client"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReceiptKeysHandler publishes the public keys that verify bid receipts
+type ReceiptKeysHandler struct {
+	keys client.KeySet
+}
+
+func NewReceiptKeysHandler(keys client.KeySet) *ReceiptKeysHandler {
+	return &ReceiptKeysHandler{keys: keys}
+}
+
+// GetReceiptKeysHandler handles GET /.well-known/bid-receipt-keys. The key set is served
+// bare, without the usual response envelope, so standard JWK tooling can read it.
+func (h *ReceiptKeysHandler) GetReceiptKeysHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.keys)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bidding-tracker/client"
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/receipt"
+	"bidding-tracker/services/bidding/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that accepted bids carry a receipt that verifies against the published key set
+func TestRecordBidHandler_Receipt(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := receipt.NewSigner(key)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	mockService := NewMockBiddingServiceInterface(ctrl)
+	bid := model.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 150.5, CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.UTC), Seq: 3}
+	mockService.EXPECT().PlaceBid(gomock.Any(), "item1", "user1", 150.5).Return(bid, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/bids", NewBiddingHandler(mockService, WithReceipts(signer)).RecordBidHandler)
+	router.GET(client.ReceiptKeysPath, NewReceiptKeysHandler(signer.KeySet()).GetReceiptKeysHandler)
+
+	body, err := json.Marshal(helpers.PlaceBidRequest{ItemID: "item1", UserID: "user1", Amount: 150.5})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bids", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var resp struct {
+		Data helpers.BidResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, uint64(3), resp.Data.Seq)
+	require.NotNil(t, resp.Data.Receipt)
+	got := *resp.Data.Receipt
+	require.Equal(t, bid.BidID, got.BidID)
+	require.Equal(t, bid.Seq, got.Sequence)
+	require.Equal(t, signer.KeyID(), got.KeyID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, client.ReceiptKeysPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var keys client.KeySet
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &keys))
+	require.NoError(t, keys.Verify(got))
+
+	got.Amount = 1500.5
+	require.ErrorIs(t, keys.Verify(got), client.ErrInvalidSignature)
+}
+
+// Test that without a signer no receipt is returned
+func TestRecordBidHandler_NoReceipt(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	ctrl := gomock.NewController(t)
+	mockService := NewMockBiddingServiceInterface(ctrl)
+	mockService.EXPECT().PlaceBid(gomock.Any(), "item1", "user1", 100.0).
+		Return(model.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 100, CreatedAt: time.Now().UTC(), Seq: 1}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/bids", NewBiddingHandler(mockService).RecordBidHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bids", bytes.NewBufferString(`{"item_id":"item1","user_id":"user1","amount":100}`)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotContains(t, resp.Data, "receipt")
+	require.Equal(t, 1.0, resp.Data["seq"])
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bidding-tracker/internal/stream"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseRetry is the reconnection delay suggested to EventSource clients
+const sseRetry = 3 * time.Second
+
+// ItemEventsHandler handles GET /items/:item_id/events as a Server-Sent Events feed.
+// Event IDs are hub sequence numbers, so a reconnecting EventSource resumes from the
+// Last-Event-ID header it sends automatically.
+func (h *StreamHandler) ItemEventsHandler(c *gin.Context) {
+	itemID := c.Param("item_id")
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id") // for clients that cannot set headers
+	}
+	var since uint64
+	if lastID != "" {
+		v, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, err, "invalid Last-Event-ID")
+			return
+		}
+		since = v
+	}
+
+	sub, truncated := h.hub.Subscribe([]string{itemID}, since)
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // disable proxy buffering
+	c.Status(http.StatusOK)
+
+	// each frame is written under a deadline so a stalled client cannot pin the handler
+	rc := http.NewResponseController(c.Writer)
+	send := func(format string, args ...any) error {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteWait))
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := send("retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return
+	}
+	if truncated {
+		data, _ := json.Marshal(streamControl{Type: streamResync, Seq: h.hub.LastSeq(), Time: time.Now().UTC()})
+		if err := send("event: %s\ndata: %s\n\n", streamResync, data); err != nil {
+			return
+		}
+	}
+
+	utils.InfoContext(c.Request.Context(), "ItemEventsHandler: subscriber connected", map[string]any{"item_id": itemID, "since": since})
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// a dropped client reconnects with Last-Event-ID and replays what it missed
+				if errors.Is(sub.Err(), stream.ErrSlowConsumer) {
+					utils.WarnContext(c.Request.Context(), "ItemEventsHandler: dropping slow subscriber", map[string]any{"item_id": itemID})
+				}
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				utils.ErrorContext(c.Request.Context(), "ItemEventsHandler: failed to encode event", map[string]any{"item_id": itemID, "error": err.Error()})
+				return
+			}
+			if err := send("id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Type, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// comment lines keep intermediaries from closing an idle connection
+			if err := send(": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bidding-tracker/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// sseFrame is one parsed Server-Sent Events frame
+type sseFrame struct {
+	id    string
+	event string
+	data  string
+}
+
+// openEvents connects to the SSE endpoint and returns a function that reads the next event frame.
+// Comment and retry-only frames are skipped.
+func openEvents(t *testing.T, hub *stream.Hub, itemID, lastEventID string) func() sseFrame {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items/:item_id/events", NewStreamHandler(hub, 100*time.Millisecond).ItemEventsHandler)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/items/"+itemID+"/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	return func() sseFrame {
+		t.Helper()
+		var f sseFrame
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if f.event != "" || f.data != "" {
+					return f
+				}
+			case strings.HasPrefix(line, "id: "):
+				f.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				f.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				f.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		require.NoError(t, scanner.Err())
+		t.Fatal("event stream ended")
+		return f
+	}
+}
+
+// Test ItemEventsHandler replay and live delivery
+func TestItemEventsHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantResync  bool
+		wantIDs     []string
+	}{
+		{name: "live_only", lastEventID: "", wantIDs: []string{"6"}},
+		{name: "resume_from_last_event_id", lastEventID: "3", wantIDs: []string{"4", "5", "6"}},
+		{name: "resume_evicted_sends_resync", lastEventID: "1", wantResync: true, wantIDs: []string{"3", "4", "5", "6"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			// replay buffer holds the last 3 events (seqs 3-5, all item1); seq 6 is published live
+			hub := stream.NewHub(stream.HubConfig{ReplaySize: 3})
+			hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item1"})
+			hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item2"})
+			for i := 0; i < 3; i++ {
+				hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item1"})
+			}
+
+			next := openEvents(t, hub, "item1", tc.lastEventID)
+			if tc.wantResync {
+				f := next()
+				require.Equal(t, streamResync, f.event)
+				require.Empty(t, f.id)
+			}
+
+			// the stream is open once the headers arrive, so a live publish is delivered
+			hub.Publish(stream.Event{Type: stream.EventLeaderChanged, ItemID: "item1"})
+
+			var ids []string
+			for len(ids) < len(tc.wantIDs) {
+				f := next()
+				ids = append(ids, f.id)
+				require.Contains(t, f.data, `"item_id":"item1"`)
+			}
+			require.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
+
+// Test ItemEventsHandler rejects a malformed Last-Event-ID
+func TestItemEventsHandler_InvalidLastEventID(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items/:item_id/events", NewStreamHandler(stream.NewHub(stream.HubConfig{}), 0).ItemEventsHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/items/item1/events", nil)
+	req.Header.Set("Last-Event-ID", "not-a-number")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bidding-tracker/internal/stream"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultHeartbeat = 30 * time.Second
+	streamWriteWait  = 10 * time.Second
+	maxClientMessage = 512 // clients only send control frames
+)
+
+// Stream control message types sent alongside hub events
+const (
+	streamHeartbeat = "heartbeat"
+	streamResync    = "resync" // replay was incomplete; client should refetch item state
+)
+
+// streamControl is a non-event message on the stream
+type streamControl struct {
+	Type string    `json:"type"`
+	Seq  uint64    `json:"seq,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// StreamHandler serves the real-time bid stream over WebSocket
+type StreamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+// NewStreamHandler creates a StreamHandler; a zero heartbeat uses the default interval
+func NewStreamHandler(hub *stream.Hub, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	return &StreamHandler{
+		hub:       hub,
+		heartbeat: heartbeat,
+		upgrader:  websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024},
+	}
+}
+
+// StreamHandler handles GET /stream?items=item1,item2&since=<seq>
+func (h *StreamHandler) StreamHandler(c *gin.Context) {
+	itemIDs := parseItemIDs(c.Query("items"))
+	if len(itemIDs) == 0 {
+		utils.JSONError(c, http.StatusBadRequest, errors.New("items query parameter is required"), "invalid stream request")
+		return
+	}
+
+	var since uint64
+	if s := c.Query("since"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, err, "invalid stream request")
+			return
+		}
+		since = v
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader has already written an error response
+		utils.WarnContext(c.Request.Context(), "StreamHandler: websocket upgrade failed", map[string]any{"error": err.Error()})
+		return
+	}
+	defer conn.Close()
+
+	sub, truncated := h.hub.Subscribe(itemIDs, since)
+	defer sub.Close()
+
+	utils.InfoContext(c.Request.Context(), "StreamHandler: subscriber connected", map[string]any{"items": itemIDs, "since": since})
+
+	if truncated {
+		if err := h.write(conn, streamControl{Type: streamResync, Seq: h.hub.LastSeq(), Time: time.Now().UTC()}); err != nil {
+			return
+		}
+	}
+
+	readerDone := h.readPump(conn)
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				switch err := sub.Err(); {
+				case errors.Is(err, stream.ErrSlowConsumer):
+					utils.WarnContext(c.Request.Context(), "StreamHandler: dropping slow subscriber", map[string]any{"items": itemIDs})
+					h.closeWith(conn, websocket.ClosePolicyViolation, "slow consumer")
+				case errors.Is(err, stream.ErrHubClosed):
+					// the client reconnects with since=<last seq> to another instance
+					h.closeWith(conn, websocket.CloseGoingAway, "server shutting down")
+				}
+				return
+			}
+			if err := h.write(conn, ev); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			// browsers do not surface pings, so heartbeats are also sent as messages
+			if err := h.write(conn, streamControl{Type: streamHeartbeat, Time: time.Now().UTC()}); err != nil {
+				return
+			}
+		case <-readerDone:
+			return
+		}
+	}
+}
+
+// readPump consumes client frames so pongs and close frames are processed.
+// The returned channel is closed once the connection stops responding or closes.
+func (h *StreamHandler) readPump(conn *websocket.Conn) <-chan struct{} {
+	done := make(chan struct{})
+	pongWait := 2 * h.heartbeat
+
+	conn.SetReadLimit(maxClientMessage)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// write sends one JSON message with a write deadline
+func (h *StreamHandler) write(conn *websocket.Conn, v any) error {
+	conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	return conn.WriteJSON(v)
+}
+
+// closeWith sends a close frame with the given code and reason
+func (h *StreamHandler) closeWith(conn *websocket.Conn, code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(streamWriteWait))
+}
+
+// parseItemIDs splits a comma-separated list, ignoring empty entries
+func parseItemIDs(raw string) []string {
+	var ids []string
+	for _, id := range strings.Split(raw, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
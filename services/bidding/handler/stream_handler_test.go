@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamServer starts an HTTP server exposing the stream handler for a hub
+func newStreamServer(t *testing.T, hub *stream.Hub, heartbeat time.Duration) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream", NewStreamHandler(hub, heartbeat).StreamHandler)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// dialStream opens a WebSocket connection to the stream endpoint with a query string
+func dialStream(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream?" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readMessage reads one JSON message into a generic map
+func readMessage(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg map[string]any
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+// Test StreamHandler request validation
+func TestStreamHandler_BadRequest(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	srv := newStreamServer(t, stream.NewHub(stream.HubConfig{}), 0)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "missing_items", query: ""},
+		{name: "blank_items", query: "items=,"},
+		{name: "invalid_since", query: "items=item1&since=abc"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			resp, err := http.Get(srv.URL + "/stream?" + tc.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+// Test live delivery, item filtering and heartbeats
+func TestStreamHandler_LiveEvents(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := stream.NewHub(stream.HubConfig{})
+	srv := newStreamServer(t, hub, 200*time.Millisecond)
+	conn := dialStream(t, srv, "items=item1,item2")
+
+	// wait for the heartbeat so the subscription is known to be registered
+	require.Equal(t, streamHeartbeat, readMessage(t, conn)["type"])
+
+	bid := model.Bid{BidID: "bid1", ItemID: "item1", UserID: "user1", Amount: 100}
+	hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item3"}) // not subscribed
+	hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item1", Bid: &bid})
+
+	msg := readMessage(t, conn)
+	for msg["type"] == streamHeartbeat {
+		msg = readMessage(t, conn)
+	}
+	require.Equal(t, stream.EventBidPlaced, msg["type"])
+	require.Equal(t, "item1", msg["item_id"])
+	require.Equal(t, float64(2), msg["seq"])
+	require.Equal(t, "bid1", msg["bid"].(map[string]any)["bid_id"])
+}
+
+// Test resuming from a sequence number
+func TestStreamHandler_Resume(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name       string
+		query      string
+		wantResync bool
+		wantSeqs   []float64
+	}{
+		{name: "resume_within_buffer", query: "items=item1&since=2", wantSeqs: []float64{3, 4}},
+		{name: "no_resume_live_only", query: "items=item1&since=0", wantSeqs: nil},
+		{name: "resume_evicted_sends_resync", query: "items=item1&since=1", wantResync: true, wantSeqs: []float64{3, 4}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			hub := stream.NewHub(stream.HubConfig{ReplaySize: 2})
+			for i := 0; i < 4; i++ {
+				hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item1"})
+			}
+
+			srv := newStreamServer(t, hub, 200*time.Millisecond)
+			conn := dialStream(t, srv, tc.query)
+
+			if tc.wantResync {
+				msg := readMessage(t, conn)
+				require.Equal(t, streamResync, msg["type"])
+				require.Equal(t, float64(4), msg["seq"])
+			}
+
+			var got []float64
+			for {
+				msg := readMessage(t, conn)
+				if msg["type"] == streamHeartbeat {
+					break
+				}
+				got = append(got, msg["seq"].(float64))
+			}
+			require.Equal(t, tc.wantSeqs, got)
+		})
+	}
+}
+
+// Test that a subscriber whose queue overflows is disconnected
+func TestStreamHandler_SlowConsumerDropped(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := stream.NewHub(stream.HubConfig{QueueSize: 1})
+	srv := newStreamServer(t, hub, 100*time.Millisecond)
+	conn := dialStream(t, srv, "items=item1")
+	require.Equal(t, streamHeartbeat, readMessage(t, conn)["type"])
+
+	// publishing never blocks; a burst overflows the one-event queue
+	for i := 0; i < 10000; i++ {
+		hub.Publish(stream.Event{Type: stream.EventBidPlaced, ItemID: "item1"})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		require.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+		return
+	}
+}
+
+// Test that closing the hub on shutdown ends the stream with a going-away close frame
+func TestStreamHandler_HubClosed(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	hub := stream.NewHub(stream.HubConfig{})
+	srv := newStreamServer(t, hub, 100*time.Millisecond)
+	conn := dialStream(t, srv, "items=item1")
+	require.Equal(t, streamHeartbeat, readMessage(t, conn)["type"])
+
+	hub.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		require.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+		return
+	}
+}
@@ -1,16 +1,42 @@
 package helpers
 
+import (
+	"bidding-tracker/client"
+	model "bidding-tracker/internal/models"
+)
+
 // Request/Response DTOs
 type PlaceBidRequest struct {
 	ItemID string  `json:"item_id" binding:"required"`
-	UserID string  `json:"user_id" binding:"required"`
+	UserID string  `json:"user_id"` // optional when authenticated; must match the token's user
 	Amount float64 `json:"amount" binding:"required,gt=0"`
 }
 
 type BidResponse struct {
-	BidID     string  `json:"bid_id"`
-	ItemID    string  `json:"item_id"`
-	UserID    string  `json:"user_id"`
-	Amount    float64 `json:"amount"`
-	CreatedAt string  `json:"created_at"`
+	BidID     string          `json:"bid_id"`
+	ItemID    string          `json:"item_id"`
+	UserID    string          `json:"user_id"`
+	Amount    float64         `json:"amount"`
+	CreatedAt string          `json:"created_at"`
+	Seq       uint64          `json:"seq,omitempty"`
+	PrevHash  string          `json:"prev_hash,omitempty"`
+	Hash      string          `json:"hash,omitempty"`
+	ChainHead string          `json:"chain_head,omitempty"` // set by GET /items/:item_id/winning
+	Receipt   *client.Receipt `json:"receipt,omitempty"`    // set by POST /bids when receipts are enabled
+}
+
+// CloseAuctionResponse is the closed item and the bid that won it
+type CloseAuctionResponse struct {
+	Item   model.Item   `json:"item"`
+	Winner *BidResponse `json:"winner"` // null when the auction closed without bids
+}
+
+// ChainVerificationResponse reports the integrity of an item's bid chain
+type ChainVerificationResponse struct {
+	ItemID    string `json:"item_id"`
+	Valid     bool   `json:"valid"`
+	Bids      int    `json:"bids"`
+	Unchained int    `json:"unchained"` // bids recorded before chaining existed
+	Head      string `json:"head"`
+	Error     string `json:"error,omitempty"` // where and why the chain is broken
 }
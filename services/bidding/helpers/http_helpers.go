@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -15,7 +16,7 @@ import (
 func HandleBindError(c *gin.Context, handlerName string, err error) {
 	wrappedErr := fmt.Errorf("invalid request payload: %w", err)
 	utils.JSONError(c, http.StatusBadRequest, wrappedErr, "invalid request payload")
-	utils.Warn(handlerName+": binding error", map[string]any{"error": err.Error()})
+	utils.WarnContext(c.Request.Context(), handlerName+": binding error", map[string]any{"error": err.Error()})
 }
 
 // MapErrorToHTTP maps domain/service errors to HTTP status code and message
@@ -27,16 +28,29 @@ func MapErrorToHTTP(err error) (int, string) {
 		return http.StatusBadRequest, "invalid bid details"
 	case errors.Is(err, biddingerrors.ErrBidTooLow):
 		return http.StatusConflict, "bid amount too low"
+	case errors.Is(err, biddingerrors.ErrBidConflict):
+		return http.StatusConflict, "bid raced another bid, retry"
+	case errors.Is(err, biddingerrors.ErrAuctionClosed):
+		return http.StatusConflict, "auction is closed"
+	case errors.Is(err, biddingerrors.ErrChangesTrimmed):
+		return http.StatusGone, "changes no longer retained, resync"
+	case errors.Is(err, biddingerrors.ErrAuditUnavailable):
+		return http.StatusServiceUnavailable, "audit log unavailable"
 	case errors.Is(err, biddingerrors.ErrNoBids):
 		return http.StatusOK, "no bids found for item"
 	case errors.Is(err, biddingerrors.ErrUserNoBids):
 		return http.StatusOK, "no items found for user"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "request timed out"
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout, "request canceled"
 	default:
 		return http.StatusInternalServerError, "internal server error"
 	}
 }
 
-// LogSuccess is a small helper to standardize logging of successful operations
-func LogSuccess(handlerName, message string, ctx map[string]any) {
-	utils.Info(handlerName+": "+message, ctx)
+// LogSuccess is a small helper to standardize logging of successful operations. These
+// lines are high-volume, so they are subject to the logger's success sampling.
+func LogSuccess(c *gin.Context, handlerName, message string, fields map[string]any) {
+	utils.InfoSampledContext(c.Request.Context(), handlerName+": "+message, fields)
 }
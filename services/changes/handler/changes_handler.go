@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bidding-tracker/internal/repository"
+	"bidding-tracker/services/bidding/helpers"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+// ChangesHandler exposes the repository outbox as a change-data-capture feed
+type ChangesHandler struct {
+	feed repository.ChangeFeed
+}
+
+func NewChangesHandler(feed repository.ChangeFeed) *ChangesHandler {
+	return &ChangesHandler{feed: feed}
+}
+
+// changesPage is one page of the feed. NextAfter is the after value for the next request.
+type changesPage struct {
+	Changes   []repository.Change `json:"changes"`
+	NextAfter uint64              `json:"next_after"`
+}
+
+// GetChangesHandler handles GET /changes?after=offset&limit=n
+func (h *ChangesHandler) GetChangesHandler(c *gin.Context) {
+	after, limit, err := parseChangesQuery(c)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	changes, err := h.feed.GetChanges(c.Request.Context(), after, limit)
+	if err != nil {
+		status, message := helpers.MapErrorToHTTP(err)
+		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+		utils.ErrorContext(c.Request.Context(), "GetChangesHandler: failed to read changes", map[string]any{"after": after, "error": err.Error()})
+		return
+	}
+
+	page := changesPage{Changes: changes, NextAfter: after}
+	if page.Changes == nil {
+		page.Changes = []repository.Change{}
+	}
+	if n := len(changes); n > 0 {
+		page.NextAfter = changes[n-1].Offset
+	}
+	utils.JSONResponse(c, http.StatusOK, page, "changes retrieved successfully")
+}
+
+// parseChangesQuery reads the after offset (default 0) and page size (default 100, at most 1000)
+func parseChangesQuery(c *gin.Context) (uint64, int, error) {
+	var after uint64
+	if raw := c.Query("after"); raw != "" {
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("after must be a non-negative integer: %w", err)
+		}
+		after = v
+	}
+
+	limit := defaultChangesLimit
+	if raw := c.Query("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return 0, 0, errors.New("limit must be a positive integer")
+		}
+		limit = min(v, maxChangesLimit)
+	}
+	return after, limit, nil
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	model "bidding-tracker/internal/models"
+	"bidding-tracker/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test paging through the change feed
+func TestGetChangesHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo()
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1"}))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.RecordBidForItem(context.Background(), model.Bid{
+			BidID: fmt.Sprintf("bid%d", i), ItemID: "item1", UserID: "user1", Amount: float64(100 + i), CreatedAt: time.Now().UTC(),
+		}))
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		wantOffsets    []uint64
+		wantNextAfter  uint64
+	}{
+		{name: "from_start", query: "", expectedStatus: http.StatusOK, wantOffsets: []uint64{1, 2, 3, 4, 5}, wantNextAfter: 5},
+		{name: "after_and_limit", query: "?after=1&limit=2", expectedStatus: http.StatusOK, wantOffsets: []uint64{2, 3}, wantNextAfter: 3},
+		{name: "caught_up", query: "?after=5", expectedStatus: http.StatusOK, wantOffsets: []uint64{}, wantNextAfter: 5},
+		{name: "invalid_after", query: "?after=-1", expectedStatus: http.StatusBadRequest},
+		{name: "invalid_limit", query: "?limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/changes", NewChangesHandler(repo).GetChangesHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/changes"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data changesPage `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			got := []uint64{}
+			for _, c := range resp.Data.Changes {
+				got = append(got, c.Offset)
+			}
+			require.Equal(t, tc.wantOffsets, got)
+			require.Equal(t, tc.wantNextAfter, resp.Data.NextAfter)
+		})
+	}
+}
+
+// Test that a consumer asking for trimmed changes is told to resync
+func TestGetChangesHandler_Trimmed(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	repo := repository.NewMemoryRepo(repository.WithOutboxRetention(2))
+	require.NoError(t, repo.AddItem(model.Item{ItemID: "item1"}))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, repo.RecordBidForItem(context.Background(), model.Bid{
+			BidID: fmt.Sprintf("bid%d", i), ItemID: "item1", UserID: "user1", Amount: float64(100 + i), CreatedAt: time.Now().UTC(),
+		}))
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/changes", NewChangesHandler(repo).GetChangesHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/changes?after=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusGone, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/changes?after=2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
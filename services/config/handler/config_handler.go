@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"bidding-tracker/internal/config"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler shows the configuration the server is running with
+type ConfigHandler struct {
+	cfg *config.Config
+}
+
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{cfg: cfg}
+}
+
+// GetConfigHandler handles GET /debug/config: every setting with its effective value and
+// source. Secrets are masked.
+func (h *ConfigHandler) GetConfigHandler(c *gin.Context) {
+	utils.JSONResponse(c, http.StatusOK, h.cfg.Effective(), "configuration retrieved successfully")
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bidding-tracker/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the effective configuration is listed with sources and masked secrets
+func TestGetConfigHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	env := map[string]string{"PORT": "9090", "JWT_HMAC_SECRET": "s3cret"}
+	cfg, err := config.Load([]string{"-storage.backend=file"}, func(k string) string { return env[k] })
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/debug/config", NewConfigHandler(cfg).GetConfigHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "s3cret")
+
+	var resp struct {
+		Data []config.Setting `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	byKey := make(map[string]config.Setting, len(resp.Data))
+	for _, s := range resp.Data {
+		byKey[s.Key] = s
+	}
+	require.Equal(t, config.Setting{Key: "server.port", Env: "PORT", Value: "9090", Source: config.SourceEnv}, byKey["server.port"])
+	require.Equal(t, config.Setting{Key: "storage.backend", Env: "STORAGE_BACKEND", Value: "file", Source: config.SourceFlag}, byKey["storage.backend"])
+	require.Equal(t, "********", byKey["auth.jwt_hmac_secret"].Value)
+	require.Equal(t, "", byKey["auth.admin_api_key"].Value, "unset secrets show as empty")
+	require.Equal(t, config.SourceDefault, byKey["logging.level"].Source)
+}
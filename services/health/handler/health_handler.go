@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"bidding-tracker/internal/health"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	checker *health.Checker
+	started time.Time
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker, started: time.Now()}
+}
+
+// liveness is the body of GET /healthz
+type liveness struct {
+	Status string `json:"status"`
+	Uptime string `json:"uptime"`
+}
+
+// LivenessHandler handles GET /healthz. It only reports that the process is serving
+// requests; dependencies are checked by /readyz so a failing database does not get
+// the server restarted.
+func (h *HealthHandler) LivenessHandler(c *gin.Context) {
+	utils.JSONResponse(c, http.StatusOK, liveness{Status: health.StatusOK, Uptime: time.Since(h.started).Round(time.Second).String()}, "alive")
+}
+
+// ReadinessHandler handles GET /readyz: 200 when every check passes, otherwise 503.
+// The body lists each check with its status, error and duration.
+func (h *HealthHandler) ReadinessHandler(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context())
+	if !report.Ready() {
+		utils.JSONResponse(c, http.StatusServiceUnavailable, report, "not ready")
+		utils.WarnContext(c.Request.Context(), "ReadinessHandler: not ready", map[string]any{"checks": failing(report)})
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, report, "ready")
+}
+
+// failing returns the failing checks' errors by name, for logging
+func failing(report health.Report) map[string]string {
+	errs := make(map[string]string)
+	for _, r := range report.Checks {
+		if r.Status != health.StatusOK {
+			errs[r.Name] = r.Error
+		}
+	}
+	return errs
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bidding-tracker/internal/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test the liveness and readiness probes
+func TestHealthHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name           string
+		path           string
+		storageErr     error
+		expectedStatus int
+		expectedMsg    string
+		wantStatus     string
+	}{
+		{name: "live", path: "/healthz", expectedStatus: http.StatusOK, expectedMsg: "alive", wantStatus: health.StatusOK},
+		{name: "live_while_storage_down", path: "/healthz", storageErr: errors.New("disk gone"), expectedStatus: http.StatusOK, expectedMsg: "alive", wantStatus: health.StatusOK},
+		{name: "ready", path: "/readyz", expectedStatus: http.StatusOK, expectedMsg: "ready", wantStatus: health.StatusOK},
+		{name: "not_ready", path: "/readyz", storageErr: errors.New("disk gone"), expectedStatus: http.StatusServiceUnavailable, expectedMsg: "not ready", wantStatus: health.StatusFailing},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			checker := health.NewChecker(0)
+			checker.Register("storage", func(context.Context) error { return tc.storageErr })
+			h := NewHealthHandler(checker)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/healthz", h.LivenessHandler)
+			router.GET("/readyz", h.ReadinessHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, tc.expectedStatus, w.Code)
+
+			var resp struct {
+				Message string `json:"message"`
+				Data    struct {
+					Status string          `json:"status"`
+					Checks []health.Result `json:"checks"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedMsg, resp.Message)
+			require.Equal(t, tc.wantStatus, resp.Data.Status)
+			if tc.path == "/readyz" {
+				require.Len(t, resp.Data.Checks, 1)
+				require.Equal(t, "storage", resp.Data.Checks[0].Name)
+			}
+		})
+	}
+}
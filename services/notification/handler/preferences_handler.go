@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/internal/notification"
+	"bidding-tracker/services/bidding/helpers"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferencesHandler exposes users' notification preferences
+type PreferencesHandler struct {
+	store notification.PreferenceStore
+}
+
+func NewPreferencesHandler(store notification.PreferenceStore) *PreferencesHandler {
+	return &PreferencesHandler{store: store}
+}
+
+var (
+	// errNotOwner is reported when a user addresses another user's preferences
+	errNotOwner = errors.New("preferences of another user are not accessible")
+	// errNoPrincipal is reported when a request reaches the handlers unauthenticated
+	errNoPrincipal = errors.New("preferences require an authenticated user")
+)
+
+// authorizeOwner rejects a caller who is neither the user nor holds override. Preferences
+// hold contact details, so requests without a principal are rejected too.
+func authorizeOwner(c *gin.Context, userID string, override auth.Permission) bool {
+	principal, ok := auth.PrincipalFrom(c.Request.Context())
+	if !ok {
+		utils.JSONErrorCode(c, http.StatusUnauthorized, auth.CodeUnauthenticated, errNoPrincipal, "authentication required")
+		return false
+	}
+	if principal.CanAccessOwned(userID, override) {
+		return true
+	}
+	utils.JSONErrorCode(c, http.StatusForbidden, auth.CodeNotOwner, errNotOwner, "cannot access another user's preferences")
+	return false
+}
+
+// GetPreferencesHandler handles GET /users/:user_id/notification-preferences
+func (h *PreferencesHandler) GetPreferencesHandler(c *gin.Context) {
+	userID := c.Param("user_id")
+	if !authorizeOwner(c, userID, auth.PermUsersRead) {
+		return
+	}
+	prefs, err := h.store.Get(c.Request.Context(), userID)
+	if err != nil {
+		status, message := helpers.MapErrorToHTTP(err)
+		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+		utils.ErrorContext(c.Request.Context(), "GetPreferencesHandler: failed to load preferences", map[string]any{"user_id": userID, "error": err.Error()})
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, prefs, "preferences retrieved successfully")
+}
+
+// PutPreferencesHandler handles PUT /users/:user_id/notification-preferences
+func (h *PreferencesHandler) PutPreferencesHandler(c *gin.Context) {
+	userID := c.Param("user_id")
+	if !authorizeOwner(c, userID, auth.PermAdmin) {
+		return
+	}
+
+	var prefs notification.Preferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		helpers.HandleBindError(c, "PutPreferencesHandler", err)
+		return
+	}
+	prefs.UserID = userID // the path is authoritative
+
+	before, err := h.store.Get(c.Request.Context(), userID)
+	if err != nil {
+		status, message := helpers.MapErrorToHTTP(err)
+		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+		utils.ErrorContext(c.Request.Context(), "PutPreferencesHandler: failed to load preferences", map[string]any{"user_id": userID, "error": err.Error()})
+		return
+	}
+	if err := audit.Ready(c.Request.Context()); err != nil {
+		utils.JSONError(c, http.StatusServiceUnavailable, err, "audit log unavailable")
+		return
+	}
+	if err := h.store.Set(c.Request.Context(), prefs); err != nil {
+		if errors.Is(err, notification.ErrInvalidPreferences) {
+			utils.JSONError(c, http.StatusBadRequest, err, "invalid notification preferences")
+			return
+		}
+		status, message := helpers.MapErrorToHTTP(err)
+		utils.JSONError(c, status, fmt.Errorf("%s: %w", message, err), message)
+		utils.ErrorContext(c.Request.Context(), "PutPreferencesHandler: failed to store preferences", map[string]any{"user_id": userID, "error": err.Error()})
+		return
+	}
+
+	audit.Record(c.Request.Context(), audit.Change{Action: audit.ActionPreferencesUpdated, UserID: userID, Before: before, After: prefs})
+	utils.JSONResponse(c, http.StatusOK, prefs, "preferences updated successfully")
+	helpers.LogSuccess(c, "PutPreferencesHandler", "preferences updated successfully", map[string]any{"user_id": userID})
+}
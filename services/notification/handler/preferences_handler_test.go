@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bidding-tracker/internal/auth"
+	"bidding-tracker/internal/notification"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// withPrincipal authenticates every request as userID
+func withPrincipal(userID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{UserID: userID}))
+	}
+}
+
+// Test the notification preferences endpoints
+func TestPreferencesHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedMsg    string
+		wantStored     notification.Preferences
+	}{
+		{
+			name:           "store_preferences",
+			body:           `{"user_id":"someone_else","email":"user1@example.com","disabled":["auction_lost"],"quiet_hours":{"start":"22:00","end":"07:00","timezone":"UTC"}}`,
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "preferences updated successfully",
+			wantStored: notification.Preferences{
+				UserID:     "user1",
+				Email:      "user1@example.com",
+				Disabled:   []notification.Kind{notification.KindAuctionLost},
+				QuietHours: &notification.QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"},
+			},
+		},
+		{
+			name:           "invalid_quiet_hours",
+			body:           `{"quiet_hours":{"start":"late","end":"07:00"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid notification preferences",
+			wantStored:     notification.Preferences{UserID: "user1"},
+		},
+		{
+			name:           "invalid_json",
+			body:           `{invalid json}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid request payload",
+			wantStored:     notification.Preferences{UserID: "user1"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal("user1"))
+			h := NewPreferencesHandler(notification.NewMemoryPreferences())
+			router.GET("/users/:user_id/notification-preferences", h.GetPreferencesHandler)
+			router.PUT("/users/:user_id/notification-preferences", h.PutPreferencesHandler)
+
+			req := httptest.NewRequest(http.MethodPut, "/users/user1/notification-preferences", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedMsg, resp["message"])
+
+			req = httptest.NewRequest(http.MethodGet, "/users/user1/notification-preferences", nil)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var got struct {
+				Data notification.Preferences `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			require.Equal(t, tc.wantStored, got.Data)
+		})
+	}
+}
+
+// Test that requests without a principal neither read nor change preferences
+func TestPreferencesHandler_RequiresPrincipal(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+	gin.SetMode(gin.TestMode)
+
+	store := notification.NewMemoryPreferences()
+	router := gin.New()
+	h := NewPreferencesHandler(store)
+	router.GET("/users/:user_id/notification-preferences", h.GetPreferencesHandler)
+	router.PUT("/users/:user_id/notification-preferences", h.PutPreferencesHandler)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		req := httptest.NewRequest(method, "/users/user1/notification-preferences", bytes.NewBufferString(`{"email":"attacker@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, http.StatusUnauthorized, w.Code, method)
+		require.Equal(t, auth.CodeUnauthenticated, resp["code"], method)
+	}
+
+	prefs, err := store.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	require.Empty(t, prefs.Email)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bidding-tracker/internal/audit"
+	"bidding-tracker/internal/webhook"
+	"bidding-tracker/services/bidding/helpers"
+	"bidding-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler exposes webhook subscription management
+type WebhookHandler struct {
+	service *webhook.Service
+}
+
+func NewWebhookHandler(service *webhook.Service) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// subscribeRequest is the body of POST /webhooks
+type subscribeRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+// CreateWebhookHandler handles POST /webhooks. The response is the only place the secret is returned.
+func (h *WebhookHandler) CreateWebhookHandler(c *gin.Context) {
+	var req subscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helpers.HandleBindError(c, "CreateWebhookHandler", err)
+		return
+	}
+
+	if err := audit.Ready(c.Request.Context()); err != nil {
+		utils.JSONError(c, http.StatusServiceUnavailable, err, "audit log unavailable")
+		return
+	}
+	sub, err := h.service.Subscribe(req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidSubscription) {
+			utils.JSONError(c, http.StatusBadRequest, err, "invalid webhook subscription")
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err, "failed to create webhook")
+		return
+	}
+
+	audit.Record(c.Request.Context(), audit.Change{Action: audit.ActionWebhookCreated, Resource: sub.ID, After: redacted(sub)})
+	utils.JSONResponse(c, http.StatusCreated, sub, "webhook created successfully")
+	helpers.LogSuccess(c, "CreateWebhookHandler", "webhook created successfully", map[string]any{"subscription_id": sub.ID})
+}
+
+// ListWebhooksHandler handles GET /webhooks
+func (h *WebhookHandler) ListWebhooksHandler(c *gin.Context) {
+	utils.JSONResponse(c, http.StatusOK, h.service.List(), "webhooks retrieved successfully")
+}
+
+// GetWebhookHandler handles GET /webhooks/:id
+func (h *WebhookHandler) GetWebhookHandler(c *gin.Context) {
+	sub, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, sub, "webhook retrieved successfully")
+}
+
+// DeleteWebhookHandler handles DELETE /webhooks/:id
+func (h *WebhookHandler) DeleteWebhookHandler(c *gin.Context) {
+	id := c.Param("id")
+	before, err := h.service.Get(id)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	if err := audit.Ready(c.Request.Context()); err != nil {
+		utils.JSONError(c, http.StatusServiceUnavailable, err, "audit log unavailable")
+		return
+	}
+	if err := h.service.Delete(id); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	audit.Record(c.Request.Context(), audit.Change{Action: audit.ActionWebhookDeleted, Resource: id, Before: redacted(before)})
+	utils.JSONResponse(c, http.StatusOK, nil, "webhook deleted successfully")
+	helpers.LogSuccess(c, "DeleteWebhookHandler", "webhook deleted successfully", map[string]any{"subscription_id": id})
+}
+
+// GetDeliveriesHandler handles GET /webhooks/:id/deliveries
+func (h *WebhookHandler) GetDeliveriesHandler(c *gin.Context) {
+	attempts, err := h.service.Attempts(c.Param("id"))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, attempts, "deliveries retrieved successfully")
+}
+
+// GetDeadLettersHandler handles GET /webhooks/:id/dead-letters
+func (h *WebhookHandler) GetDeadLettersHandler(c *gin.Context) {
+	dead, err := h.service.DeadLetters(c.Param("id"))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONResponse(c, http.StatusOK, dead, "dead letters retrieved successfully")
+}
+
+// redacted hides a subscription's signing secret from the audit log
+func redacted(sub webhook.Subscription) webhook.Subscription {
+	sub.Secret = ""
+	return sub
+}
+
+// writeWebhookError maps webhook service errors to HTTP responses
+func writeWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		utils.JSONError(c, http.StatusNotFound, err, "webhook not found")
+		return
+	}
+	utils.JSONError(c, http.StatusInternalServerError, err, "internal server error")
+}
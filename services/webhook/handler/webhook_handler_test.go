@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bidding-tracker/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(svc *webhook.Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewWebhookHandler(svc)
+	router.POST("/webhooks", h.CreateWebhookHandler)
+	router.GET("/webhooks", h.ListWebhooksHandler)
+	router.GET("/webhooks/:id", h.GetWebhookHandler)
+	router.DELETE("/webhooks/:id", h.DeleteWebhookHandler)
+	router.GET("/webhooks/:id/deliveries", h.GetDeliveriesHandler)
+	router.GET("/webhooks/:id/dead-letters", h.GetDeadLettersHandler)
+	return router
+}
+
+// Test webhook creation validation
+func TestCreateWebhookHandler(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{
+			name:           "created",
+			body:           `{"url":"https://partner.example.com/hooks","event_types":["leader_changed"],"secret":"s3cret"}`,
+			expectedStatus: http.StatusCreated,
+			expectedMsg:    "webhook created successfully",
+		},
+		{
+			name:           "unknown_event_type",
+			body:           `{"url":"https://partner.example.com/hooks","event_types":["nope"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid webhook subscription",
+		},
+		{
+			name:           "missing_url",
+			body:           `{"event_types":["leader_changed"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "invalid request payload",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			svc := webhook.NewService(webhook.Config{})
+			defer svc.Close()
+			router := newTestRouter(svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedMsg, resp["message"])
+		})
+	}
+}
+
+// Test the subscription lifecycle through the API
+func TestWebhookLifecycle(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	svc := webhook.NewService(webhook.Config{})
+	defer svc.Close()
+	router := newTestRouter(svc)
+
+	do := func(method, path, body string) (int, json.RawMessage) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp.Data
+	}
+
+	status, data := do(http.MethodPost, "/webhooks", `{"url":"https://partner.example.com/hooks"}`)
+	require.Equal(t, http.StatusCreated, status)
+	var created webhook.Subscription
+	require.NoError(t, json.Unmarshal(data, &created))
+	require.NotEmpty(t, created.Secret)
+
+	status, data = do(http.MethodGet, "/webhooks", "")
+	require.Equal(t, http.StatusOK, status)
+	var list []webhook.Subscription
+	require.NoError(t, json.Unmarshal(data, &list))
+	require.Len(t, list, 1)
+	require.Empty(t, list[0].Secret)
+
+	status, _ = do(http.MethodGet, "/webhooks/"+created.ID+"/deliveries", "")
+	require.Equal(t, http.StatusOK, status)
+	status, _ = do(http.MethodGet, "/webhooks/"+created.ID+"/dead-letters", "")
+	require.Equal(t, http.StatusOK, status)
+
+	status, _ = do(http.MethodDelete, "/webhooks/"+created.ID, "")
+	require.Equal(t, http.StatusOK, status)
+	status, _ = do(http.MethodGet, "/webhooks/"+created.ID, "")
+	require.Equal(t, http.StatusNotFound, status)
+	status, _ = do(http.MethodDelete, "/webhooks/"+created.ID, "")
+	require.Equal(t, http.StatusNotFound, status)
+}
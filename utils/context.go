@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	contextFieldsMu sync.RWMutex
+	contextFields   []func(ctx context.Context) map[string]any
+)
+
+// RegisterContextFields adds a source of fields, such as request and trace IDs, that
+// the *Context logging functions read from the context of every log line
+func RegisterContextFields(fn func(ctx context.Context) map[string]any) {
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+	contextFields = append(contextFields, fn)
+}
+
+type loggerKey struct{}
+
+// WithLogger stores a per-request logger, e.g. one carrying the caller, that the
+// *Context functions log through
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFrom returns the logger stored in ctx, or the default logger
+func LoggerFrom(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+	return DefaultLogger()
+}
+
+// withContext returns the context's logger with the registered context fields added;
+// fields passed to the log call still win
+func withContext(ctx context.Context) *Logger {
+	contextFieldsMu.RLock()
+	sources := contextFields
+	contextFieldsMu.RUnlock()
+
+	merged := make(map[string]any, 3)
+	for _, fn := range sources {
+		for k, v := range fn(ctx) {
+			merged[k] = v
+		}
+	}
+	return LoggerFrom(ctx).With(merged)
+}
+
+// DebugContext logs at debug level with the fields carried by ctx
+func DebugContext(ctx context.Context, message string, fields map[string]any) {
+	withContext(ctx).Debug(message, fields)
+}
+
+// InfoContext logs at info level with the fields carried by ctx
+func InfoContext(ctx context.Context, message string, fields map[string]any) {
+	withContext(ctx).Info(message, fields)
+}
+
+// InfoSampledContext logs a high-volume success line at info level with the fields
+// carried by ctx, subject to the logger's success sampling
+func InfoSampledContext(ctx context.Context, message string, fields map[string]any) {
+	withContext(ctx).InfoSampled(message, fields)
+}
+
+// WarnContext logs at warning level with the fields carried by ctx
+func WarnContext(ctx context.Context, message string, fields map[string]any) {
+	withContext(ctx).Warn(message, fields)
+}
+
+// ErrorContext logs at error level with the fields carried by ctx
+func ErrorContext(ctx context.Context, message string, fields map[string]any) {
+	withContext(ctx).Error(message, fields)
+}
@@ -1,41 +1,242 @@
 package utils
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// init initializes the global logger configuration when the package is imported.
+// Log formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// timestampFormat is ISO 8601
+const timestampFormat = "2006-01-02T15:04:05Z07:00"
+
+// redactedValue replaces the value of a redacted field
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedFields are always masked, whatever LogConfig.Redact says
+var DefaultRedactedFields = []string{"api_key", "authorization", "password", "secret", "token"}
+
+// LogConfig configures a Logger. The zero value logs JSON at info level to stdout.
+type LogConfig struct {
+	Level  string    // debug, info, warn or error; default info
+	Format string    // FormatJSON or FormatText; default JSON
+	Output io.Writer // default os.Stdout
+	// SuccessSampling keeps one in every N lines per message logged through the
+	// *Sampled functions; 0 or 1 keeps them all
+	SuccessSampling int
+	// Redact lists more field names, matched case-insensitively, whose values are masked
+	Redact []string
+}
+
+// Logger writes structured log lines. Loggers derived with With share their output,
+// level, redaction and sampling.
+type Logger struct {
+	entry   *log.Entry
+	sampler *sampler
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+// init installs the default logger: JSON to stdout at info level
 func init() {
-	//set log formatter to JSON with ISO 8601 timestamps
-	log.SetFormatter(&log.JSONFormatter{
-		TimestampFormat: "2006-01-02T15:04:05Z07:00",
-	})
+	l, _ := NewLogger(LogConfig{}) // the zero config is valid
+	defaultLogger.Store(l)
+}
+
+// NewLogger creates a logger from cfg
+func NewLogger(cfg LogConfig) (*Logger, error) {
+	level := log.InfoLevel
+	if cfg.Level != "" {
+		var err error
+		if level, err = log.ParseLevel(cfg.Level); err != nil {
+			return nil, fmt.Errorf("log level: %w", err)
+		}
+	}
+
+	var formatter log.Formatter
+	switch cfg.Format {
+	case "", FormatJSON:
+		formatter = &log.JSONFormatter{TimestampFormat: timestampFormat}
+	case FormatText:
+		formatter = &log.TextFormatter{FullTimestamp: true, TimestampFormat: timestampFormat, DisableColors: true}
+	default:
+		return nil, fmt.Errorf("log format %q: want %s or %s", cfg.Format, FormatJSON, FormatText)
+	}
 
-	// Output to stdout
-	log.SetOutput(os.Stdout)
+	redact := make(map[string]bool, len(DefaultRedactedFields)+len(cfg.Redact))
+	for _, name := range append(append([]string(nil), DefaultRedactedFields...), cfg.Redact...) {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			redact[name] = true
+		}
+	}
 
-	// Set default log level
-	log.SetLevel(log.InfoLevel)
+	out := cfg.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	lg := log.New()
+	lg.SetOutput(out)
+	lg.SetLevel(level)
+	lg.SetFormatter(&redactingFormatter{inner: formatter, fields: redact})
+	return &Logger{entry: log.NewEntry(lg), sampler: newSampler(cfg.SuccessSampling)}, nil
+}
+
+// Configure replaces the default logger used by Info, Warn, Error and the *Context
+// functions. Loggers already stored in a context keep their configuration.
+func Configure(cfg LogConfig) error {
+	l, err := NewLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defaultLogger.Store(l)
+	return nil
+}
+
+// DefaultLogger returns the logger installed by Configure
+func DefaultLogger() *Logger {
+	return defaultLogger.Load()
+}
+
+// With returns a logger that adds fields to every line
+func (l *Logger) With(fields map[string]any) *Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return &Logger{entry: l.entry.WithFields(fields), sampler: l.sampler}
+}
+
+// Debug logs a message at debug level with optional fields
+func (l *Logger) Debug(message string, fields map[string]any) {
+	l.entry.WithFields(fields).Debug(message)
+}
+
+// Info logs a message at info level with optional fields
+func (l *Logger) Info(message string, fields map[string]any) {
+	l.entry.WithFields(fields).Info(message)
+}
+
+// InfoSampled logs at info level, keeping one in every SuccessSampling lines per
+// message. Kept lines carry the rate in a sample_rate field.
+func (l *Logger) InfoSampled(message string, fields map[string]any) {
+	keep, rate := l.sampler.keep(message)
+	if !keep {
+		return
+	}
+	if rate > 1 {
+		fields = withField(fields, "sample_rate", rate)
+	}
+	l.Info(message, fields)
+}
+
+// Warn logs a message at warning level with optional fields
+func (l *Logger) Warn(message string, fields map[string]any) {
+	l.entry.WithFields(fields).Warn(message)
+}
+
+// Error logs a message at error level with optional fields
+func (l *Logger) Error(message string, fields map[string]any) {
+	l.entry.WithFields(fields).Error(message)
+}
+
+// Fatal logs a message at fatal level and exits the application
+func (l *Logger) Fatal(message string, fields map[string]any) {
+	l.entry.WithFields(fields).Fatal(message)
+}
+
+// Debug logs a message at debug level with optional fields
+func Debug(message string, fields map[string]any) {
+	DefaultLogger().Debug(message, fields)
 }
 
 // Info logs a message at info level with optional fields
 func Info(message string, fields map[string]any) {
-	log.WithFields(fields).Info(message)
+	DefaultLogger().Info(message, fields)
 }
 
 // Warn logs a message at warning level with optional fields
 func Warn(message string, fields map[string]any) {
-	log.WithFields(fields).Warn(message)
+	DefaultLogger().Warn(message, fields)
 }
 
 // Error logs a message at error level with optional fields
 func Error(message string, fields map[string]any) {
-	log.WithFields(fields).Error(message)
+	DefaultLogger().Error(message, fields)
 }
 
 // Fatal logs a message at fatal level and exits the application
 func Fatal(message string, fields map[string]any) {
-	log.WithFields(fields).Fatal(message)
+	DefaultLogger().Fatal(message, fields)
+}
+
+// withField returns a copy of fields with key set, leaving the caller's map untouched
+func withField(fields map[string]any, key string, value any) map[string]any {
+	out := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// redactingFormatter masks sensitive fields before handing the entry to the real formatter
+type redactingFormatter struct {
+	inner  log.Formatter
+	fields map[string]bool // lowercase names
+}
+
+// Format implements logrus.Formatter
+func (f *redactingFormatter) Format(e *log.Entry) ([]byte, error) {
+	sensitive := false
+	for k := range e.Data {
+		if f.fields[strings.ToLower(k)] {
+			sensitive = true
+			break
+		}
+	}
+	if !sensitive {
+		return f.inner.Format(e)
+	}
+
+	masked := *e // the entry is shared with the caller, so mask a copy
+	masked.Data = make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		if f.fields[strings.ToLower(k)] {
+			v = redactedValue
+		}
+		masked.Data[k] = v
+	}
+	return f.inner.Format(&masked)
+}
+
+// sampler keeps the first of every n lines per message
+type sampler struct {
+	n      uint64
+	counts sync.Map // message -> *atomic.Uint64
+}
+
+func newSampler(n int) *sampler {
+	if n < 1 {
+		n = 1
+	}
+	return &sampler{n: uint64(n)}
+}
+
+// keep reports whether this line of message is logged, and the sampling rate
+func (s *sampler) keep(message string) (bool, uint64) {
+	if s.n == 1 {
+		return true, 1
+	}
+	c, _ := s.counts.LoadOrStore(message, new(atomic.Uint64))
+	return (c.(*atomic.Uint64).Add(1)-1)%s.n == 0, s.n
 }
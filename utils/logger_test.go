@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// lines decodes the JSON log lines written to buf
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		out = append(out, m)
+	}
+	return out
+}
+
+// Test level, format and configuration validation
+func TestNewLogger(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	tests := []struct {
+		name    string
+		cfg     LogConfig
+		log     func(l *Logger)
+		want    string // substring of the output; empty for no output
+		wantErr bool
+	}{
+		{name: "default_json_info", log: func(l *Logger) { l.Info("hello", map[string]any{"k": "v"}) }, want: `"k":"v"`},
+		{name: "debug_filtered_at_info", log: func(l *Logger) { l.Debug("hidden", nil) }},
+		{name: "debug_level", cfg: LogConfig{Level: "debug"}, log: func(l *Logger) { l.Debug("shown", nil) }, want: `"msg":"shown"`},
+		{name: "warn_level_drops_info", cfg: LogConfig{Level: "warn"}, log: func(l *Logger) { l.Info("hidden", nil) }},
+		{name: "text_format", cfg: LogConfig{Format: FormatText}, log: func(l *Logger) { l.Warn("careful", map[string]any{"k": "v"}) }, want: `level=warning msg=careful k=v`},
+		{name: "bad_level", cfg: LogConfig{Level: "loud"}, wantErr: true},
+		{name: "bad_format", cfg: LogConfig{Format: "xml"}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run table test cases in parallel
+
+			var buf bytes.Buffer
+			tc.cfg.Output = &buf
+			l, err := NewLogger(tc.cfg)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			tc.log(l)
+			if tc.want == "" {
+				require.Empty(t, buf.String())
+				return
+			}
+			require.Contains(t, buf.String(), tc.want)
+		})
+	}
+}
+
+// Test that sensitive fields are masked without touching the caller's map
+func TestLogger_Redaction(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	var buf bytes.Buffer
+	l, err := NewLogger(LogConfig{Output: &buf, Redact: []string{"Email"}})
+	require.NoError(t, err)
+
+	fields := map[string]any{"Authorization": "Bearer abc", "email": "u1@example.com", "user_id": "user1"}
+	l.With(map[string]any{"token": "t0k3n"}).Info("login", fields)
+
+	got := lines(t, &buf)
+	require.Len(t, got, 1)
+	require.Equal(t, redactedValue, got[0]["Authorization"])
+	require.Equal(t, redactedValue, got[0]["email"])
+	require.Equal(t, redactedValue, got[0]["token"])
+	require.Equal(t, "user1", got[0]["user_id"])
+	require.Equal(t, "Bearer abc", fields["Authorization"])
+}
+
+// Test that sampled lines keep one in N per message and report the rate
+func TestLogger_InfoSampled(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	var buf bytes.Buffer
+	l, err := NewLogger(LogConfig{Output: &buf, SuccessSampling: 3})
+	require.NoError(t, err)
+
+	for i := 0; i < 7; i++ {
+		l.InfoSampled("bid placed", nil)
+	}
+	l.InfoSampled("bids listed", nil)
+	l.Info("not sampled", nil)
+
+	got := lines(t, &buf)
+	require.Len(t, got, 5, "lines 1, 4 and 7 of the first message, the first of the second, and the unsampled line")
+	require.Equal(t, 3.0, got[0]["sample_rate"])
+	require.NotContains(t, got[4], "sample_rate")
+
+	buf.Reset()
+	l, err = NewLogger(LogConfig{Output: &buf})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		l.InfoSampled("bid placed", nil)
+	}
+	got = lines(t, &buf)
+	require.Len(t, got, 3)
+	require.NotContains(t, got[0], "sample_rate")
+}
+
+// Test that the *Context functions log through the logger carried by the context
+func TestLoggerFrom(t *testing.T) {
+	t.Parallel() // Allow running in parallel with other test functions
+
+	require.Same(t, DefaultLogger(), LoggerFrom(context.Background()))
+
+	var buf bytes.Buffer
+	l, err := NewLogger(LogConfig{Output: &buf})
+	require.NoError(t, err)
+	ctx := WithLogger(context.Background(), l.With(map[string]any{"actor": "user1", "path": "/bids"}))
+
+	InfoContext(ctx, "HTTP Request", map[string]any{"path": "/override"})
+	WarnContext(ctx, "slow", nil)
+
+	got := lines(t, &buf)
+	require.Len(t, got, 2)
+	require.Equal(t, "user1", got[0]["actor"])
+	require.Equal(t, "/override", got[0]["path"], "explicit fields win")
+	require.Equal(t, "warning", got[1]["level"])
+	require.Equal(t, "/bids", got[1]["path"])
+}
@@ -21,3 +21,13 @@ func JSONError(c *gin.Context, status int, err error, message string) {
 		"error":   err.Error(),
 	})
 }
+
+// JSONErrorCode sends a structured error response with a stable, machine-readable code
+func JSONErrorCode(c *gin.Context, status int, code string, err error, message string) {
+	c.JSON(status, gin.H{
+		"status":  status,
+		"code":    code,
+		"message": message,
+		"error":   err.Error(),
+	})
+}